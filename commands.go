@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gobase-app/config"
+	"gobase-app/migrations"
+	"gobase-app/repositories"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// runCommand menjalankan subcommand CLI (mis. `gobase-app migrate up`) sebagai pengganti
+// menjalankan server. Mengembalikan false jika name bukan subcommand yang dikenal.
+func runCommand(name string, args []string) (bool, error) {
+	switch name {
+	case "migrate":
+		return true, migrate(args)
+	case "backfill-user-stores":
+		return true, backfillUserStores(args)
+	case "stock-verify":
+		return true, stockVerify()
+	default:
+		return false, nil
+	}
+}
+
+// migrate menjalankan `migrate up`, `migrate down [jumlah]` (default 1), atau `migrate status`.
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("pemakaian: migrate up | down [jumlah] | status")
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := migrations.Up(config.DB, log.Printf)
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("schema sudah versi terbaru")
+		}
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("jumlah migrasi yang di-rollback tidak valid: %s", args[1])
+			}
+			steps = n
+		}
+		done, err := migrations.Down(config.DB, steps, log.Printf)
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("tidak ada migrasi yang bisa di-rollback")
+		}
+		return nil
+	case "status":
+		statuses, err := migrations.Statuses(config.DB)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			switch {
+			case s.Missing:
+				fmt.Printf("%04d  %-40s applied %s (file tidak ada)\n", s.Version, "?", s.AppliedAt.Format("2006-01-02 15:04:05"))
+			case s.Applied:
+				fmt.Printf("%04d  %-40s applied %s\n", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			default:
+				fmt.Printf("%04d  %-40s pending\n", s.Version, s.Name)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("subcommand migrate tidak dikenal: %s", args[0])
+	}
+}
+
+// backfillUserStores memindahkan assignment store dari kolom JSON users.store_id ke tabel
+// user_stores. Dengan -drop-column, kolom lama dihapus jika tidak ada entry yang terlewat.
+func backfillUserStores(args []string) error {
+	fs := flag.NewFlagSet("backfill-user-stores", flag.ContinueOnError)
+	dropColumn := fs.Bool("drop-column", false, "hapus kolom users.store_id setelah backfill berhasil")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	legacy, err := migrations.HasLegacyStoreColumn(config.DB)
+	if err != nil {
+		return err
+	}
+	if !legacy {
+		fmt.Println("kolom users.store_id sudah tidak ada, tidak ada yang perlu di-backfill")
+		return nil
+	}
+
+	result, err := migrations.BackfillUserStores(config.DB)
+	if err != nil {
+		return fmt.Errorf("backfill user_stores: %w", err)
+	}
+	fmt.Printf("%d user dibaca, %d baris user_stores ditambahkan\n", result.Users, result.Inserted)
+	if len(result.Skipped) > 0 {
+		fmt.Printf("%d entry dilewati:\n  %s\n", len(result.Skipped), strings.Join(result.Skipped, "\n  "))
+	}
+
+	if !*dropColumn {
+		return nil
+	}
+	if len(result.Skipped) > 0 {
+		return fmt.Errorf("kolom users.store_id tidak dihapus karena masih ada entry yang dilewati")
+	}
+	if err := migrations.DropLegacyStoreColumn(config.DB); err != nil {
+		return fmt.Errorf("hapus kolom users.store_id: %w", err)
+	}
+	fmt.Println("kolom users.store_id dihapus")
+	return nil
+}
+
+// stockVerify mencocokkan saldo di stock_balances dengan total mutasi di stock_movements. Selisih
+// hanya dilaporkan; koreksinya dicatat lewat penyesuaian stok agar ledger tetap utuh.
+func stockVerify() error {
+	mismatches, err := (&repositories.StockRepository{DB: config.DB}).FindBalanceMismatches()
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		fmt.Println("seluruh saldo stok sesuai dengan ledger")
+		return nil
+	}
+
+	for _, m := range mismatches {
+		fmt.Printf("store %d item %d: saldo %d, ledger %d\n", m.StoreID, m.ItemID, m.Cached, m.Ledger)
+	}
+	return fmt.Errorf("%d saldo stok tidak sesuai dengan ledger", len(mismatches))
+}
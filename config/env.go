@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvInt membaca env sebagai integer positif, atau mengembalikan fallback jika kosong/tidak valid.
+func EnvInt(key string, fallback int) int {
+	val, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || val <= 0 {
+		return fallback
+	}
+	return val
+}
+
+// EnvBool membaca env bernilai "true"/"1"/"yes" sebagai true.
+func EnvBool(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "true", "1", "yes":
+		return true
+	}
+	return false
+}
+
+// EnvBoolDefault seperti EnvBool, tetapi mengembalikan fallback jika env tidak diisi.
+func EnvBoolDefault(key string, fallback bool) bool {
+	if strings.TrimSpace(os.Getenv(key)) == "" {
+		return fallback
+	}
+	return EnvBool(key)
+}
+
+// EnvList membaca env berisi daftar yang dipisah koma, mengabaikan item kosong.
+func EnvList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
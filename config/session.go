@@ -0,0 +1,64 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gorilla/securecookie"
+)
+
+// SessionKeyPairs membaca key session dari env SESSION_KEYS.
+//
+// Format: "auth:enc,auth_lama:enc_lama" dengan setiap key di-encode base64. Pasangan pertama
+// dipakai untuk menandatangani/enkripsi cookie baru, pasangan berikutnya hanya untuk membaca
+// cookie lama sehingga key bisa dirotasi tanpa me-logout semua user. Key enkripsi boleh
+// dikosongkan ("auth:") tetapi jika diisi harus 16, 24, atau 32 byte.
+//
+// Jika SESSION_KEYS kosong di luar production, key acak sementara dibuat (session hilang saat restart).
+func SessionKeyPairs() ([][]byte, error) {
+	raw := strings.TrimSpace(os.Getenv("SESSION_KEYS"))
+	if raw == "" {
+		if strings.ToLower(os.Getenv("APP_ENV")) == "production" {
+			return nil, errors.New("SESSION_KEYS wajib diisi pada APP_ENV=production")
+		}
+		log.Println("SESSION_KEYS not set, using ephemeral random session keys")
+		return [][]byte{securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32)}, nil
+	}
+
+	var pairs [][]byte
+	for i, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		authPart, encPart, _ := strings.Cut(entry, ":")
+		authKey, err := base64.StdEncoding.DecodeString(authPart)
+		if err != nil || len(authKey) < 32 {
+			return nil, fmt.Errorf("SESSION_KEYS #%d: auth key harus base64 minimal 32 byte", i+1)
+		}
+
+		var encKey []byte
+		if encPart != "" {
+			encKey, err = base64.StdEncoding.DecodeString(encPart)
+			if err != nil {
+				return nil, fmt.Errorf("SESSION_KEYS #%d: encryption key bukan base64 valid", i+1)
+			}
+			if l := len(encKey); l != 16 && l != 24 && l != 32 {
+				return nil, fmt.Errorf("SESSION_KEYS #%d: encryption key harus 16, 24, atau 32 byte", i+1)
+			}
+		}
+
+		pairs = append(pairs, authKey, encKey)
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("SESSION_KEYS tidak berisi key yang valid")
+	}
+
+	return pairs, nil
+}
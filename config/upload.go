@@ -0,0 +1,17 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// UploadURLPrefix adalah prefix URL tempat file upload disajikan (lihat main.go).
+const UploadURLPrefix = "/uploads"
+
+// UploadDir mengembalikan folder penyimpanan file upload (UPLOAD_DIR, default ./uploads).
+func UploadDir() string {
+	if dir := strings.TrimSpace(os.Getenv("UPLOAD_DIR")); dir != "" {
+		return dir
+	}
+	return "./uploads"
+}
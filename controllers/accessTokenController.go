@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"sort"
+	"strconv"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileTokenIndex menampilkan daftar token API milik user dan form pembuatan token.
+func ProfileTokenIndex(c *gin.Context) {
+	renderProfileTokens(c, "", "", "")
+}
+
+// ProfileTokenStore membuat token API baru. Token asli hanya ditampilkan sekali di respons ini.
+func ProfileTokenStore(c *gin.Context) {
+	expiresInDays, err := strconv.Atoi(c.PostForm("expires_in_days"))
+	if err != nil {
+		renderProfileTokens(c, "Masa berlaku token tidak valid", "", "")
+		return
+	}
+
+	plain, err := newAccessTokenService().CreateToken(
+		sessionUserID(sessions.Default(c)),
+		c.PostForm("name"),
+		c.PostFormArray("abilities"),
+		expiresInDays,
+	)
+	if err != nil {
+		renderProfileTokens(c, err.Error(), "", "")
+		return
+	}
+
+	renderProfileTokens(c, "", "Token berhasil dibuat. Salin sekarang, token tidak akan ditampilkan lagi.", plain)
+}
+
+// ProfileTokenRevoke mencabut token API milik user.
+func ProfileTokenRevoke(c *gin.Context) {
+	tokenID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		renderProfileTokens(c, "Token tidak valid", "", "")
+		return
+	}
+
+	if err := newAccessTokenService().RevokeToken(sessionUserID(sessions.Default(c)), tokenID); err != nil {
+		renderProfileTokens(c, err.Error(), "", "")
+		return
+	}
+
+	renderProfileTokens(c, "", "Token berhasil dicabut.", "")
+}
+
+func renderProfileTokens(c *gin.Context, message, success, newToken string) {
+	userID := sessionUserID(sessions.Default(c))
+
+	tokens, err := newAccessTokenService().ListTokens(userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	perms, err := services.GetUserPermissions(userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	abilities := make([]string, 0, len(perms))
+	for name := range perms {
+		abilities = append(abilities, name)
+	}
+	sort.Strings(abilities)
+
+	Render(c, "tokens.html", gin.H{
+		"Title":         "Token API",
+		"Page":          "profileTokens",
+		"Tokens":        tokens,
+		"Abilities":     abilities,
+		"ExpiryOptions": services.AccessTokenExpiryOptions,
+		"NewToken":      newToken,
+		"Error":         message,
+		"Success":       success,
+	})
+}
+
+func newAccessTokenService() *services.PersonalAccessTokenService {
+	return &services.PersonalAccessTokenService{Repo: &repositories.PersonalAccessTokenRepository{DB: config.DB}}
+}
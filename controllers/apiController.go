@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"gobase-app/middleware"
+	"gobase-app/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiDefaultPerPage = 20
+	apiMaxPerPage     = 100
+)
+
+// apiMeta adalah informasi paginasi pada envelope list API.
+type apiMeta struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// apiData mengirim envelope sukses {"data": ...}.
+func apiData(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, gin.H{"data": data})
+}
+
+// apiList mengirim envelope list {"data": [...], "meta": {...}}.
+func apiList(c *gin.Context, data interface{}, page, perPage, total int) {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = (total + perPage - 1) / perPage
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+		"meta": apiMeta{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages},
+	})
+}
+
+// apiPagination membaca query page & per_page (default 20, maksimal 100).
+func apiPagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = apiDefaultPerPage
+	}
+	if perPage > apiMaxPerPage {
+		perPage = apiMaxPerPage
+	}
+	return page, perPage
+}
+
+// apiPageBounds menghitung batas slice untuk paginasi data yang sudah dimuat di memori.
+func apiPageBounds(total, page, perPage int) (int, int) {
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// apiIDParam membaca :id dari URL; request dihentikan dengan 400 jika tidak valid.
+func apiIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		middleware.AbortAPIError(c, http.StatusBadRequest, "invalid_id", "id tidak valid")
+		return 0, false
+	}
+	return id, true
+}
+
+// apiBindJSON membaca body JSON ke dst; body yang tidak valid dibalas 400.
+func apiBindJSON(c *gin.Context, dst interface{}) bool {
+	if err := c.ShouldBindJSON(dst); err != nil {
+		middleware.AbortAPIError(c, http.StatusBadRequest, "invalid_body", "Body JSON tidak valid: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// apiServiceError memetakan error service ke status HTTP. Error sistem tidak dikirim
+// ke klien apa adanya, hanya dicatat di log.
+func apiServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		middleware.AbortAPIError(c, http.StatusUnprocessableEntity, "validation_failed", err.Error())
+	case errors.Is(err, services.ErrNotFound):
+		middleware.AbortAPIError(c, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, services.ErrConflict):
+		middleware.AbortAPIError(c, http.StatusConflict, "conflict", err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		middleware.AbortAPIError(c, http.StatusForbidden, "forbidden", err.Error())
+	default:
+		log.Printf("api %s %s failed: %v", c.Request.Method, c.Request.URL.Path, err)
+		middleware.AbortAPIError(c, http.StatusInternalServerError, "internal_error", "Terjadi kesalahan pada server")
+	}
+}
+
+// APINotFound membalas route API yang tidak dikenal dengan envelope error JSON.
+func APINotFound(c *gin.Context) {
+	middleware.AbortAPIError(c, http.StatusNotFound, "route_not_found", "Endpoint tidak ditemukan")
+}
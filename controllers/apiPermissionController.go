@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiPermission adalah representasi JSON permission pada REST API.
+type apiPermission struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Group       string `json:"group"`
+	GuardName   string `json:"guard_name"`
+	Description string `json:"description"`
+}
+
+// apiPermissionPayload adalah body JSON untuk update (PUT) permission. Nama permission
+// dideklarasikan di kode sehingga hanya group dan deskripsi yang bisa diubah.
+type apiPermissionPayload struct {
+	Group       string `json:"group"`
+	Description string `json:"description"`
+}
+
+// apiPermissionGroupRenamePayload adalah body JSON untuk memindahkan seluruh permission satu group.
+type apiPermissionGroupRenamePayload struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func newAPIPermission(p models.Permission) apiPermission {
+	return apiPermission{
+		ID:          p.ID,
+		Name:        p.Name,
+		Group:       p.GroupName,
+		GuardName:   p.GuardName,
+		Description: p.Description,
+	}
+}
+
+// apiPermissionIDParam membaca :id permission dari URL; request dihentikan dengan 400 jika tidak valid.
+func apiPermissionIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		middleware.AbortAPIError(c, http.StatusBadRequest, "invalid_id", "id tidak valid")
+		return 0, false
+	}
+	return id, true
+}
+
+// APIPermissionIndex GET /api/v1/permissions
+func APIPermissionIndex(c *gin.Context) {
+	page, perPage := apiPagination(c)
+
+	permissionService := &services.PermissionService{Repo: &repositories.PermissionRepository{DB: config.DB}}
+	groups, err := permissionService.GetGroupedPermissions()
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	var all []apiPermission
+	for _, group := range groups {
+		for _, perm := range group.Permissions {
+			all = append(all, newAPIPermission(perm))
+		}
+	}
+
+	start, end := apiPageBounds(len(all), page, perPage)
+	data := make([]apiPermission, 0, end-start)
+	data = append(data, all[start:end]...)
+	apiList(c, data, page, perPage, len(all))
+}
+
+// APIPermissionUpdate PUT /api/v1/permissions/:id
+func APIPermissionUpdate(c *gin.Context) {
+	id, ok := apiPermissionIDParam(c)
+	if !ok {
+		return
+	}
+
+	var payload apiPermissionPayload
+	if !apiBindJSON(c, &payload) {
+		return
+	}
+
+	permissionService := newPermissionService()
+	if err := permissionService.UpdatePermission(id, payload.Group, payload.Description, auditActor(c)); err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	perm, err := permissionService.GetPermission(id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, newAPIPermission(*perm))
+}
+
+// APIPermissionGroupRename POST /api/v1/permissions/groups/rename
+func APIPermissionGroupRename(c *gin.Context) {
+	var payload apiPermissionGroupRenamePayload
+	if !apiBindJSON(c, &payload) {
+		return
+	}
+
+	count, err := newPermissionService().RenameGroup(payload.From, payload.To, auditActor(c))
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, gin.H{"group": payload.To, "moved": count})
+}
+
+// APIPermissionDestroy DELETE /api/v1/permissions/:id (hanya permission orphan)
+func APIPermissionDestroy(c *gin.Context) {
+	id, ok := apiPermissionIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := newPermissionService().DeletePermission(id, auditActor(c)); err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
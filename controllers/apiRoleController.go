@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiRoleSummary adalah item daftar role pada REST API.
+type apiRoleSummary struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	IsAdmin         bool   `json:"is_admin"`
+	PermissionCount int    `json:"permission_count"`
+	UserCount       int    `json:"user_count"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+// apiRole adalah detail role beserta permission dan parent role yang dimiliki.
+type apiRole struct {
+	ID                int     `json:"id"`
+	Name              string  `json:"name"`
+	GuardName         string  `json:"guard_name"`
+	IsAdmin           bool    `json:"is_admin"`
+	RequiresTwoFactor bool    `json:"requires_two_factor"`
+	PermissionIDs     []int64 `json:"permission_ids"`
+	ParentIDs         []int   `json:"parent_ids"`
+}
+
+// apiRolePayload adalah body JSON untuk create (POST) dan update (PUT) role.
+type apiRolePayload struct {
+	Name              string  `json:"name"`
+	GuardName         string  `json:"guard_name"`
+	IsAdmin           bool    `json:"is_admin"`
+	RequiresTwoFactor bool    `json:"requires_two_factor"`
+	PermissionIDs     []int64 `json:"permission_ids"`
+	ParentIDs         []int   `json:"parent_ids"`
+}
+
+func newAPIRole(r models.RoleDetail) apiRole {
+	permIDs := r.PermissionIDs
+	if permIDs == nil {
+		permIDs = []int64{}
+	}
+	parentIDs := r.ParentIDs
+	if parentIDs == nil {
+		parentIDs = []int{}
+	}
+
+	return apiRole{
+		ID:                r.ID,
+		Name:              r.Name,
+		GuardName:         r.GuardName,
+		IsAdmin:           r.IsAdmin,
+		RequiresTwoFactor: r.RequiresTwoFactor,
+		PermissionIDs:     permIDs,
+		ParentIDs:         parentIDs,
+	}
+}
+
+func newAPIRoleService() *services.RoleService {
+	return &services.RoleService{Repo: &repositories.RoleRepository{DB: config.DB}}
+}
+
+// APIRoleIndex GET /api/v1/roles
+func APIRoleIndex(c *gin.Context) {
+	page, perPage := apiPagination(c)
+
+	roles, err := newAPIRoleService().GetRoles()
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	start, end := apiPageBounds(len(roles), page, perPage)
+	data := make([]apiRoleSummary, 0, end-start)
+	for _, r := range roles[start:end] {
+		data = append(data, apiRoleSummary{
+			ID:              r.ID,
+			Name:            r.Name,
+			IsAdmin:         r.IsAdmin,
+			PermissionCount: r.PermissionCount,
+			UserCount:       r.UserCount,
+			UpdatedAt:       r.UpdatedAt,
+		})
+	}
+	apiList(c, data, page, perPage, len(roles))
+}
+
+// APIRoleShow GET /api/v1/roles/:id
+func APIRoleShow(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok {
+		return
+	}
+
+	role, err := newAPIRoleService().GetRoleDetail(id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, newAPIRole(*role))
+}
+
+// APIRoleStore POST /api/v1/roles
+func APIRoleStore(c *gin.Context) {
+	var payload apiRolePayload
+	if !apiBindJSON(c, &payload) {
+		return
+	}
+
+	roleSvc := newAPIRoleService()
+	id, err := roleSvc.CreateRole(models.RoleCreateInput{
+		Name:              payload.Name,
+		GuardName:         payload.GuardName,
+		IsAdmin:           payload.IsAdmin,
+		RequiresTwoFactor: payload.RequiresTwoFactor,
+		PermissionIDs:     payload.PermissionIDs,
+		ParentIDs:         payload.ParentIDs,
+	}, auditActor(c), middleware.CurrentPrincipal(c))
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	role, err := roleSvc.GetRoleDetail(id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusCreated, newAPIRole(*role))
+}
+
+// APIRoleUpdate PUT /api/v1/roles/:id
+func APIRoleUpdate(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok {
+		return
+	}
+
+	var payload apiRolePayload
+	if !apiBindJSON(c, &payload) {
+		return
+	}
+
+	roleSvc := newAPIRoleService()
+	err := roleSvc.UpdateRole(models.RoleUpdateInput{
+		ID:                id,
+		Name:              payload.Name,
+		GuardName:         payload.GuardName,
+		IsAdmin:           payload.IsAdmin,
+		RequiresTwoFactor: payload.RequiresTwoFactor,
+		PermissionIDs:     payload.PermissionIDs,
+		ParentIDs:         payload.ParentIDs,
+	}, auditActor(c), middleware.CurrentPrincipal(c))
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	role, err := roleSvc.GetRoleDetail(id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, newAPIRole(*role))
+}
+
+// APIRoleDestroy DELETE /api/v1/roles/:id
+func APIRoleDestroy(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := newAPIRoleService().DeleteRole(id, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"gobase-app/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiStore adalah representasi JSON store pada REST API.
+type apiStore struct {
+	ID       int    `json:"id"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	IsActive bool   `json:"is_active"`
+}
+
+// apiStorePayload adalah body JSON untuk create (POST) dan update (PUT) store.
+type apiStorePayload struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+func newAPIStore(s models.Store) apiStore {
+	return apiStore{
+		ID:       s.StoreID,
+		Code:     s.StoreCode,
+		Name:     s.StoreName,
+		Address:  s.StoreAddress,
+		IsActive: s.IsActive,
+	}
+}
+
+// APIStoreIndex GET /api/v1/stores (hanya store milik user yang sedang login).
+// Mendukung query q (kode, nama, atau alamat) dan status (active / inactive).
+func APIStoreIndex(c *gin.Context) {
+	page, perPage := apiPagination(c)
+
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	search := models.StoreSearch{Query: c.Query("q"), Status: c.Query("status")}
+	stores, err := newStoreService().SearchStores(filter, search)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	start, end := apiPageBounds(len(stores), page, perPage)
+	data := make([]apiStore, 0, end-start)
+	for _, s := range stores[start:end] {
+		data = append(data, newAPIStore(s))
+	}
+	apiList(c, data, page, perPage, len(stores))
+}
+
+// APIStoreShow GET /api/v1/stores/:id
+func APIStoreShow(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok {
+		return
+	}
+
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	store, err := newStoreService().GetStore(filter, id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, newAPIStore(*store))
+}
+
+// APIStoreStore POST /api/v1/stores
+func APIStoreStore(c *gin.Context) {
+	var payload apiStorePayload
+	if !apiBindJSON(c, &payload) {
+		return
+	}
+
+	storeSvc := newStoreService()
+	id, err := storeSvc.CreateStore(models.StoreInput{
+		Code:    payload.Code,
+		Name:    payload.Name,
+		Address: payload.Address,
+	}, auditActor(c))
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	// Store baru belum ditugaskan ke siapa pun sehingga dibaca tanpa filter store pembuatnya.
+	store, err := storeSvc.GetStore(models.StoreFilter{All: true}, int(id))
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusCreated, newAPIStore(*store))
+}
+
+// APIStoreUpdate PUT /api/v1/stores/:id
+func APIStoreUpdate(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok {
+		return
+	}
+
+	var payload apiStorePayload
+	if !apiBindJSON(c, &payload) {
+		return
+	}
+
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	storeSvc := newStoreService()
+	err = storeSvc.UpdateStore(filter, models.StoreInput{
+		ID:      id,
+		Code:    payload.Code,
+		Name:    payload.Name,
+		Address: payload.Address,
+	}, auditActor(c))
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	store, err := storeSvc.GetStore(filter, id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, newAPIStore(*store))
+}
+
+// APIStoreDestroy DELETE /api/v1/stores/:id. Store tidak dihapus, hanya dinonaktifkan, karena masih
+// dirujuk user dan data lain; gunakan POST /api/v1/stores/:id/activate untuk mengaktifkannya kembali.
+func APIStoreDestroy(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok {
+		return
+	}
+
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	if err := newStoreService().SetStoreActive(filter, id, false, auditActor(c)); err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// APIStoreActivate POST /api/v1/stores/:id/activate
+func APIStoreActivate(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok {
+		return
+	}
+
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	storeSvc := newStoreService()
+	if err := storeSvc.SetStoreActive(filter, id, true, auditActor(c)); err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	store, err := storeSvc.GetStore(filter, id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, newAPIStore(*store))
+}
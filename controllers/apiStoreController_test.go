@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"encoding/json"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/services"
+	"gobase-app/sessionstore"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// newAPIStoreRouter menyiapkan route store API untuk user yang hanya memiliki store storeIDs.
+func newAPIStoreRouter(t *testing.T, storeIDs ...int) (*gin.Engine, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevDB := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = prevDB
+		db.Close()
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(sessions.Sessions("mysession", sessionstore.NewStore(sessionstore.NewMemoryBackend(), []byte("0123456789abcdef0123456789abcdef"))))
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.StoreScopeKey, &services.StoreScope{StoreIDs: storeIDs})
+	})
+	r.GET("/api/v1/stores/:id", APIStoreShow)
+	r.POST("/api/v1/stores", APIStoreStore)
+	r.DELETE("/api/v1/stores/:id", APIStoreDestroy)
+	return r, mock
+}
+
+func expectStoreRow(mock sqlmock.Sqlmock, id int, active bool) {
+	mock.ExpectQuery(`FROM stores\s+WHERE store_id IN \(\?\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id", "store_code", "store_name", "store_address", "is_active"}).
+			AddRow(id, "MK1", "Mitra Kampus 1", "Jl. Kampus 1", active))
+}
+
+func TestAPIStore(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		setup      func(sqlmock.Sqlmock)
+		wantStatus int
+		wantData   map[string]interface{}
+	}{
+		{
+			name:       "detail berisi kode, alamat, dan status",
+			method:     http.MethodGet,
+			path:       "/api/v1/stores/3",
+			setup:      func(mock sqlmock.Sqlmock) { expectStoreRow(mock, 3, true) },
+			wantStatus: http.StatusOK,
+			wantData: map[string]interface{}{
+				"id": float64(3), "code": "MK1", "name": "Mitra Kampus 1", "address": "Jl. Kampus 1", "is_active": true,
+			},
+		},
+		{
+			name:       "detail store di luar store user",
+			method:     http.MethodGet,
+			path:       "/api/v1/stores/4",
+			setup:      func(sqlmock.Sqlmock) {},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "buat store tanpa alamat",
+			method:     http.MethodPost,
+			path:       "/api/v1/stores",
+			body:       `{"code": "mk9", "name": "Mitra Kampus 9"}`,
+			setup:      func(sqlmock.Sqlmock) {},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "nonaktifkan store",
+			method: http.MethodDelete,
+			path:   "/api/v1/stores/3",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectStoreRow(mock, 3, true)
+				snapshot := func(active bool) {
+					mock.ExpectQuery(`SELECT store_code, store_name, store_address, is_active\s+FROM stores`).WithArgs(int64(3)).
+						WillReturnRows(sqlmock.NewRows([]string{"store_code", "store_name", "store_address", "is_active"}).
+							AddRow("MK1", "Mitra Kampus 1", "Jl. Kampus 1", active))
+				}
+				mock.ExpectBegin()
+				snapshot(true)
+				mock.ExpectExec(`UPDATE stores SET is_active = \? WHERE store_id = \?`).WithArgs(false, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				snapshot(false)
+				mock.ExpectExec(`INSERT INTO audit_logs`).WithArgs(nil, "", "store.deactivate", "store", int64(3),
+					sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "nonaktifkan store di luar store user",
+			method:     http.MethodDelete,
+			path:       "/api/v1/stores/4",
+			setup:      func(sqlmock.Sqlmock) {},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mock := newAPIStoreRouter(t, 3)
+			tt.setup(mock)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+			if tt.wantData == nil {
+				return
+			}
+
+			var resp struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.wantData {
+				if resp.Data[key] != want {
+					t.Errorf("data.%s = %v, want %v", key, resp.Data[key], want)
+				}
+			}
+		})
+	}
+}
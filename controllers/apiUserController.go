@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiUser adalah representasi JSON user pada REST API.
+type apiUser struct {
+	ID                 int      `json:"id"`
+	NIP                int      `json:"nip"`
+	Username           string   `json:"username"`
+	Name               string   `json:"name"`
+	Email              string   `json:"email"`
+	Status             string   `json:"status"`
+	StoreIDs           []int    `json:"store_ids"`
+	Roles              []string `json:"roles"`
+	MustChangePassword bool     `json:"must_change_password"`
+	EmailVerified      bool     `json:"email_verified"`
+	CreatedAt          string   `json:"created_at"`
+}
+
+// apiUserPayload adalah body JSON untuk create (POST) dan update (PUT) user.
+// Pada update, password boleh kosong jika tidak ingin diganti.
+type apiUserPayload struct {
+	NIP                int      `json:"nip"`
+	Username           string   `json:"username"`
+	Password           string   `json:"password"`
+	Name               string   `json:"name"`
+	Email              string   `json:"email"`
+	Status             string   `json:"status"`
+	StoreIDs           []int    `json:"store_ids"`
+	Roles              []string `json:"roles"`
+	MustChangePassword bool     `json:"must_change_password"`
+}
+
+func newAPIUser(u models.User) apiUser {
+	storeIDs := u.StoreIDs
+	if storeIDs == nil {
+		storeIDs = []int{}
+	}
+	roles := u.RoleNames
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return apiUser{
+		ID:                 u.ID,
+		NIP:                u.NIP,
+		Username:           u.Username,
+		Name:               u.Name,
+		Email:              u.Email,
+		Status:             u.Status,
+		StoreIDs:           storeIDs,
+		Roles:              roles,
+		MustChangePassword: u.MustChangePassword,
+		EmailVerified:      u.EmailVerified,
+		CreatedAt:          u.CreatedAt,
+	}
+}
+
+func newAPIUserService() *services.UserService {
+	return &services.UserService{Repo: &repositories.UserRepository{DB: config.DB}}
+}
+
+// APIUserIndex GET /api/v1/users
+func APIUserIndex(c *gin.Context) {
+	page, perPage := apiPagination(c)
+
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	users, total, err := newAPIUserService().GetUsersPage(filter, page, perPage)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	data := make([]apiUser, 0, len(users))
+	for _, u := range users {
+		data = append(data, newAPIUser(u))
+	}
+	apiList(c, data, page, perPage, total)
+}
+
+// APIUserShow GET /api/v1/users/:id
+func APIUserShow(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok {
+		return
+	}
+
+	if !apiUserInScope(c, id) {
+		return
+	}
+
+	user, err := newAPIUserService().GetUser(id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, newAPIUser(*user))
+}
+
+// APIUserStore POST /api/v1/users
+func APIUserStore(c *gin.Context) {
+	var payload apiUserPayload
+	if !apiBindJSON(c, &payload) {
+		return
+	}
+
+	userSvc := newAPIUserService()
+	id, err := userSvc.CreateUser(models.UserCreateInput{
+		NIP:       payload.NIP,
+		Username:  strings.TrimSpace(payload.Username),
+		Password:  payload.Password,
+		Name:      strings.TrimSpace(payload.Name),
+		Email:     strings.TrimSpace(payload.Email),
+		Status:    payload.Status,
+		StoreIDs:  payload.StoreIDs,
+		RoleNames: payload.Roles,
+
+		MustChangePassword: payload.MustChangePassword,
+	}, auditActor(c), middleware.CurrentPrincipal(c))
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	user, err := userSvc.GetUser(id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusCreated, newAPIUser(*user))
+}
+
+// APIUserUpdate PUT /api/v1/users/:id
+func APIUserUpdate(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok || !apiUserInScope(c, id) {
+		return
+	}
+
+	var payload apiUserPayload
+	if !apiBindJSON(c, &payload) {
+		return
+	}
+
+	userSvc := newAPIUserService()
+	err := userSvc.UpdateUser(models.UserUpdateInput{
+		ID:        id,
+		NIP:       payload.NIP,
+		Username:  strings.TrimSpace(payload.Username),
+		Password:  payload.Password,
+		Name:      strings.TrimSpace(payload.Name),
+		Email:     strings.TrimSpace(payload.Email),
+		Status:    payload.Status,
+		StoreIDs:  payload.StoreIDs,
+		RoleNames: payload.Roles,
+
+		MustChangePassword: payload.MustChangePassword,
+	}, auditActor(c), middleware.CurrentPrincipal(c))
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+
+	user, err := userSvc.GetUser(id)
+	if err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, newAPIUser(*user))
+}
+
+// APIUserDestroy DELETE /api/v1/users/:id
+func APIUserDestroy(c *gin.Context) {
+	id, ok := apiIDParam(c)
+	if !ok || !apiUserInScope(c, id) {
+		return
+	}
+
+	if err := newAPIUserService().DeleteUser(id, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		apiServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// apiUserInScope membalas 404 untuk user yang tidak ada maupun user di luar store milik user yang
+// sedang login, di semua method, agar API tidak membocorkan user mana yang ada.
+func apiUserInScope(c *gin.Context, id int) bool {
+	inScope, err := userInStoreScope(c, id)
+	if err != nil {
+		apiServiceError(c, err)
+		return false
+	}
+	if !inScope {
+		middleware.AbortAPIError(c, http.StatusNotFound, "not_found", "user tidak ditemukan")
+		return false
+	}
+	return true
+}
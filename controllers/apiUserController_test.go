@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIUserOutOfScopeIsNotFound(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		exists bool
+	}{
+		{name: "detail", method: http.MethodGet, exists: true},
+		{name: "ubah", method: http.MethodPut, body: `{"username": "budi"}`, exists: true},
+		{name: "hapus", method: http.MethodDelete, exists: true},
+		{name: "ubah user yang tidak ada", method: http.MethodPut, body: `{"username": "budi"}`},
+		{name: "hapus user yang tidak ada", method: http.MethodDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			prevDB := config.DB
+			config.DB = db
+			t.Cleanup(func() {
+				config.DB = prevDB
+				db.Close()
+			})
+
+			// user 9 hanya ditugaskan di store 4, sedangkan user yang login hanya memegang store 3
+			exists := sqlmock.NewRows([]string{"exists"})
+			if tt.exists {
+				exists.AddRow(true)
+			}
+			mock.ExpectQuery(`SELECT TRUE FROM users WHERE id = \?`).WithArgs(9).WillReturnRows(exists)
+			if tt.exists {
+				mock.ExpectQuery(`SELECT store_id FROM user_stores WHERE user_id = \?`).
+					WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(4))
+			}
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set(middleware.StoreScopeKey, &services.StoreScope{StoreIDs: []int{3}})
+			})
+			r.GET("/api/v1/users/:id", APIUserShow)
+			r.PUT("/api/v1/users/:id", APIUserUpdate)
+			r.DELETE("/api/v1/users/:id", APIUserDestroy)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/users/9", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, http.StatusNotFound, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), `"not_found"`) {
+				t.Errorf("body = %s, want kode not_found", w.Body)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/url"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// auditActor menyusun identitas pelaku perubahan dari session dan request untuk audit log.
+func auditActor(c *gin.Context) models.AuditActor {
+	actor := models.AuditActor{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if principal, ok := middleware.CurrentTokenPrincipal(c); ok {
+		actor.UserID = principal.UserID
+		actor.Username = principal.Username
+		return actor
+	}
+	if u, ok := sessions.Default(c).Get("user").(models.SessionUser); ok {
+		actor.UserID = u.UserID
+		actor.Username = u.Username
+	}
+	return actor
+}
+
+// AuditIndex menampilkan audit log dengan filter dan paginasi.
+func AuditIndex(c *gin.Context) {
+	auditSvc := &services.AuditService{Repo: &repositories.AuditRepository{DB: config.DB}}
+	filter := auditFilterFromQuery(c)
+
+	actions, err := auditSvc.GetActions()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data := gin.H{
+		"Title":       "Audit Log",
+		"Page":        "audit",
+		"Filter":      filter,
+		"Actions":     actions,
+		"ExportURL":   auditURL("/audit/export", filter, 0),
+		"TotalPages":  0,
+	}
+
+	logs, total, err := auditSvc.Search(filter)
+	if err != nil {
+		data["Error"] = err.Error()
+		Render(c, "audit.html", data)
+		return
+	}
+
+	page := filter.Page
+	if page < 1 {
+		page = 1
+	}
+	totalPages := (total + services.AuditPerPage - 1) / services.AuditPerPage
+
+	data["logs"] = logs
+	data["Total"] = total
+	data["CurrentPage"] = page
+	data["TotalPages"] = totalPages
+	if page > 1 {
+		data["PrevURL"] = auditURL("/audit", filter, page-1)
+	}
+	if page < totalPages {
+		data["NextURL"] = auditURL("/audit", filter, page+1)
+	}
+
+	Render(c, "audit.html", data)
+}
+
+// AuditExport mengunduh audit log sesuai filter dalam format CSV.
+func AuditExport(c *gin.Context) {
+	auditSvc := &services.AuditService{Repo: &repositories.AuditRepository{DB: config.DB}}
+
+	var buf bytes.Buffer
+	if err := auditSvc.ExportCSV(auditFilterFromQuery(c), &buf); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filename := "audit-log-" + time.Now().Format("20060102-150405") + ".csv"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func auditFilterFromQuery(c *gin.Context) models.AuditFilter {
+	entityID, _ := strconv.ParseInt(c.Query("entity_id"), 10, 64)
+	page, _ := strconv.Atoi(c.Query("page"))
+
+	return models.AuditFilter{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   entityID,
+		DateFrom:   c.Query("date_from"),
+		DateTo:     c.Query("date_to"),
+		Page:       page,
+	}
+}
+
+// auditURL menyusun link dengan query filter untuk paginasi dan export (page 0 = tanpa page).
+func auditURL(path string, filter models.AuditFilter, page int) template.URL {
+	q := url.Values{}
+	for key, val := range map[string]string{
+		"actor":       filter.Actor,
+		"action":      filter.Action,
+		"entity_type": filter.EntityType,
+		"date_from":   filter.DateFrom,
+		"date_to":     filter.DateTo,
+	} {
+		if val != "" {
+			q.Set(key, val)
+		}
+	}
+	if filter.EntityID > 0 {
+		q.Set("entity_id", strconv.FormatInt(filter.EntityID, 10))
+	}
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if len(q) == 0 {
+		return template.URL(path)
+	}
+	return template.URL(path + "?" + q.Encode())
+}
@@ -2,14 +2,20 @@ package controllers
 
 import (
 	"database/sql"
+	"errors"
+	"log"
 	"net/http"
 	"gobase-app/config"
 	helpers "gobase-app/helper"
+	"gobase-app/middleware"
 	"gobase-app/models"
+	"gobase-app/oidc"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 )
 
 const userModelType = "Models\\User"
@@ -21,23 +27,136 @@ func LoginPage(c *gin.Context) {
 		c.Redirect(302, "/dashboard")
 		return
 	}
-	c.HTML(http.StatusOK, "login.html", gin.H{
-		"Title": "Login User",
-	})
+	renderLogin(c, http.StatusOK, nil)
 }
 
+// renderLogin menampilkan halaman login beserta tombol registrasi dan SSO jika diaktifkan.
+// extra berisi field tambahan seperti Error atau Success.
+func renderLogin(c *gin.Context, status int, extra gin.H) {
+	data := gin.H{
+		"Title":               "Login User",
+		"CSRFToken":           csrfToken(c),
+		"RegistrationEnabled": services.RegistrationEnabled(),
+	}
+	if provider := oidc.Default(); provider != nil {
+		data["SSOEnabled"] = true
+		data["SSOProviderName"] = provider.Name()
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+
+	c.HTML(status, "login.html", data)
+}
+
+// loginFailedMessage sengaja sama untuk username tidak ada, user non aktif, maupun password salah.
+const loginFailedMessage = "Username atau password salah"
+
 func LoginPost(c *gin.Context) {
 	username := c.PostForm("username")
 	password := c.PostForm("password")
+	clientIP := c.ClientIP()
+
+	throttleSvc := &services.LoginThrottleService{Repo: &repositories.LoginThrottleRepository{DB: config.DB}}
+
+	renderLoginError := func(status int, message string) {
+		renderLogin(c, status, gin.H{"Error": message})
+	}
+
+	wait, err := throttleSvc.Check(username, clientIP)
+	if err != nil {
+		renderLoginError(500, "Terjadi kesalahan saat memeriksa percobaan login")
+		return
+	}
+	if wait > 0 {
+		recordAuthEvent(c, models.AuthEventLoginFailed, 0, username)
+		renderLoginError(http.StatusTooManyRequests, "Terlalu banyak percobaan login. Coba lagi dalam "+services.FormatWait(wait)+".")
+		return
+	}
+
+	// password diverifikasi oleh authenticator di AUTH_DRIVERS (local dan/atau ldap)
+	userID, err := services.Authenticate(services.LoginAuthenticators(), username, password, auditActor(c))
+	if err != nil {
+		recordAuthEvent(c, models.AuthEventLoginFailed, 0, username)
+		// server LDAP yang mati bukan kesalahan user, jadi tidak dihitung sebagai percobaan gagal
+		if errors.Is(err, services.ErrAuthUnavailable) {
+			renderLoginError(http.StatusServiceUnavailable, "Layanan login sedang tidak bisa dihubungi. Coba lagi beberapa saat lagi.")
+			return
+		}
+		// hanya kredensial salah yang dihitung; error database atau authenticator tidak boleh
+		// mengunci user selama gangguan berlangsung
+		if !errors.Is(err, services.ErrInvalidCredentials) {
+			renderLoginError(500, "Terjadi kesalahan saat memverifikasi login")
+			return
+		}
+		if err := throttleSvc.RegisterFailure(username, clientIP); err != nil {
+			renderLoginError(500, "Terjadi kesalahan saat mencatat percobaan login")
+			return
+		}
+		renderLoginError(200, loginFailedMessage)
+		return
+	}
+
+	user, _, err := findLoginUser("u.id = ?", userID)
+	if err != nil {
+		renderLoginError(500, "Terjadi kesalahan saat mengambil data user")
+		return
+	}
+
+	if err := throttleSvc.RegisterSuccess(username); err != nil {
+		renderLoginError(500, "Terjadi kesalahan saat mencatat percobaan login")
+		return
+	}
+
+	completeLogin(c, user, renderLoginError)
+}
+
+// completeLogin melanjutkan login setelah identitas user terverifikasi (password atau SSO):
+// user dengan 2FA aktif diarahkan ke langkah kode verifikasi, selain itu session langsung dibuat.
+func completeLogin(c *gin.Context, user models.SessionUser, renderLoginError func(status int, message string)) {
+	twoFactorSvc := &services.TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: config.DB}}
+	state, err := twoFactorSvc.GetState(user.UserID)
+	if err != nil {
+		renderLoginError(500, "Terjadi kesalahan saat memeriksa 2FA")
+		return
+	}
+
+	session := sessions.Default(c)
+
+	// user dengan 2FA aktif harus melewati langkah verifikasi kode sebelum SessionUser disimpan
+	if state.Enabled {
+		session.Set(pendingTwoFactorUserKey, user.UserID)
+		session.Set(pendingTwoFactorAtKey, time.Now().Unix())
+		if err := session.Save(); err != nil {
+			renderLoginError(500, "Gagal menyimpan sesi: "+err.Error())
+			return
+		}
+		c.Redirect(http.StatusFound, "/login/2fa")
+		return
+	}
+
+	required, err := twoFactorSvc.IsRequired(user.UserID)
+	if err != nil {
+		renderLoginError(500, "Terjadi kesalahan saat memeriksa 2FA")
+		return
+	}
 
-	// hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	// if err != nil {
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-	// 	return
-	// }
+	if err := startUserSession(session, user, required); err != nil {
+		renderLoginError(500, "Gagal menyimpan sesi: "+err.Error())
+		return
+	}
+	recordAuthEvent(c, models.AuthEventLoginSuccess, user.UserID, user.Username)
 
-	// fmt.Println("DEBUG:", string(hashedPassword))
+	if required {
+		c.Redirect(http.StatusFound, "/profile/2fa")
+		return
+	}
 
+	c.Redirect(302, "/dashboard")
+}
+
+// findLoginUser mengambil user aktif beserta hash password berdasarkan kondisi where.
+func findLoginUser(where string, arg interface{}) (models.SessionUser, string, error) {
 	var (
 		userID  int
 		dbUser  string
@@ -55,99 +174,86 @@ func LoginPost(c *gin.Context) {
 			u.password,
 			COALESCE(u.nip, '') AS nip,
 			COALESCE(r.name, '') AS role,
-			COALESCE(u.store_id, '') AS store_id
+			COALESCE((
+				SELECT CONCAT('[', GROUP_CONCAT(us.store_id ORDER BY us.store_id), ']')
+				FROM user_stores us
+				WHERE us.user_id = u.id
+			), '') AS store_id
 		FROM users u
 		LEFT JOIN model_has_roles mhr ON mhr.model_id = u.id 
 		LEFT JOIN roles r ON r.id = mhr.role_id
-		WHERE u.username = ? and u.status = 'active'
-	`, username).
+		WHERE `+where+` and u.status = 'active'
+	`, arg).
 		Scan(&userID, &dbUser, &dbName, &dbPass, &dbNip, &dbRole, &dbStore)
-
-	if err == sql.ErrNoRows {
-		c.HTML(200, "login.html", gin.H{
-			"Title": "Login User",
-			"Error": "Username tidak ditemukan / atau mungkin user tidak aktif",
-		})
-		return
-	} else if err != nil {
-		c.HTML(500, "login.html", gin.H{
-			"Title": "Login User",
-			"Error": "Terjadi kesalahan saat mengambil data user",
-		})
-		return
-	}
-
-	// cek password
-	if bcrypt.CompareHashAndPassword([]byte(dbPass), []byte(password)) != nil {
-		c.HTML(200, "login.html", gin.H{
-			"Title": "Login User",
-			"Error": "Password salah",
-		})
-		return
+	if err != nil {
+		return models.SessionUser{}, "", err
 	}
 
-	// simpan session
-	userInitials := helpers.Initials(dbName)
-	session := sessions.Default(c)
-	session.Set("user", models.SessionUser{
+	return models.SessionUser{
 		UserID:          userID,
 		NIP:             dbNip.String,
 		Name:            dbName,
-		Initials:        userInitials,
+		Initials:        helpers.Initials(dbName),
 		Username:        dbUser,
 		Role:            dbRole.String,
 		StoreID:         dbStore.String,
 		IsAuthenticated: true,
-	})
+	}, dbPass, nil
+}
+
+// startUserSession menyimpan SessionUser ke session setelah seluruh langkah login selesai.
+func startUserSession(session sessions.Session, user models.SessionUser, enrollTwoFactor bool) error {
+	session.Delete(pendingTwoFactorUserKey)
+	session.Delete(pendingTwoFactorAtKey)
+
+	session.Set("user", user)
 	// simpan id user secara eksplisit agar mudah dipakai middleware permission
-	session.Set("user_id", userID)
-	if err := session.Save(); err != nil {
-		c.HTML(500, "login.html", gin.H{
-			"Title": "Login User",
-			"Error": "Gagal menyimpan sesi: " + err.Error(),
-		})
-		return
+	session.Set("user_id", user.UserID)
+	if enrollTwoFactor {
+		session.Set(middleware.TwoFactorEnrollKey, true)
+	} else {
+		session.Delete(middleware.TwoFactorEnrollKey)
 	}
 
-	c.Redirect(302, "/dashboard")
+	userRepo := &repositories.UserRepository{DB: config.DB}
+	mustChange, err := userRepo.MustChangePassword(user.UserID)
+	if err != nil {
+		return err
+	}
+	if mustChange {
+		session.Set(middleware.PasswordChangeKey, true)
+	} else {
+		session.Delete(middleware.PasswordChangeKey)
+	}
+
+	return session.Save()
 }
 
 func Logout(c *gin.Context) {
 	session := sessions.Default(c)
+	if u, ok := session.Get("user").(models.SessionUser); ok {
+		recordAuthEvent(c, models.AuthEventLogout, u.UserID, u.Username)
+	}
 	session.Clear()
 	session.Save()
 	c.Redirect(302, "/")
 }
 
-func CreateUser(c *gin.Context) {
-	username := c.PostForm("username")
-	password := c.PostForm("password")
-
-	// Check if username already exists
-	var existingUser string
-	err := config.DB.QueryRow("SELECT username FROM users WHERE username = ?", username).Scan(&existingUser)
-	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username already exists"})
-		return
-	} else if err != sql.ErrNoRows {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
-	}
-
-	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-		return
-	}
-
-	// Insert new user
-	_, err = config.DB.Exec("INSERT INTO users (username, password) VALUES (?, ?)", username, string(hashedPassword))
+// recordAuthEvent mencatat event login/logout beserta IP dan user agent request.
+// Kegagalan pencatatan hanya di-log agar tidak menghalangi proses login.
+func recordAuthEvent(c *gin.Context, event string, userID int, username string) {
+	err := newAuthEventService().Record(models.AuthEvent{
+		UserID:    userID,
+		Username:  username,
+		Event:     event,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-		return
+		log.Printf("failed to record auth event %s: %v", event, err)
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "User created successfully"})
 }
 
+func newAuthEventService() *services.AuthEventService {
+	return &services.AuthEventService{Repo: &repositories.AuthEventRepository{DB: config.DB}}
+}
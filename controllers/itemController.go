@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"errors"
+	"html/template"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newItemService() *services.ItemService {
+	return &services.ItemService{Repo: &repositories.ItemRepository{DB: config.DB}}
+}
+
+// ItemIndex menampilkan katalog hadiah dengan pencarian, filter kategori/status, dan paginasi.
+func ItemIndex(c *gin.Context) {
+	renderItemPage(c, gin.H{"FormModal": ""})
+}
+
+// ItemStore menyimpan item hadiah baru beserta gambarnya.
+func ItemStore(c *gin.Context) {
+	input, ok := itemInputFromForm(c, "itemModal")
+	if !ok {
+		return
+	}
+	image, err := itemImageFromForm(c)
+	if err != nil {
+		itemFormError(c, "itemModal", err)
+		return
+	}
+
+	if _, err := newItemService().CreateItem(input, image, auditActor(c)); err != nil {
+		itemFormError(c, "itemModal", err)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/items")
+}
+
+// ItemUpdate mengubah data item hadiah. Gambar lama dipertahankan jika tidak ada gambar baru.
+func ItemUpdate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("item_id"), 10, 64)
+	if err != nil || id <= 0 {
+		renderItemPage(c, gin.H{"FormModal": "", "Error": "Item tidak valid"})
+		return
+	}
+	input, ok := itemInputFromForm(c, "itemEditModal")
+	if !ok {
+		return
+	}
+	input.ID = id
+	input.RemoveImage = c.PostForm("remove_image") == "1"
+
+	image, err := itemImageFromForm(c)
+	if err != nil {
+		itemFormError(c, "itemEditModal", err)
+		return
+	}
+
+	if err := newItemService().UpdateItem(input, image, auditActor(c)); err != nil {
+		itemFormError(c, "itemEditModal", err)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/items")
+}
+
+// ItemDeactivate menonaktifkan item hadiah.
+func ItemDeactivate(c *gin.Context) {
+	setItemActive(c, false)
+}
+
+// ItemActivate mengaktifkan kembali item hadiah yang sebelumnya dinonaktifkan.
+func ItemActivate(c *gin.Context) {
+	setItemActive(c, true)
+}
+
+func setItemActive(c *gin.Context, active bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.String(http.StatusBadRequest, "invalid item id")
+		return
+	}
+
+	if err := newItemService().SetItemActive(id, active, auditActor(c)); err != nil {
+		if !services.IsClientError(err) {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		renderItemPage(c, gin.H{"FormModal": "", "Error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/items")
+}
+
+// itemInputFromForm membaca isian form item. Nilai yang bukan angka bulat (mis. "10k" atau "1.5")
+// ditolak dengan menampilkan ulang modal form; false berarti response sudah dikirim.
+func itemInputFromForm(c *gin.Context, modal string) (models.ItemInput, bool) {
+	value, err := strconv.ParseInt(strings.TrimSpace(c.PostForm("value")), 10, 64)
+	if err != nil {
+		renderItemFormError(c, modal, "nilai harus berupa angka bulat")
+		return models.ItemInput{}, false
+	}
+	return models.ItemInput{
+		SKU:       c.PostForm("sku"),
+		Name:      c.PostForm("name"),
+		Category:  c.PostForm("category"),
+		Unit:      c.PostForm("unit"),
+		ValueType: c.PostForm("value_type"),
+		Value:     value,
+	}, true
+}
+
+// itemImageFromForm mengambil file gambar dari form. Tidak memilih file bukan error (nil, nil).
+func itemImageFromForm(c *gin.Context) (*multipart.FileHeader, error) {
+	image, err := c.FormFile("image")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// itemFormError menampilkan ulang halaman item dengan modal form yang terbuka, pesan error
+// di dalam modal, dan isian form sebelumnya (kecuali file gambar).
+func itemFormError(c *gin.Context, modal string, err error) {
+	if !services.IsClientError(err) {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	renderItemFormError(c, modal, err.Error())
+}
+
+func renderItemFormError(c *gin.Context, modal, message string) {
+	oldInput := make(map[string][]string)
+	for key, values := range c.Request.PostForm {
+		if key == "_csrf" {
+			continue
+		}
+		oldInput[key] = values
+	}
+
+	renderItemPage(c, gin.H{
+		"FormError": message,
+		"FormModal": modal,
+		"OldInput":  oldInput,
+	})
+}
+
+func renderItemPage(c *gin.Context, data gin.H) {
+	itemSvc := newItemService()
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	filter := models.ItemFilter{
+		Query:    c.Query("q"),
+		Category: c.Query("category"),
+		Status:   c.Query("status"),
+		Page:     page,
+	}
+
+	items, total, err := itemSvc.SearchItems(filter)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	categories, err := itemSvc.GetCategories()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	totalPages := (total + services.ItemPerPage - 1) / services.ItemPerPage
+
+	data["Title"] = "Katalog Hadiah"
+	data["Page"] = "item"
+	data["items"] = items
+	data["Categories"] = categories
+	data["Filter"] = filter
+	data["Total"] = total
+	data["CurrentPage"] = page
+	data["TotalPages"] = totalPages
+	if page > 1 {
+		data["PrevURL"] = itemURL(filter, page-1)
+	}
+	if page < totalPages {
+		data["NextURL"] = itemURL(filter, page+1)
+	}
+
+	Render(c, "item.html", data)
+}
+
+// itemURL menyusun link halaman item dengan query filter untuk paginasi.
+func itemURL(filter models.ItemFilter, page int) template.URL {
+	q := url.Values{}
+	for key, val := range map[string]string{
+		"q":        filter.Query,
+		"category": filter.Category,
+		"status":   filter.Status,
+	} {
+		if val != "" {
+			q.Set(key, val)
+		}
+	}
+	if page > 1 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if len(q) == 0 {
+		return template.URL("/items")
+	}
+	return template.URL("/items?" + q.Encode())
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/mailer"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const forgotPasswordSentMessage = "Jika email tersebut terdaftar, link reset password telah dikirim. Silakan cek inbox Anda."
+
+// ForgotPasswordPage menampilkan form permintaan reset password.
+func ForgotPasswordPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "forgot_password.html", gin.H{
+		"Title":     "Lupa Password",
+		"CSRFToken": csrfToken(c),
+	})
+}
+
+// ForgotPasswordPost membuat token reset dan mengirim link ke email user. Permintaan dibatasi per
+// email dan per IP seperti login. Hasilnya selalu pesan sukses yang sama dan dikirim tanpa menunggu
+// email terkirim (kegagalan, termasuk error mailer, hanya dicatat di log) agar isi maupun waktu
+// respons tidak membocorkan email mana yang terdaftar.
+func ForgotPasswordPost(c *gin.Context) {
+	email := strings.TrimSpace(c.PostForm("email"))
+	if email == "" {
+		c.HTML(http.StatusOK, "forgot_password.html", gin.H{
+			"Title":     "Lupa Password",
+			"CSRFToken": csrfToken(c),
+			"Error":     "Email wajib diisi.",
+		})
+		return
+	}
+
+	throttleSvc := &services.LoginThrottleService{Repo: &repositories.LoginThrottleRepository{DB: config.DB}}
+	clientIP := c.ClientIP()
+
+	wait, err := throttleSvc.CheckPasswordReset(email, clientIP)
+	switch {
+	case err != nil:
+		log.Printf("password reset throttle check failed: %v", err)
+	case wait > 0:
+		log.Printf("password reset request throttled from %s", clientIP)
+	default:
+		if err := throttleSvc.RegisterPasswordReset(email, clientIP); err != nil {
+			log.Printf("password reset throttle update failed: %v", err)
+			break
+		}
+		// pencarian user, pembuatan token, dan pengiriman email berjalan di background agar waktu
+		// respons sama untuk email yang terdaftar maupun tidak
+		go func() {
+			if err := newPasswordResetService().RequestReset(email); err != nil {
+				log.Printf("password reset request failed: %v", err)
+			}
+		}()
+	}
+
+	c.HTML(http.StatusOK, "forgot_password.html", gin.H{
+		"Title":     "Lupa Password",
+		"CSRFToken": csrfToken(c),
+		"Success":   forgotPasswordSentMessage,
+	})
+}
+
+// ResetPasswordPage menampilkan form password baru jika token masih valid.
+func ResetPasswordPage(c *gin.Context) {
+	token := c.Query("token")
+	resetSvc := newPasswordResetService()
+
+	if err := resetSvc.ValidateToken(token); err != nil {
+		renderResetPassword(c, "", err.Error(), true)
+		return
+	}
+
+	renderResetPassword(c, token, "", false)
+}
+
+// ResetPasswordPost menyimpan password baru dan mengakhiri seluruh session user.
+func ResetPasswordPost(c *gin.Context) {
+	token := c.PostForm("token")
+	resetSvc := newPasswordResetService()
+
+	if err := resetSvc.ResetPassword(token, c.PostForm("password"), c.PostForm("password_confirmation")); err != nil {
+		renderResetPassword(c, token, err.Error(), err == services.ErrInvalidResetToken)
+		return
+	}
+
+	renderLogin(c, http.StatusOK, gin.H{
+		"Success": "Password berhasil diganti. Silakan login dengan password baru.",
+	})
+}
+
+func renderResetPassword(c *gin.Context, token, message string, invalidToken bool) {
+	c.HTML(http.StatusOK, "reset_password.html", gin.H{
+		"Title":        "Reset Password",
+		"CSRFToken":    csrfToken(c),
+		"Token":        token,
+		"InvalidToken": invalidToken,
+		"Error":        message,
+	})
+}
+
+func newPasswordResetService() *services.PasswordResetService {
+	return &services.PasswordResetService{
+		Repo:   &repositories.PasswordResetRepository{DB: config.DB},
+		Users:  &repositories.UserRepository{DB: config.DB},
+		Mailer: mailer.Default(),
+	}
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newPermissionService() *services.PermissionService {
+	return &services.PermissionService{Repo: &repositories.PermissionRepository{DB: config.DB}}
+}
+
+// PermissionIndex menampilkan seluruh permission per group beserta penanda orphan.
+func PermissionIndex(c *gin.Context) {
+	renderPermissionPage(c, "", "")
+}
+
+// PermissionUpdate mengubah group dan deskripsi satu permission.
+func PermissionUpdate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.PostForm("permission_id"), 10, 64)
+	if err != nil || id <= 0 {
+		renderPermissionPage(c, "Permission tidak valid", "")
+		return
+	}
+
+	err = newPermissionService().UpdatePermission(id, c.PostForm("group"), c.PostForm("description"), auditActor(c))
+	if err != nil {
+		permissionActionError(c, err)
+		return
+	}
+	renderPermissionPage(c, "", "Permission berhasil diperbarui.")
+}
+
+// PermissionGroupRename memindahkan seluruh permission di satu group ke nama group baru.
+func PermissionGroupRename(c *gin.Context) {
+	count, err := newPermissionService().RenameGroup(c.PostForm("from"), c.PostForm("to"), auditActor(c))
+	if err != nil {
+		permissionActionError(c, err)
+		return
+	}
+	renderPermissionPage(c, "", fmt.Sprintf("%d permission dipindahkan ke group %s.", count, c.PostForm("to")))
+}
+
+// PermissionDelete menghapus permission orphan.
+func PermissionDelete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.String(http.StatusBadRequest, "invalid permission id")
+		return
+	}
+
+	if err := newPermissionService().DeletePermission(id, auditActor(c)); err != nil {
+		permissionActionError(c, err)
+		return
+	}
+	renderPermissionPage(c, "", "Permission berhasil dihapus.")
+}
+
+func permissionActionError(c *gin.Context, err error) {
+	if services.IsClientError(err) {
+		renderPermissionPage(c, err.Error(), "")
+		return
+	}
+	c.String(http.StatusInternalServerError, err.Error())
+}
+
+func renderPermissionPage(c *gin.Context, message, success string) {
+	groups, err := newPermissionService().GetManagedPermissions()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	orphanCount := 0
+	for _, group := range groups {
+		for _, perm := range group.Permissions {
+			if perm.Orphan {
+				orphanCount++
+			}
+		}
+	}
+
+	Render(c, "permission.html", gin.H{
+		"Title":       "Permission",
+		"Page":        "permission",
+		"Groups":      groups,
+		"OrphanCount": orphanCount,
+		"Error":       message,
+		"Success":     success,
+	})
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileIndex menampilkan data akun, riwayat login, dan session aktif milik user yang sedang login.
+func ProfileIndex(c *gin.Context) {
+	renderProfile(c, "", "")
+}
+
+// ProfileSessionRevoke mengakhiri salah satu session lain milik user yang sedang login.
+func ProfileSessionRevoke(c *gin.Context) {
+	session := sessions.Default(c)
+	err := newAuthEventService().EndSession(sessionUserID(session), c.PostForm("session"), session.ID())
+	if err != nil {
+		renderProfile(c, err.Error(), "")
+		return
+	}
+	renderProfile(c, "", "Session berhasil diakhiri.")
+}
+
+// ProfileSessionRevokeOthers mengakhiri seluruh session user kecuali session yang sedang dipakai.
+func ProfileSessionRevokeOthers(c *gin.Context) {
+	session := sessions.Default(c)
+	count, err := newAuthEventService().EndOtherSessions(sessionUserID(session), session.ID())
+	if err != nil {
+		renderProfile(c, "Gagal mengakhiri session: "+err.Error(), "")
+		return
+	}
+	renderProfile(c, "", fmt.Sprintf("%d session lain berhasil diakhiri.", count))
+}
+
+func renderProfile(c *gin.Context, message, success string) {
+	session := sessions.Default(c)
+	user, _ := session.Get("user").(models.SessionUser)
+
+	renderSessionPage(c, sessionPage{
+		Page:      "profile",
+		Title:     "Profil",
+		UserID:    user.UserID,
+		Username:  user.Username,
+		Name:      user.Name,
+		CurrentID: session.ID(),
+		ActionURL: "/profile/sessions",
+		Self:      true,
+	}, message, success)
+}
+
+// sessionPage berisi konteks halaman riwayat login & session aktif, dipakai bersama oleh
+// halaman profil (user sendiri) dan halaman admin /users/:id/sessions.
+type sessionPage struct {
+	Page      string
+	Title     string
+	UserID    int
+	Username  string
+	Name      string
+	CurrentID string
+	ActionURL string
+	Self      bool
+}
+
+func renderSessionPage(c *gin.Context, p sessionPage, message, success string) {
+	eventSvc := newAuthEventService()
+
+	events, err := eventSvc.RecentEvents(p.UserID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	activeSessions, err := eventSvc.ActiveSessions(p.UserID, p.CurrentID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Render(c, "profile.html", gin.H{
+		"Title":           p.Title,
+		"Page":            p.Page,
+		"SubjectName":     p.Name,
+		"SubjectUsername": p.Username,
+		"Self":            p.Self,
+		"ActionURL":       p.ActionURL,
+		"Events":          events,
+		"ActiveSessions":  activeSessions,
+		"EventLimit":      services.AuthEventHistoryLimit,
+		"Error":           message,
+		"Success":         success,
+	})
+}
+
+// ProfilePasswordPage menampilkan form ganti password milik user yang sedang login.
+func ProfilePasswordPage(c *gin.Context) {
+	renderProfilePassword(c, "", "")
+}
+
+// ProfilePasswordUpdate mengganti password user sendiri dan mencabut tanda wajib ganti password.
+func ProfilePasswordUpdate(c *gin.Context) {
+	session := sessions.Default(c)
+	userSvc := &services.UserService{Repo: &repositories.UserRepository{DB: config.DB}}
+
+	err := userSvc.ChangePassword(
+		sessionUserID(session),
+		c.PostForm("current_password"),
+		c.PostForm("password"),
+		c.PostForm("password_confirmation"),
+	)
+	if err != nil {
+		renderProfilePassword(c, err.Error(), "")
+		return
+	}
+
+	session.Delete(middleware.PasswordChangeKey)
+	if err := session.Save(); err != nil {
+		renderProfilePassword(c, "Gagal menyimpan sesi: "+err.Error(), "")
+		return
+	}
+
+	renderProfilePassword(c, "", "Password berhasil diganti.")
+}
+
+func renderProfilePassword(c *gin.Context, message, success string) {
+	required, _ := sessions.Default(c).Get(middleware.PasswordChangeKey).(bool)
+
+	Render(c, "profile_password.html", gin.H{
+		"Title":    "Ganti Password",
+		"Page":     "profilePassword",
+		"Required": required,
+		"Policy":   services.LoadPasswordPolicy(),
+		"Error":    message,
+		"Success":  success,
+	})
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/mailer"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPage menampilkan form registrasi mandiri.
+func RegisterPage(c *gin.Context) {
+	renderRegister(c, http.StatusOK, "", "")
+}
+
+// RegisterPost menyimpan user baru berstatus pending dan mengirim email verifikasi. Percobaan
+// registrasi dibatasi per IP seperti login.
+func RegisterPost(c *gin.Context) {
+	throttleSvc := &services.LoginThrottleService{Repo: &repositories.LoginThrottleRepository{DB: config.DB}}
+	clientIP := c.ClientIP()
+
+	wait, err := throttleSvc.CheckRegistration(clientIP)
+	if err != nil {
+		renderRegister(c, http.StatusInternalServerError, "Terjadi kesalahan saat memeriksa percobaan registrasi", "")
+		return
+	}
+	if wait > 0 {
+		renderRegister(c, http.StatusTooManyRequests, "Terlalu banyak percobaan registrasi. Coba lagi dalam "+services.FormatWait(wait)+".", "")
+		return
+	}
+	if err := throttleSvc.RegisterRegistration(clientIP); err != nil {
+		renderRegister(c, http.StatusInternalServerError, "Terjadi kesalahan saat mencatat percobaan registrasi", "")
+		return
+	}
+
+	nip, err := strconv.Atoi(strings.TrimSpace(c.PostForm("nip")))
+	if err != nil {
+		renderRegister(c, http.StatusOK, "NIP harus berupa angka", "")
+		return
+	}
+
+	storeIDs := []int{}
+	for _, val := range c.PostFormArray("store_id") {
+		if val == "" {
+			continue
+		}
+		id, err := strconv.Atoi(val)
+		if err != nil {
+			renderRegister(c, http.StatusOK, "Store ID tidak valid", "")
+			return
+		}
+		storeIDs = append(storeIDs, id)
+	}
+
+	input := models.UserCreateInput{
+		NIP:      nip,
+		Name:     strings.TrimSpace(c.PostForm("name")),
+		Username: strings.TrimSpace(c.PostForm("username")),
+		Password: c.PostForm("password"),
+		Email:    strings.TrimSpace(c.PostForm("email")),
+		StoreIDs: storeIDs,
+	}
+
+	// pendaftar belum login, jadi username yang didaftarkan dicatat sebagai pelaku
+	actor := auditActor(c)
+	actor.Username = input.Username
+
+	if err := newRegistrationService().Register(input, c.PostForm("password_confirmation"), actor); err != nil {
+		if errors.Is(err, services.ErrVerificationMailFailed) {
+			log.Printf("registration mail failed: %v", err)
+			err = services.ErrVerificationMailFailed
+		}
+		renderRegister(c, http.StatusOK, err.Error(), "")
+		return
+	}
+
+	renderRegister(c, http.StatusOK, "", "Pendaftaran berhasil. Silakan cek email Anda untuk verifikasi, lalu tunggu persetujuan administrator.")
+}
+
+// RegisterVerify memverifikasi email dari link yang dikirim saat registrasi.
+func RegisterVerify(c *gin.Context) {
+	data := gin.H{}
+
+	if err := newRegistrationService().VerifyEmail(c.Query("token")); err != nil {
+		data["Error"] = err.Error()
+	} else {
+		data["Success"] = "Email berhasil diverifikasi. Akun Anda dapat dipakai setelah disetujui administrator."
+	}
+
+	renderLogin(c, http.StatusOK, data)
+}
+
+// UserApprove mengaktifkan user hasil registrasi mandiri.
+func UserApprove(c *gin.Context) {
+	userSvc := &services.UserService{Repo: &repositories.UserRepository{DB: config.DB}}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		renderUserPage(c, userSvc, "User tidak valid")
+		return
+	}
+
+	if err := newRegistrationService().Approve(id, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		renderUserPage(c, userSvc, err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/users")
+}
+
+func renderRegister(c *gin.Context, status int, message, success string) {
+	storeRepo := &repositories.StoreRepository{DB: config.DB}
+	stores, err := storeRepo.GetActive()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.HTML(status, "register.html", gin.H{
+		"Title":        "Registrasi",
+		"CSRFToken":    csrfToken(c),
+		"stores":       stores,
+		"PasswordHint": services.LoadPasswordPolicy().Summary(),
+		"Error":        message,
+		"Success":      success,
+		// isian sebelumnya agar user tidak perlu mengetik ulang saat validasi gagal
+		"Old": gin.H{
+			"name":     c.PostForm("name"),
+			"nip":      c.PostForm("nip"),
+			"username": c.PostForm("username"),
+			"email":    c.PostForm("email"),
+			"store_id": c.PostForm("store_id"),
+		},
+	})
+}
+
+func newRegistrationService() *services.RegistrationService {
+	return &services.RegistrationService{
+		Users:  &services.UserService{Repo: &repositories.UserRepository{DB: config.DB}},
+		Repo:   &repositories.RegistrationRepository{DB: config.DB},
+		Mailer: mailer.Default(),
+	}
+}
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"net/http"
+	"gobase-app/middleware"
 	"gobase-app/models"
+	"gobase-app/services"
 
 	helpers "gobase-app/helper"
 
@@ -72,12 +74,37 @@ func Render(c *gin.Context, name string, data gin.H) {
 	}
 
 	// ambil permissions dari context
-	permsAny, _ := c.Get("Permissions")
+	permsAny, _ := c.Get(middleware.PermissionsKey)
 	perms, _ := permsAny.(map[string]bool)
 
 	// inject global data (biar semua halaman dapat)
 	data["Permissions"] = perms
+	data["StorePermissions"] = storePermissions(c)
+	data["CSRFToken"] = csrfToken(c)
 
 	c.HTML(http.StatusOK, name, data)
 }
 
+// storePermissions mengambil permission yang dimiliki user minimal di satu store (termasuk permission
+// global), untuk menampilkan menu halaman yang datanya difilter per store.
+func storePermissions(c *gin.Context) map[string]bool {
+	v, _ := c.Get(middleware.StoreScopeKey)
+	scope, _ := v.(*services.StoreScope)
+	if scope == nil {
+		return nil
+	}
+
+	perms := make(map[string]bool, len(scope.Permissions)+len(scope.StoreGrants))
+	for name := range scope.StoreGrants {
+		perms[name] = scope.CanAnyStore(name)
+	}
+	for name, ok := range scope.Permissions {
+		perms[name] = perms[name] || ok
+	}
+	return perms
+}
+
+// csrfToken mengambil token CSRF yang disiapkan middleware.CSRF untuk disisipkan ke form.
+func csrfToken(c *gin.Context) string {
+	return c.GetString(middleware.CSRFContextKey)
+}
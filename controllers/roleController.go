@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"gobase-app/config"
+	"gobase-app/middleware"
 	"gobase-app/models"
 	"gobase-app/repositories"
 	"gobase-app/services"
@@ -13,7 +14,10 @@ import (
 )
 
 func RoleIndex(c *gin.Context) {
+	renderRolePage(c, "")
+}
 
+func renderRolePage(c *gin.Context, message string) {
 	roleRepo := &repositories.RoleRepository{DB: config.DB}
 	roleService := &services.RoleService{Repo: roleRepo}
 
@@ -27,12 +31,13 @@ func RoleIndex(c *gin.Context) {
 		"Title": "Daftar Role",
 		"Page":  "role",
 		"roles": roles,
+		"Error": message,
 	})
 
 }
 
 func RoleFormIndex(c *gin.Context) {
-	renderRoleForm(c, "")
+	renderRoleForm(c, nil, "")
 }
 
 // RoleEdit menampilkan form edit role beserta permission yang dimilikinya.
@@ -59,13 +64,20 @@ func RoleEdit(c *gin.Context) {
 // RoleStore menangani penyimpanan role baru dari form.
 func RoleStore(c *gin.Context) {
 	type roleForm struct {
-		Name      string `form:"name" binding:"required"`
-		GuardName string `form:"guard_name"`
+		Name              string `form:"name" binding:"required"`
+		GuardName         string `form:"guard_name"`
+		RequiresTwoFactor bool   `form:"requires_two_factor"`
+		IsAdmin           bool   `form:"is_admin"`
 	}
 
 	var form roleForm
+	parentIDs, parentsOK := parseRoleParents(c)
 	if err := c.ShouldBind(&form); err != nil {
-		renderRoleForm(c, "Form tidak lengkap")
+		renderRoleForm(c, parentIDs, "Form tidak lengkap")
+		return
+	}
+	if !parentsOK {
+		renderRoleForm(c, parentIDs, "Parent role tidak valid")
 		return
 	}
 
@@ -76,7 +88,7 @@ func RoleStore(c *gin.Context) {
 		}
 		id, err := strconv.ParseInt(val, 10, 64)
 		if err != nil || id <= 0 {
-			renderRoleForm(c, "Permission tidak valid")
+			renderRoleForm(c, parentIDs, "Permission tidak valid")
 			return
 		}
 		permissionIDs = append(permissionIDs, id)
@@ -86,13 +98,16 @@ func RoleStore(c *gin.Context) {
 	roleService := &services.RoleService{Repo: roleRepo}
 
 	input := models.RoleCreateInput{
-		Name:          form.Name,
-		GuardName:     form.GuardName,
-		PermissionIDs: permissionIDs,
+		Name:              form.Name,
+		GuardName:         form.GuardName,
+		IsAdmin:           form.IsAdmin,
+		RequiresTwoFactor: form.RequiresTwoFactor,
+		PermissionIDs:     permissionIDs,
+		ParentIDs:         parentIDs,
 	}
 
-	if err := roleService.CreateRole(input); err != nil {
-		renderRoleForm(c, err.Error())
+	if _, err := roleService.CreateRole(input, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		renderRoleForm(c, parentIDs, err.Error())
 		return
 	}
 
@@ -102,14 +117,21 @@ func RoleStore(c *gin.Context) {
 // RoleUpdate menangani pembaruan data role yang sudah ada.
 func RoleUpdate(c *gin.Context) {
 	type roleUpdateForm struct {
-		ID        int    `form:"role_id" binding:"required"`
-		Name      string `form:"name" binding:"required"`
-		GuardName string `form:"guard_name"`
+		ID                int    `form:"role_id" binding:"required"`
+		Name              string `form:"name" binding:"required"`
+		GuardName         string `form:"guard_name"`
+		RequiresTwoFactor bool   `form:"requires_two_factor"`
+		IsAdmin           bool   `form:"is_admin"`
 	}
 
 	var form roleUpdateForm
+	parentIDs, parentsOK := parseRoleParents(c)
 	if err := c.ShouldBind(&form); err != nil {
-		renderRoleEditForm(c, models.RoleDetail{ID: form.ID, Name: form.Name, GuardName: form.GuardName}, "Form tidak lengkap")
+		renderRoleEditForm(c, models.RoleDetail{ID: form.ID, Name: form.Name, GuardName: form.GuardName, IsAdmin: form.IsAdmin, RequiresTwoFactor: form.RequiresTwoFactor, ParentIDs: parentIDs}, "Form tidak lengkap")
+		return
+	}
+	if !parentsOK {
+		renderRoleEditForm(c, models.RoleDetail{ID: form.ID, Name: form.Name, GuardName: form.GuardName, IsAdmin: form.IsAdmin, RequiresTwoFactor: form.RequiresTwoFactor, ParentIDs: parentIDs}, "Parent role tidak valid")
 		return
 	}
 
@@ -121,10 +143,13 @@ func RoleUpdate(c *gin.Context) {
 		id, err := strconv.ParseInt(val, 10, 64)
 		if err != nil || id <= 0 {
 			renderRoleEditForm(c, models.RoleDetail{
-				ID:            form.ID,
-				Name:          form.Name,
-				GuardName:     form.GuardName,
-				PermissionIDs: permissionIDs,
+				ID:                form.ID,
+				Name:              form.Name,
+				GuardName:         form.GuardName,
+				IsAdmin:           form.IsAdmin,
+				RequiresTwoFactor: form.RequiresTwoFactor,
+				PermissionIDs:     permissionIDs,
+				ParentIDs:         parentIDs,
 			}, "Permission tidak valid")
 			return
 		}
@@ -135,18 +160,24 @@ func RoleUpdate(c *gin.Context) {
 	roleService := &services.RoleService{Repo: roleRepo}
 
 	input := models.RoleUpdateInput{
-		ID:            form.ID,
-		Name:          form.Name,
-		GuardName:     form.GuardName,
-		PermissionIDs: permissionIDs,
+		ID:                form.ID,
+		Name:              form.Name,
+		GuardName:         form.GuardName,
+		IsAdmin:           form.IsAdmin,
+		RequiresTwoFactor: form.RequiresTwoFactor,
+		PermissionIDs:     permissionIDs,
+		ParentIDs:         parentIDs,
 	}
 
-	if err := roleService.UpdateRole(input); err != nil {
+	if err := roleService.UpdateRole(input, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
 		renderRoleEditForm(c, models.RoleDetail{
-			ID:            form.ID,
-			Name:          strings.TrimSpace(form.Name),
-			GuardName:     strings.TrimSpace(form.GuardName),
-			PermissionIDs: permissionIDs,
+			ID:                form.ID,
+			Name:              strings.TrimSpace(form.Name),
+			GuardName:         strings.TrimSpace(form.GuardName),
+			IsAdmin:           form.IsAdmin,
+			RequiresTwoFactor: form.RequiresTwoFactor,
+			PermissionIDs:     permissionIDs,
+			ParentIDs:         parentIDs,
 		}, err.Error())
 		return
 	}
@@ -166,7 +197,11 @@ func RoleDelete(c *gin.Context) {
 	roleRepo := &repositories.RoleRepository{DB: config.DB}
 	roleService := &services.RoleService{Repo: roleRepo}
 
-	if err := roleService.DeleteRole(id); err != nil {
+	if err := roleService.DeleteRole(id, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		if services.IsClientError(err) {
+			renderRolePage(c, err.Error())
+			return
+		}
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -174,7 +209,7 @@ func RoleDelete(c *gin.Context) {
 	c.Redirect(http.StatusSeeOther, "/role")
 }
 
-func renderRoleForm(c *gin.Context, message string) {
+func renderRoleForm(c *gin.Context, parentIDs []int, message string) {
 	permissionRepo := &repositories.PermissionRepository{DB: config.DB}
 	permissionService := &services.PermissionService{Repo: permissionRepo}
 
@@ -189,12 +224,22 @@ func renderRoleForm(c *gin.Context, message string) {
 		totalPermissions += len(group.Permissions)
 	}
 
+	parentOptions, selectedParents, inherited, err := roleParentData(0, parentIDs)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	Render(c, "role_form.html", gin.H{
-		"Title":            "Form Role",
-		"Page":             "roleForm",
-		"PermissionGroups": permissionGroups,
-		"TotalPermissions": totalPermissions,
-		"Error":            message,
+		"Title":                "Form Role",
+		"Page":                 "roleForm",
+		"PermissionGroups":     permissionGroups,
+		"TotalPermissions":     totalPermissions,
+		"ParentOptions":        parentOptions,
+		"SelectedParents":      selectedParents,
+		"InheritedPermissions": inherited,
+		"CanManageAdmin":       canManageAdmin(c),
+		"Error":                message,
 	})
 
 }
@@ -219,14 +264,66 @@ func renderRoleEditForm(c *gin.Context, role models.RoleDetail, message string)
 		selectedPermissions[id] = true
 	}
 
+	parentOptions, selectedParents, inherited, err := roleParentData(role.ID, role.ParentIDs)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	Render(c, "role_form_edit.html", gin.H{
-		"Title":               "Edit Role",
-		"Page":                "roleEdit",
-		"PermissionGroups":    permissionGroups,
-		"TotalPermissions":    totalPermissions,
-		"SelectedPermissions": selectedPermissions,
-		"Role":                role,
-		"Error":               message,
+		"Title":                "Edit Role",
+		"Page":                 "roleEdit",
+		"PermissionGroups":     permissionGroups,
+		"TotalPermissions":     totalPermissions,
+		"SelectedPermissions":  selectedPermissions,
+		"ParentOptions":        parentOptions,
+		"SelectedParents":      selectedParents,
+		"InheritedPermissions": inherited,
+		"Role":                 role,
+		"CanManageAdmin":       canManageAdmin(c),
+		"Error":                message,
 	})
 }
 
+// parseRoleParents membaca pilihan parent role dari form. ok bernilai false jika ada id yang tidak valid.
+func parseRoleParents(c *gin.Context) (parentIDs []int, ok bool) {
+	ok = true
+	for _, val := range c.PostFormArray("parents") {
+		if strings.TrimSpace(val) == "" {
+			continue
+		}
+		id, err := strconv.Atoi(val)
+		if err != nil || id <= 0 {
+			ok = false
+			continue
+		}
+		parentIDs = append(parentIDs, id)
+	}
+	return parentIDs, ok
+}
+
+// roleParentData menyiapkan pilihan parent, parent yang tercentang, dan permission warisan untuk form role.
+func roleParentData(roleID int, parentIDs []int) ([]models.RoleOption, map[int]bool, map[int64][]string, error) {
+	roleService := &services.RoleService{Repo: &repositories.RoleRepository{DB: config.DB}}
+
+	options, err := roleService.ParentOptions(roleID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	inherited, err := roleService.InheritedPermissions(parentIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	selected := make(map[int]bool, len(parentIDs))
+	for _, id := range parentIDs {
+		selected[id] = true
+	}
+	return options, selected, inherited, nil
+}
+
+// canManageAdmin menentukan apakah pilihan role admin ditampilkan di form role.
+func canManageAdmin(c *gin.Context) bool {
+	isAdmin, err := services.IsAdminUser(middleware.CurrentUserID(c))
+	return err == nil && isAdmin
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/oidc"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcStateKey     = "oidc_state"
+	oidcNonceKey     = "oidc_nonce"
+	oidcVerifierKey  = "oidc_verifier"
+	oidcStartedAtKey = "oidc_started_at"
+
+	// batas waktu antara redirect ke identity provider dan callback.
+	oidcFlowTTL = 10 * time.Minute
+)
+
+// OIDCLogin memulai authorization code flow: state, nonce, dan code verifier PKCE disimpan
+// di session lalu browser diarahkan ke identity provider.
+func OIDCLogin(c *gin.Context) {
+	session := sessions.Default(c)
+	if session.Get("user") != nil {
+		c.Redirect(http.StatusFound, "/dashboard")
+		return
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		value, err := oidc.RandomString()
+		if err != nil {
+			renderLogin(c, http.StatusInternalServerError, gin.H{"Error": "Gagal memulai login SSO"})
+			return
+		}
+		secrets[i] = value
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	session.Set(oidcStateKey, state)
+	session.Set(oidcNonceKey, nonce)
+	session.Set(oidcVerifierKey, verifier)
+	session.Set(oidcStartedAtKey, time.Now().Unix())
+	if err := session.Save(); err != nil {
+		renderLogin(c, http.StatusInternalServerError, gin.H{"Error": "Gagal menyimpan sesi: " + err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	authURL, err := oidc.Default().AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Printf("oidc: %v", err)
+		renderLogin(c, http.StatusBadGateway, gin.H{"Error": "Login SSO sedang tidak tersedia, silakan coba lagi nanti"})
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback menerima authorization code dari identity provider, menukarnya dengan ID token,
+// mencocokkan user lokal, lalu melanjutkan login seperti login password (termasuk 2FA).
+func OIDCCallback(c *gin.Context) {
+	session := sessions.Default(c)
+
+	state, _ := session.Get(oidcStateKey).(string)
+	nonce, _ := session.Get(oidcNonceKey).(string)
+	verifier, _ := session.Get(oidcVerifierKey).(string)
+	startedAt, _ := session.Get(oidcStartedAtKey).(int64)
+
+	// state hanya boleh dipakai sekali
+	session.Delete(oidcStateKey)
+	session.Delete(oidcNonceKey)
+	session.Delete(oidcVerifierKey)
+	session.Delete(oidcStartedAtKey)
+	if err := session.Save(); err != nil {
+		renderLogin(c, http.StatusInternalServerError, gin.H{"Error": "Gagal menyimpan sesi: " + err.Error()})
+		return
+	}
+
+	renderLoginError := func(status int, message string) {
+		renderLogin(c, status, gin.H{"Error": message})
+	}
+
+	if c.Query("error") != "" {
+		renderLoginError(http.StatusOK, "Login SSO dibatalkan atau ditolak oleh identity provider")
+		return
+	}
+
+	if state == "" || time.Since(time.Unix(startedAt, 0)) > oidcFlowTTL ||
+		subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		renderLoginError(http.StatusBadRequest, "Sesi login SSO tidak valid atau sudah kedaluwarsa, silakan coba lagi")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	claims, err := oidc.Default().Exchange(ctx, c.Query("code"), verifier, nonce)
+	if err != nil {
+		log.Printf("oidc: %v", err)
+		renderLoginError(http.StatusBadGateway, "Login SSO gagal diverifikasi, silakan coba lagi")
+		return
+	}
+
+	username := claims.PreferredUsername
+	if username == "" {
+		username = claims.Email
+	}
+
+	userID, err := newSSOService().ResolveUser(claims, auditActor(c))
+	if err != nil {
+		recordAuthEvent(c, models.AuthEventLoginFailed, 0, username)
+		if errors.Is(err, services.ErrSSOUserNotFound) || errors.Is(err, services.ErrInvalidInput) || errors.Is(err, services.ErrConflict) {
+			renderLoginError(http.StatusForbidden, err.Error())
+			return
+		}
+		log.Printf("oidc: failed to resolve user: %v", err)
+		renderLoginError(http.StatusInternalServerError, "Terjadi kesalahan saat mencocokkan akun SSO")
+		return
+	}
+
+	user, _, err := findLoginUser("u.id = ?", userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		recordAuthEvent(c, models.AuthEventLoginFailed, userID, username)
+		renderLoginError(http.StatusForbidden, "Akun Anda belum aktif atau sudah dinonaktifkan")
+		return
+	}
+	if err != nil {
+		renderLoginError(http.StatusInternalServerError, "Terjadi kesalahan saat mengambil data user")
+		return
+	}
+
+	completeLogin(c, user, renderLoginError)
+}
+
+func newSSOService() *services.SSOService {
+	return &services.SSOService{
+		Users:      &services.UserService{Repo: &repositories.UserRepository{DB: config.DB}},
+		Identities: &repositories.UserIdentityRepository{DB: config.DB},
+		Settings:   services.LoadSSOSettings(),
+	}
+}
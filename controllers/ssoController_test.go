@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"encoding/json"
+	"gobase-app/oidc"
+	"gobase-app/sessionstore"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// callbackIssuer adalah identity provider palsu untuk callback SSO: token endpoint hanya
+// menerima code beserta code_verifier yang challenge-nya cocok dengan saat code diterbitkan.
+type callbackIssuer struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	codes     map[string]string // code -> code_challenge
+	tokenHits int
+}
+
+func newCallbackIssuer(t *testing.T) *callbackIssuer {
+	t.Helper()
+	m := &callbackIssuer{codes: map[string]string{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.tokenHits++
+
+		challenge, ok := m.codes[r.PostFormValue("code")]
+		if !ok || oidc.CodeChallenge(r.PostFormValue("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		// jalur sukses tidak diuji di sini; id token diuji di paket oidc
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *callbackIssuer) issue(code, challenge string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes[code] = challenge
+}
+
+func (m *callbackIssuer) hits() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tokenHits
+}
+
+func newSSOTestRouter(t *testing.T, issuer *callbackIssuer) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	oidc.SetDefault(oidc.New(oidc.Config{
+		IssuerURL:   issuer.server.URL,
+		ClientID:    "client-1",
+		RedirectURL: "https://app.test/login/oidc/callback",
+		HTTPClient:  issuer.server.Client(),
+	}))
+	t.Cleanup(func() { oidc.SetDefault(nil) })
+
+	r := gin.New()
+	r.SetHTMLTemplate(template.Must(template.New("login.html").Parse(`{{.Error}}`)))
+	store := sessionstore.NewStore(sessionstore.NewMemoryBackend(), []byte("0123456789abcdef0123456789abcdef"))
+	r.Use(sessions.Sessions("mysession", store))
+	r.GET("/login/oidc", OIDCLogin)
+	r.GET("/login/oidc/callback", OIDCCallback)
+	return r
+}
+
+// ssoFlow adalah satu browser yang sudah diarahkan ke identity provider.
+type ssoFlow struct {
+	cookies   []*http.Cookie
+	state     string
+	challenge string
+}
+
+func startSSOFlow(t *testing.T, r *gin.Engine) ssoFlow {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login/oidc", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("GET /login/oidc = %d, want 302: %s", w.Code, w.Body.String())
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ssoFlow{
+		cookies:   w.Result().Cookies(),
+		state:     location.Query().Get("state"),
+		challenge: location.Query().Get("code_challenge"),
+	}
+}
+
+func (f ssoFlow) callback(r *gin.Engine, query url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/login/oidc/callback?"+query.Encode(), nil)
+	for _, cookie := range f.cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestOIDCCallbackRejectsStateAndPKCEMismatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		run           func(t *testing.T, r *gin.Engine, issuer *callbackIssuer) *httptest.ResponseRecorder
+		wantStatus    int
+		wantTokenHits int
+	}{
+		{
+			name: "state berbeda",
+			run: func(t *testing.T, r *gin.Engine, issuer *callbackIssuer) *httptest.ResponseRecorder {
+				flow := startSSOFlow(t, r)
+				issuer.issue("code-1", flow.challenge)
+				return flow.callback(r, url.Values{"state": {"state-lain"}, "code": {"code-1"}})
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "tanpa flow di session",
+			run: func(t *testing.T, r *gin.Engine, issuer *callbackIssuer) *httptest.ResponseRecorder {
+				return ssoFlow{}.callback(r, url.Values{"state": {""}, "code": {"code-1"}})
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "state hanya bisa dipakai sekali",
+			run: func(t *testing.T, r *gin.Engine, issuer *callbackIssuer) *httptest.ResponseRecorder {
+				flow := startSSOFlow(t, r)
+				flow.callback(r, url.Values{"state": {"state-lain"}, "code": {"code-1"}})
+				return flow.callback(r, url.Values{"state": {flow.state}, "code": {"code-1"}})
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "code milik flow lain ditolak PKCE",
+			run: func(t *testing.T, r *gin.Engine, issuer *callbackIssuer) *httptest.ResponseRecorder {
+				victim := startSSOFlow(t, r)
+				attacker := startSSOFlow(t, r)
+				issuer.issue("code-attacker", attacker.challenge)
+				return victim.callback(r, url.Values{"state": {victim.state}, "code": {"code-attacker"}})
+			},
+			wantStatus:    http.StatusBadGateway,
+			wantTokenHits: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newCallbackIssuer(t)
+			r := newSSOTestRouter(t, issuer)
+
+			w := tt.run(t, r, issuer)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("callback = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if strings.TrimSpace(w.Body.String()) == "" {
+				t.Error("pesan error login kosong")
+			}
+			if hits := issuer.hits(); hits != tt.wantTokenHits {
+				t.Errorf("token endpoint dipanggil %d kali, want %d", hits, tt.wantTokenHits)
+			}
+		})
+	}
+}
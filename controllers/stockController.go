@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newStockService() *services.StockService {
+	return &services.StockService{
+		Repo:   &repositories.StockRepository{DB: config.DB},
+		Items:  &repositories.ItemRepository{DB: config.DB},
+		Stores: &repositories.StoreRepository{DB: config.DB},
+	}
+}
+
+// StockIndex menampilkan saldo stok per item per store milik user beserta form mutasi stok.
+func StockIndex(c *gin.Context) {
+	renderStockPage(c, gin.H{"FormModal": ""})
+}
+
+// StockReceive mencatat penerimaan stok di store form store_id.
+func StockReceive(c *gin.Context) {
+	recordStockMovement(c, models.StockReceipt, "stock_receive", "stockReceiptModal")
+}
+
+// StockIssue mencatat pengeluaran hadiah dari store form store_id.
+func StockIssue(c *gin.Context) {
+	recordStockMovement(c, models.StockIssue, "stock_issue", "stockIssueModal")
+}
+
+// StockAdjust menyamakan saldo di store form store_id dengan hasil hitung fisik.
+func StockAdjust(c *gin.Context) {
+	recordStockMovement(c, models.StockAdjustment, "stock_adjust", "stockAdjustmentModal")
+}
+
+// StockTransfer memindahkan stok dari store form store_id ke store form to_store_id.
+func StockTransfer(c *gin.Context) {
+	recordStockMovement(c, models.StockTransfer, "stock_transfer", "stockTransferModal")
+}
+
+func recordStockMovement(c *gin.Context, movementType, perm, modal string) {
+	storeID, _ := strconv.Atoi(c.PostForm("store_id"))
+	// middleware sudah mengecek store dari request, tetapi store yang dipakai di sini selalu form
+	// store_id; cek ulang agar keduanya tidak bisa dibuat berbeda
+	scope, err := middleware.CurrentStoreScope(c)
+	if err != nil || !scope.Can(perm, storeID) {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"code_error": 3,
+			"error":      "Anda Tidak punya Akses di store ini",
+		})
+		return
+	}
+	toStoreID, _ := strconv.Atoi(c.PostForm("to_store_id"))
+	itemID, _ := strconv.ParseInt(c.PostForm("item_id"), 10, 64)
+	quantity, err := strconv.ParseInt(strings.TrimSpace(c.PostForm("quantity")), 10, 64)
+	if err != nil {
+		stockFormError(c, modal, "jumlah harus berupa angka bulat")
+		return
+	}
+
+	input := models.StockMovementInput{
+		Type:      movementType,
+		StoreID:   storeID,
+		ItemID:    itemID,
+		Quantity:  quantity,
+		ToStoreID: toStoreID,
+		Reason:    c.PostForm("reason"),
+		Reference: c.PostForm("reference"),
+	}
+	if err := newStockService().RecordMovement(input, auditActor(c)); err != nil {
+		if !services.IsClientError(err) {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		stockFormError(c, modal, err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/stock")
+}
+
+// stockFormError menampilkan ulang halaman stok dengan modal form yang terbuka, pesan error di
+// dalam modal, dan isian form sebelumnya.
+func stockFormError(c *gin.Context, modal, message string) {
+	oldInput := make(map[string][]string)
+	for key, values := range c.Request.PostForm {
+		if key == "_csrf" {
+			continue
+		}
+		oldInput[key] = values
+	}
+
+	renderStockPage(c, gin.H{
+		"FormError": message,
+		"FormModal": modal,
+		"OldInput":  oldInput,
+	})
+}
+
+func renderStockPage(c *gin.Context, data gin.H) {
+	scope, err := middleware.CurrentStoreScope(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	stockSvc := newStockService()
+
+	storeID, _ := strconv.Atoi(c.Query("store_id"))
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	filter := models.StockBalanceFilter{
+		StoreID:  storeID,
+		Query:    c.Query("q"),
+		Category: c.Query("category"),
+		Page:     page,
+	}
+
+	balances, total, err := stockSvc.SearchBalances(scope.FilterFor("stock_view"), filter)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stores, err := stockSvc.GetStores(scope.Filter())
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	categories, err := stockSvc.GetCategories()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	items, err := stockSvc.GetItems()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	targets, err := stockSvc.GetTransferTargets()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	totalPages := (total + services.StockPerPage - 1) / services.StockPerPage
+	params := map[string]string{"q": filter.Query, "category": filter.Category}
+	if filter.StoreID > 0 {
+		params["store_id"] = strconv.Itoa(filter.StoreID)
+	}
+
+	data["Title"] = "Stok Hadiah"
+	data["Page"] = "stock"
+	data["balances"] = balances
+	data["Filter"] = filter
+	data["Total"] = total
+	data["CurrentPage"] = page
+	data["TotalPages"] = totalPages
+	if page > 1 {
+		data["PrevURL"] = stockURL("/stock", params, page-1)
+	}
+	if page < totalPages {
+		data["NextURL"] = stockURL("/stock", params, page+1)
+	}
+	data["Stores"] = storesWithPermission(scope, stores, "stock_view")
+	data["Categories"] = categories
+	data["Items"] = items
+	data["TransferTargets"] = targets
+	data["ReceiptStores"] = storesWithPermission(scope, stores, "stock_receive")
+	data["IssueStores"] = storesWithPermission(scope, stores, "stock_issue")
+	data["AdjustmentStores"] = storesWithPermission(scope, stores, "stock_adjust")
+	data["TransferStores"] = storesWithPermission(scope, stores, "stock_transfer")
+
+	Render(c, "stock.html", data)
+}
+
+// StockMovementIndex menampilkan ledger mutasi stok di store milik user dengan filter dan paginasi.
+func StockMovementIndex(c *gin.Context) {
+	scope, err := middleware.CurrentStoreScope(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	stockSvc := newStockService()
+
+	storeID, _ := strconv.Atoi(c.Query("store_id"))
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	filter := models.StockMovementFilter{
+		StoreID:  storeID,
+		Query:    c.Query("q"),
+		Type:     c.Query("type"),
+		DateFrom: c.Query("date_from"),
+		DateTo:   c.Query("date_to"),
+		Page:     page,
+	}
+
+	stores, err := stockSvc.GetStores(scope.Filter())
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data := gin.H{
+		"Title":      "Ledger Stok",
+		"Page":       "stockMovements",
+		"Filter":     filter,
+		"Stores":     storesWithPermission(scope, stores, "stock_view"),
+		"Types":      services.StockMovementTypes,
+		"TotalPages": 0,
+	}
+
+	movements, total, err := stockSvc.SearchMovements(scope.FilterFor("stock_view"), filter)
+	if err != nil {
+		if !services.IsClientError(err) {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		data["Error"] = err.Error()
+		Render(c, "stock_movements.html", data)
+		return
+	}
+
+	totalPages := (total + services.StockPerPage - 1) / services.StockPerPage
+	params := map[string]string{
+		"q":         filter.Query,
+		"type":      filter.Type,
+		"date_from": filter.DateFrom,
+		"date_to":   filter.DateTo,
+	}
+	if filter.StoreID > 0 {
+		params["store_id"] = strconv.Itoa(filter.StoreID)
+	}
+
+	data["movements"] = movements
+	data["Total"] = total
+	data["CurrentPage"] = page
+	data["TotalPages"] = totalPages
+	if page > 1 {
+		data["PrevURL"] = stockURL("/stock/movements", params, page-1)
+	}
+	if page < totalPages {
+		data["NextURL"] = stockURL("/stock/movements", params, page+1)
+	}
+
+	Render(c, "stock_movements.html", data)
+}
+
+// storesWithPermission menyaring stores ke store tempat user memiliki perm.
+func storesWithPermission(scope *services.StoreScope, stores []models.Store, perm string) []models.Store {
+	allowed := []models.Store{}
+	for _, store := range stores {
+		if scope.Can(perm, store.StoreID) {
+			allowed = append(allowed, store)
+		}
+	}
+	return allowed
+}
+
+// stockURL menyusun link halaman stok dengan query filter untuk paginasi.
+func stockURL(path string, params map[string]string, page int) template.URL {
+	q := url.Values{}
+	for key, val := range params {
+		if val != "" {
+			q.Set(key, val)
+		}
+	}
+	if page > 1 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if len(q) == 0 {
+		return template.URL(path)
+	}
+	return template.URL(path + "?" + q.Encode())
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/services"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestStockMovementChecksFormStore(t *testing.T) {
+	// stock_issue hanya di store 2; store 1 juga milik user tetapi tanpa permission tersebut
+	scope := &services.StoreScope{StoreIDs: []int{1, 2}, StoreGrants: map[string][]int{"stock_issue": {2}}}
+
+	tests := []struct {
+		name  string
+		query string
+		form  string
+	}{
+		{name: "query store yang diizinkan, form store tanpa permission", query: "2", form: "1"},
+		{name: "form store di luar store user", query: "2", form: "9"},
+		{name: "tanpa store", query: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			prevDB := config.DB
+			config.DB = db
+			t.Cleanup(func() {
+				config.DB = prevDB
+				db.Close()
+			})
+
+			// handler dipanggil tanpa middleware agar cek store di handler sendiri yang diuji
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.SetHTMLTemplate(template.Must(template.New("error.html").Parse(`{{ .error }}`)))
+			r.Use(func(c *gin.Context) { c.Set(middleware.StoreScopeKey, scope) })
+			r.POST("/stock/issue", StockIssue)
+
+			form := url.Values{"item_id": {"5"}, "quantity": {"1"}}
+			if tt.form != "" {
+				form.Set("store_id", tt.form)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/stock/issue?store_id="+tt.query, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, http.StatusForbidden, w.Body)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
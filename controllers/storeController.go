@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newStoreService() *services.StoreService {
+	return &services.StoreService{Repo: &repositories.StoreRepository{DB: config.DB}}
+}
+
+// StoreIndex menampilkan daftar store milik user yang login dengan pencarian dan filter status.
+func StoreIndex(c *gin.Context) {
+	renderStorePage(c, gin.H{"FormModal": ""})
+}
+
+// StoreStore menyimpan store baru.
+func StoreStore(c *gin.Context) {
+	input := storeInputFromForm(c)
+	if _, err := newStoreService().CreateStore(input, auditActor(c)); err != nil {
+		storeFormError(c, "storeModal", err)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/stores")
+}
+
+// StoreUpdate mengubah kode, nama, dan alamat store.
+func StoreUpdate(c *gin.Context) {
+	input := storeInputFromForm(c)
+	id, err := strconv.Atoi(c.PostForm("store_id"))
+	if err != nil || id <= 0 {
+		renderStorePage(c, gin.H{"FormModal": "", "Error": "Store tidak valid"})
+		return
+	}
+	input.ID = id
+
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := newStoreService().UpdateStore(filter, input, auditActor(c)); err != nil {
+		storeFormError(c, "storeEditModal", err)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/stores")
+}
+
+// StoreDeactivate menonaktifkan store sehingga tidak bisa dipilih lagi di form user.
+func StoreDeactivate(c *gin.Context) {
+	setStoreActive(c, false)
+}
+
+// StoreActivate mengaktifkan kembali store yang sebelumnya dinonaktifkan.
+func StoreActivate(c *gin.Context) {
+	setStoreActive(c, true)
+}
+
+func setStoreActive(c *gin.Context, active bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.String(http.StatusBadRequest, "invalid store id")
+		return
+	}
+
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := newStoreService().SetStoreActive(filter, id, active, auditActor(c)); err != nil {
+		if !services.IsClientError(err) {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		renderStorePage(c, gin.H{"FormModal": "", "Error": err.Error()})
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/stores")
+}
+
+func storeInputFromForm(c *gin.Context) models.StoreInput {
+	return models.StoreInput{
+		Code:    c.PostForm("store_code"),
+		Name:    c.PostForm("store_name"),
+		Address: c.PostForm("store_address"),
+	}
+}
+
+// storeFormError menampilkan ulang halaman store dengan modal form yang terbuka, pesan error
+// di dalam modal, dan isian form sebelumnya.
+func storeFormError(c *gin.Context, modal string, err error) {
+	if !services.IsClientError(err) {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	oldInput := make(map[string][]string)
+	for key, values := range c.Request.PostForm {
+		if key == "_csrf" {
+			continue
+		}
+		oldInput[key] = values
+	}
+
+	renderStorePage(c, gin.H{
+		"FormError": err.Error(),
+		"FormModal": modal,
+		"OldInput":  oldInput,
+	})
+}
+
+func renderStorePage(c *gin.Context, data gin.H) {
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	search := models.StoreSearch{Query: c.Query("q"), Status: c.Query("status")}
+	stores, err := newStoreService().SearchStores(filter, search)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data["Title"] = "Daftar Store"
+	data["Page"] = "store"
+	data["stores"] = stores
+	data["Search"] = search
+
+	Render(c, "store.html", data)
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentStoreFilter membatasi query ke store milik user yang sedang login (admin tanpa batasan).
+func currentStoreFilter(c *gin.Context) (models.StoreFilter, error) {
+	scope, err := middleware.CurrentStoreScope(c)
+	if err != nil {
+		return models.StoreFilter{}, err
+	}
+	return scope.Filter(), nil
+}
+
+// userInStoreScope mengecek apakah user userID berada di minimal satu store milik user yang sedang
+// login. User yang tidak ada dianggap di luar scope.
+func userInStoreScope(c *gin.Context, userID int) (bool, error) {
+	scope, err := middleware.CurrentStoreScope(c)
+	if err != nil {
+		return false, err
+	}
+
+	storeIDs, err := (&repositories.UserRepository{DB: config.DB}).GetStoreIDs(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return scope.AllowsAny(storeIDs), nil
+}
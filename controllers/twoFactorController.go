@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	pendingTwoFactorUserKey = "pending_2fa_user_id"
+	pendingTwoFactorAtKey   = "pending_2fa_at"
+
+	// batas waktu antara password benar dan input kode 2FA.
+	pendingTwoFactorTTL = 5 * time.Minute
+)
+
+// TwoFactorChallengePage menampilkan form kode 2FA setelah password terverifikasi.
+func TwoFactorChallengePage(c *gin.Context) {
+	if _, ok := pendingTwoFactorUser(sessions.Default(c)); !ok {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	renderTwoFactorChallenge(c, http.StatusOK, "")
+}
+
+// TwoFactorChallengePost memverifikasi kode TOTP / recovery code lalu menyelesaikan login.
+func TwoFactorChallengePost(c *gin.Context) {
+	session := sessions.Default(c)
+	userID, ok := pendingTwoFactorUser(session)
+	if !ok {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	user, _, err := findLoginUser("u.id = ?", userID)
+	if err != nil {
+		session.Delete(pendingTwoFactorUserKey)
+		session.Delete(pendingTwoFactorAtKey)
+		session.Save()
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	// kode 2FA ikut dibatasi oleh throttle login agar tidak bisa di-brute-force
+	throttleSvc := &services.LoginThrottleService{Repo: &repositories.LoginThrottleRepository{DB: config.DB}}
+	wait, err := throttleSvc.Check(user.Username, c.ClientIP())
+	if err != nil {
+		renderTwoFactorChallenge(c, http.StatusInternalServerError, "Terjadi kesalahan saat memeriksa percobaan login")
+		return
+	}
+	if wait > 0 {
+		renderTwoFactorChallenge(c, http.StatusTooManyRequests, "Terlalu banyak percobaan. Coba lagi dalam "+services.FormatWait(wait)+".")
+		return
+	}
+
+	twoFactorSvc := &services.TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: config.DB}}
+	valid, err := twoFactorSvc.Verify(userID, c.PostForm("code"))
+	if err != nil {
+		renderTwoFactorChallenge(c, http.StatusInternalServerError, "Terjadi kesalahan saat memverifikasi kode")
+		return
+	}
+	if !valid {
+		recordAuthEvent(c, models.AuthEventLoginFailed, user.UserID, user.Username)
+		if err := throttleSvc.RegisterFailure(user.Username, c.ClientIP()); err != nil {
+			renderTwoFactorChallenge(c, http.StatusInternalServerError, "Terjadi kesalahan saat mencatat percobaan login")
+			return
+		}
+		renderTwoFactorChallenge(c, http.StatusOK, "Kode verifikasi tidak valid")
+		return
+	}
+
+	if err := throttleSvc.RegisterSuccess(user.Username); err != nil {
+		renderTwoFactorChallenge(c, http.StatusInternalServerError, "Terjadi kesalahan saat mencatat percobaan login")
+		return
+	}
+
+	if err := startUserSession(session, user, false); err != nil {
+		renderTwoFactorChallenge(c, http.StatusInternalServerError, "Gagal menyimpan sesi: "+err.Error())
+		return
+	}
+	recordAuthEvent(c, models.AuthEventLoginSuccess, user.UserID, user.Username)
+
+	c.Redirect(http.StatusFound, "/dashboard")
+}
+
+// TwoFactorSetupPage menampilkan status 2FA user dan QR pendaftaran jika belum aktif.
+func TwoFactorSetupPage(c *gin.Context) {
+	renderTwoFactorSetup(c, "", nil)
+}
+
+// TwoFactorEnable mengaktifkan 2FA dengan kode pertama dari aplikasi authenticator.
+func TwoFactorEnable(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := sessionUserID(session)
+	twoFactorSvc := &services.TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: config.DB}}
+
+	codes, err := twoFactorSvc.ConfirmEnrollment(userID, c.PostForm("code"))
+	if err != nil {
+		renderTwoFactorSetup(c, err.Error(), nil)
+		return
+	}
+
+	session.Delete(middleware.TwoFactorEnrollKey)
+	if err := session.Save(); err != nil {
+		renderTwoFactorSetup(c, "Gagal menyimpan sesi: "+err.Error(), codes)
+		return
+	}
+
+	renderTwoFactorSetup(c, "", codes)
+}
+
+// TwoFactorRecoveryCodes membuat ulang recovery code.
+func TwoFactorRecoveryCodes(c *gin.Context) {
+	userID := sessionUserID(sessions.Default(c))
+	twoFactorSvc := &services.TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: config.DB}}
+
+	codes, err := twoFactorSvc.RegenerateRecoveryCodes(userID, c.PostForm("code"))
+	if err != nil {
+		renderTwoFactorSetup(c, err.Error(), nil)
+		return
+	}
+
+	renderTwoFactorSetup(c, "", codes)
+}
+
+// TwoFactorDisable menonaktifkan 2FA user.
+func TwoFactorDisable(c *gin.Context) {
+	userID := sessionUserID(sessions.Default(c))
+	twoFactorSvc := &services.TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: config.DB}}
+
+	if err := twoFactorSvc.Disable(userID, c.PostForm("code")); err != nil {
+		renderTwoFactorSetup(c, err.Error(), nil)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/profile/2fa")
+}
+
+func renderTwoFactorChallenge(c *gin.Context, status int, message string) {
+	c.HTML(status, "two_factor_challenge.html", gin.H{
+		"Title":     "Verifikasi 2FA",
+		"CSRFToken": csrfToken(c),
+		"Error":     message,
+	})
+}
+
+func renderTwoFactorSetup(c *gin.Context, message string, recoveryCodes []string) {
+	session := sessions.Default(c)
+	userID := sessionUserID(session)
+	twoFactorSvc := &services.TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: config.DB}}
+
+	state, err := twoFactorSvc.GetState(userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	required, err := twoFactorSvc.IsRequired(userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var setup *models.TwoFactorSetup
+	if !state.Enabled {
+		account := ""
+		if u, ok := session.Get("user").(models.SessionUser); ok {
+			account = u.Username
+		}
+		setup, err = twoFactorSvc.BeginEnrollment(userID, account)
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	Render(c, "two_factor.html", gin.H{
+		"Title":         "Two-Factor Authentication",
+		"Page":          "twoFactor",
+		"State":         state,
+		"Setup":         setup,
+		"Required":      required,
+		"RecoveryCodes": recoveryCodes,
+		"Error":         message,
+	})
+}
+
+// pendingTwoFactorUser mengambil user yang sedang menunggu verifikasi 2FA dan belum kedaluwarsa.
+func pendingTwoFactorUser(session sessions.Session) (int, bool) {
+	userID, _ := session.Get(pendingTwoFactorUserKey).(int)
+	startedAt, _ := session.Get(pendingTwoFactorAtKey).(int64)
+	if userID <= 0 || time.Since(time.Unix(startedAt, 0)) > pendingTwoFactorTTL {
+		return 0, false
+	}
+	return userID, true
+}
+
+// sessionUserID mengambil id user yang sedang login dari session.
+func sessionUserID(session sessions.Session) int {
+	if id, ok := session.Get("user_id").(int); ok {
+		return id
+	}
+	if u, ok := session.Get("user").(models.SessionUser); ok {
+		return u.UserID
+	}
+	return 0
+}
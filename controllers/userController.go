@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"gobase-app/config"
+	"gobase-app/middleware"
 	"gobase-app/models"
 	"gobase-app/repositories"
 	"gobase-app/services"
@@ -37,13 +38,13 @@ func UserStore(c *gin.Context) {
 	)
 
 	if err := c.ShouldBind(&form); err != nil {
-		renderUserPage(c, userSvc, "Form tidak lengkap")
+		renderUserFormError(c, userSvc, "userModal", "Form tidak lengkap")
 		return
 	}
 
 	nip, err := strconv.Atoi(strings.TrimSpace(form.NIP))
 	if err != nil {
-		renderUserPage(c, userSvc, "NIP harus berupa angka")
+		renderUserFormError(c, userSvc, "userModal", "NIP harus berupa angka")
 		return
 	}
 
@@ -54,7 +55,7 @@ func UserStore(c *gin.Context) {
 		}
 		id, err := strconv.Atoi(val)
 		if err != nil {
-			renderUserPage(c, userSvc, "Store ID tidak valid")
+			renderUserFormError(c, userSvc, "userModal", "Store ID tidak valid")
 			return
 		}
 		storeIDs = append(storeIDs, id)
@@ -69,10 +70,12 @@ func UserStore(c *gin.Context) {
 		Status:    form.Status,
 		StoreIDs:  storeIDs,
 		RoleNames: c.PostFormArray("roles"),
+
+		MustChangePassword: c.PostForm("must_change_password") == "true",
 	}
 
-	if err := userSvc.CreateUser(input); err != nil {
-		renderUserPage(c, userSvc, err.Error())
+	if _, err := userSvc.CreateUser(input, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		renderUserFormError(c, userSvc, "userModal", err.Error())
 		return
 	}
 
@@ -98,13 +101,13 @@ func UserUpdate(c *gin.Context) {
 	)
 
 	if err := c.ShouldBind(&form); err != nil {
-		renderUserPage(c, userSvc, "Form tidak lengkap")
+		renderUserFormError(c, userSvc, "userEditModal", "Form tidak lengkap")
 		return
 	}
 
 	nip, err := strconv.Atoi(strings.TrimSpace(form.NIP))
 	if err != nil {
-		renderUserPage(c, userSvc, "NIP harus berupa angka")
+		renderUserFormError(c, userSvc, "userEditModal", "NIP harus berupa angka")
 		return
 	}
 
@@ -115,7 +118,7 @@ func UserUpdate(c *gin.Context) {
 		}
 		id, err := strconv.Atoi(val)
 		if err != nil {
-			renderUserPage(c, userSvc, "Store ID tidak valid")
+			renderUserFormError(c, userSvc, "userEditModal", "Store ID tidak valid")
 			return
 		}
 		storeIDs = append(storeIDs, id)
@@ -131,10 +134,12 @@ func UserUpdate(c *gin.Context) {
 		Status:    form.Status,
 		StoreIDs:  storeIDs,
 		RoleNames: c.PostFormArray("roles"),
+
+		MustChangePassword: c.PostForm("must_change_password") == "true",
 	}
 
-	if err := userSvc.UpdateUser(input); err != nil {
-		renderUserPage(c, userSvc, err.Error())
+	if err := userSvc.UpdateUser(input, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		renderUserFormError(c, userSvc, "userEditModal", err.Error())
 		return
 	}
 
@@ -153,7 +158,11 @@ func UserDelete(c *gin.Context) {
 	userRepo := &repositories.UserRepository{DB: config.DB}
 	userService := &services.UserService{Repo: userRepo}
 
-	if err := userService.DeleteUser(id); err != nil {
+	if err := userService.DeleteUser(id, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		if services.IsClientError(err) {
+			renderUserPage(c, userService, err.Error())
+			return
+		}
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -162,7 +171,35 @@ func UserDelete(c *gin.Context) {
 }
 
 func renderUserPage(c *gin.Context, userService *services.UserService, message string) {
-	users, err := userService.GetUsers()
+	renderUserPageData(c, userService, gin.H{"Error": message, "FormModal": ""})
+}
+
+// renderUserFormError menampilkan ulang halaman user dengan modal form yang terbuka,
+// pesan error di dalam modal, dan isian form sebelumnya (kecuali password).
+func renderUserFormError(c *gin.Context, userService *services.UserService, modal string, message string) {
+	oldInput := make(map[string][]string)
+	for key, values := range c.Request.PostForm {
+		if key == "password" || key == "_csrf" {
+			continue
+		}
+		oldInput[key] = values
+	}
+
+	renderUserPageData(c, userService, gin.H{
+		"FormError": message,
+		"FormModal": modal,
+		"OldInput":  oldInput,
+	})
+}
+
+func renderUserPageData(c *gin.Context, userService *services.UserService, data gin.H) {
+	filter, err := currentStoreFilter(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	users, err := userService.GetUsers(filter)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -182,13 +219,46 @@ func renderUserPage(c *gin.Context, userService *services.UserService, message s
 		return
 	}
 
-	Render(c, "user.html", gin.H{
-		"Title":  "Daftar User",
-		"Page":   "user",
-		"users":  users,
-		"roles":  roles,
-		"stores": stores,
-		"Error":  message,
-	})
+	data["Title"] = "Daftar User"
+	data["Page"] = "user"
+	data["users"] = users
+	data["roles"] = roles
+	data["stores"] = stores
+	data["PasswordHint"] = services.LoadPasswordPolicy().Summary()
+
+	Render(c, "user.html", data)
+}
+
+
+// UserLockedIndex menampilkan daftar akun yang terkunci karena terlalu banyak login gagal.
+func UserLockedIndex(c *gin.Context) {
+	throttleSvc := &services.LoginThrottleService{Repo: &repositories.LoginThrottleRepository{DB: config.DB}}
+	renderLockedPage(c, throttleSvc, "")
+}
+
+// UserUnlock membuka kunci akun sehingga user bisa mencoba login kembali.
+func UserUnlock(c *gin.Context) {
+	throttleSvc := &services.LoginThrottleService{Repo: &repositories.LoginThrottleRepository{DB: config.DB}}
+
+	if err := throttleSvc.Unlock(c.PostForm("username")); err != nil {
+		renderLockedPage(c, throttleSvc, err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/users/locked")
 }
 
+func renderLockedPage(c *gin.Context, throttleSvc *services.LoginThrottleService, message string) {
+	accounts, err := throttleSvc.GetLockedAccounts()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Render(c, "user_locked.html", gin.H{
+		"Title":    "Akun Terkunci",
+		"Page":     "userLocked",
+		"accounts": accounts,
+		"Error":    message,
+	})
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newUserPermissionService() *services.UserPermissionService {
+	return &services.UserPermissionService{
+		Users:       &repositories.UserRepository{DB: config.DB},
+		Permissions: &repositories.PermissionRepository{DB: config.DB},
+		Roles:       &repositories.RoleRepository{DB: config.DB},
+	}
+}
+
+// UserPermissionIndex menampilkan permission efektif user beserta asalnya (role atau langsung).
+func UserPermissionIndex(c *gin.Context) {
+	renderUserPermissions(c, "", "")
+}
+
+// UserPermissionGrant memberikan permission langsung ke user.
+func UserPermissionGrant(c *gin.Context) {
+	handleUserPermission(c, (*services.UserPermissionService).Grant, "Permission berhasil diberikan.")
+}
+
+// UserPermissionRemoveGrant mencabut permission yang sebelumnya diberikan langsung.
+func UserPermissionRemoveGrant(c *gin.Context) {
+	handleUserPermission(c, (*services.UserPermissionService).RemoveGrant, "Permission langsung berhasil dicabut.")
+}
+
+// UserPermissionDeny menolak permission untuk user walaupun didapat dari role.
+func UserPermissionDeny(c *gin.Context) {
+	handleUserPermission(c, (*services.UserPermissionService).Deny, "Permission berhasil ditolak untuk user ini.")
+}
+
+// UserPermissionRemoveDeny menghapus penolakan sehingga user kembali mengikuti role-nya.
+func UserPermissionRemoveDeny(c *gin.Context) {
+	handleUserPermission(c, (*services.UserPermissionService).RemoveDeny, "Penolakan permission berhasil dihapus.")
+}
+
+// UserPermissionStoreGrant memberikan permission ke user khusus untuk satu store.
+func UserPermissionStoreGrant(c *gin.Context) {
+	handleUserStorePermission(c, (*services.UserPermissionService).GrantStore, "Permission per store berhasil diberikan.")
+}
+
+// UserPermissionStoreRevoke mencabut permission per store milik user.
+func UserPermissionStoreRevoke(c *gin.Context) {
+	handleUserStorePermission(c, (*services.UserPermissionService).RevokeStore, "Permission per store berhasil dicabut.")
+}
+
+func handleUserStorePermission(c *gin.Context, action func(*services.UserPermissionService, int, int, int64, models.AuditActor, models.Principal) error, success string) {
+	userID, ok := userSessionParam(c)
+	if !ok {
+		return
+	}
+
+	permissionID, err := strconv.ParseInt(c.PostForm("permission_id"), 10, 64)
+	if err != nil || permissionID <= 0 {
+		renderUserPermissions(c, "Permission tidak valid", "")
+		return
+	}
+	storeID, err := strconv.Atoi(c.PostForm("store_id"))
+	if err != nil || storeID <= 0 {
+		renderUserPermissions(c, "Store tidak valid", "")
+		return
+	}
+
+	if err := action(newUserPermissionService(), userID, storeID, permissionID, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		if !services.IsClientError(err) {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		renderUserPermissions(c, err.Error(), "")
+		return
+	}
+	renderUserPermissions(c, "", success)
+}
+
+func handleUserPermission(c *gin.Context, action func(*services.UserPermissionService, int, int64, models.AuditActor, models.Principal) error, success string) {
+	userID, ok := userSessionParam(c)
+	if !ok {
+		return
+	}
+
+	permissionID, err := strconv.ParseInt(c.PostForm("permission_id"), 10, 64)
+	if err != nil || permissionID <= 0 {
+		renderUserPermissions(c, "Permission tidak valid", "")
+		return
+	}
+
+	if err := action(newUserPermissionService(), userID, permissionID, auditActor(c), middleware.CurrentPrincipal(c)); err != nil {
+		if !services.IsClientError(err) {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		renderUserPermissions(c, err.Error(), "")
+		return
+	}
+	renderUserPermissions(c, "", success)
+}
+
+func renderUserPermissions(c *gin.Context, message, success string) {
+	userID, ok := userSessionParam(c)
+	if !ok {
+		return
+	}
+
+	overview, err := newUserPermissionService().Overview(userID)
+	if err != nil {
+		if services.IsClientError(err) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Render(c, "user_permissions.html", gin.H{
+		"Title":    "Permission User",
+		"Page":     "userPermissions",
+		"Overview": overview,
+		"Error":    message,
+		"Success":  success,
+	})
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strconv"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// UserSessionIndex menampilkan riwayat login dan session aktif milik user lain (admin).
+func UserSessionIndex(c *gin.Context) {
+	renderUserSessions(c, "", "")
+}
+
+// UserSessionRevoke mengakhiri salah satu session milik user lain.
+func UserSessionRevoke(c *gin.Context) {
+	userID, ok := userSessionParam(c)
+	if !ok || !guardUserSessions(c, userID) {
+		return
+	}
+
+	// session admin sendiri tetap dilindungi jika admin membuka halaman miliknya
+	current := sessions.Default(c).ID()
+	if err := newAuthEventService().EndSession(userID, c.PostForm("session"), current); err != nil {
+		renderUserSessions(c, err.Error(), "")
+		return
+	}
+	renderUserSessions(c, "", "Session berhasil diakhiri.")
+}
+
+// UserSessionRevokeOthers mengakhiri seluruh session milik user lain (kecuali session admin yang sedang dipakai).
+func UserSessionRevokeOthers(c *gin.Context) {
+	userID, ok := userSessionParam(c)
+	if !ok || !guardUserSessions(c, userID) {
+		return
+	}
+
+	count, err := newAuthEventService().EndOtherSessions(userID, sessions.Default(c).ID())
+	if err != nil {
+		renderUserSessions(c, "Gagal mengakhiri session: "+err.Error(), "")
+		return
+	}
+	renderUserSessions(c, "", fmt.Sprintf("%d session berhasil diakhiri.", count))
+}
+
+// guardUserSessions menghentikan request dengan 403 jika user bukan admin tetapi mencoba mengakhiri
+// session milik user admin.
+func guardUserSessions(c *gin.Context, userID int) bool {
+	err := newAuthEventService().GuardUserSessions(userID, middleware.CurrentPrincipal(c))
+	if errors.Is(err, services.ErrForbidden) {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"code_error": 3,
+			"error":      err.Error(),
+		})
+		return false
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return false
+	}
+	return true
+}
+
+func renderUserSessions(c *gin.Context, message, success string) {
+	userID, ok := userSessionParam(c)
+	if !ok {
+		return
+	}
+
+	userRepo := &repositories.UserRepository{DB: config.DB}
+	subject, err := userRepo.GetPasswordOwner(userID)
+	if err == sql.ErrNoRows {
+		c.String(http.StatusNotFound, "user tidak ditemukan")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session := sessions.Default(c)
+	renderSessionPage(c, sessionPage{
+		Page:      "userSessions",
+		Title:     "Session User",
+		UserID:    subject.ID,
+		Username:  subject.Username,
+		Name:      subject.Name,
+		CurrentID: session.ID(),
+		ActionURL: "/users/" + strconv.Itoa(userID) + "/sessions",
+		Self:      subject.ID == sessionUserID(session),
+	}, message, success)
+}
+
+// userSessionParam membaca :id dari URL; request dihentikan dengan 400 jika tidak valid dan 404 jika
+// user tersebut berada di luar store milik user yang sedang login.
+func userSessionParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return 0, false
+	}
+
+	inScope, err := userInStoreScope(c, id)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return 0, false
+	}
+	if !inScope {
+		c.String(http.StatusNotFound, "user tidak ditemukan")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"gobase-app/config"
+	"gobase-app/middleware"
+	"gobase-app/models"
+	"gobase-app/services"
+	"gobase-app/sessionstore"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func TestUserSessionRevokeAdminRequiresAdmin(t *testing.T) {
+	const (
+		adminTarget = 7
+		actorID     = 61
+	)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "akhiri satu session", path: "/users/7/sessions/revoke"},
+		{name: "akhiri seluruh session", path: "/users/7/sessions/revoke-others"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			prevDB := config.DB
+			config.DB = db
+			t.Cleanup(func() {
+				config.DB = prevDB
+				db.Close()
+			})
+
+			backend := sessionstore.NewMemoryBackend()
+			sessionstore.SetDefault(backend)
+			t.Cleanup(func() { sessionstore.SetDefault(nil) })
+			backend.Save(models.Session{ID: "admin-laptop", UserID: adminTarget, ExpiresAt: time.Now().Add(time.Hour)})
+
+			// actor dan admin sama-sama ditugaskan di store 3
+			mock.ExpectQuery(`SELECT TRUE FROM users WHERE id = \?`).WithArgs(adminTarget).
+				WillReturnRows(sqlmock.NewRows([]string{"true"}).AddRow(true))
+			mock.ExpectQuery(`SELECT store_id FROM user_stores WHERE user_id = \?`).
+				WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(3))
+			for _, check := range []struct {
+				userID  int
+				isAdmin int
+			}{{adminTarget, 1}, {actorID, 0}} {
+				mock.ExpectQuery(`SELECT COUNT\(1\)\s+FROM model_has_roles mhr\s+JOIN roles r`).
+					WithArgs(check.userID, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(check.isAdmin))
+			}
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.SetHTMLTemplate(template.Must(template.New("error.html").Parse(`{{ .error }}`)))
+			r.Use(sessions.Sessions("mysession", sessionstore.NewStore(sessionstore.NewMemoryBackend(), []byte("0123456789abcdef0123456789abcdef"))))
+			r.Use(func(c *gin.Context) {
+				sessions.Default(c).Set("user", models.SessionUser{UserID: actorID, Username: "operator"})
+				c.Set(middleware.StoreScopeKey, &services.StoreScope{StoreIDs: []int{3}})
+			})
+			r.POST("/users/:id/sessions/revoke", UserSessionRevoke)
+			r.POST("/users/:id/sessions/revoke-others", UserSessionRevokeOthers)
+
+			form := url.Values{"session": {sessionstore.SessionKey("admin-laptop")}}
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, http.StatusForbidden, w.Body)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+			if remaining, _ := backend.FindByUserID(adminTarget); len(remaining) != 1 {
+				t.Errorf("%d session admin aktif, want 1 (tidak boleh diakhiri non-admin)", len(remaining))
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret adalah secret SHA-1 dari lampiran B RFC 6238 ("12345678901234567890") dalam base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// kode 8 digit di RFC dipotong menjadi 6 digit terakhir
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTPWindowEdges(t *testing.T) {
+	// kode untuk time-step 40 berlaku pada detik 1200-1229
+	const step = 40
+	code, err := TOTPCode(rfc6238Secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(sec int64) time.Time { return time.Unix(step*30+sec, 0) }
+
+	tests := []struct {
+		name string
+		now  time.Time
+		skew int64
+		code string
+		ok   bool
+	}{
+		{name: "awal step", now: at(0), skew: 1, code: code, ok: true},
+		{name: "akhir step", now: at(29), skew: 1, code: code, ok: true},
+		{name: "awal step berikutnya masih dalam skew", now: at(30), skew: 1, code: code, ok: true},
+		{name: "akhir step berikutnya masih dalam skew", now: at(59), skew: 1, code: code, ok: true},
+		{name: "dua step sesudahnya ditolak", now: at(60), skew: 1, code: code, ok: false},
+		{name: "akhir step sebelumnya masih dalam skew", now: at(-1), skew: 1, code: code, ok: true},
+		{name: "awal step sebelumnya masih dalam skew", now: at(-30), skew: 1, code: code, ok: true},
+		{name: "dua step sebelumnya ditolak", now: at(-31), skew: 1, code: code, ok: false},
+		{name: "tanpa skew step berikutnya ditolak", now: at(30), skew: 0, code: code, ok: false},
+		{name: "spasi di tengah kode diabaikan", now: at(10), skew: 1, code: code[:3] + " " + code[3:], ok: true},
+		{name: "kode terlalu pendek", now: at(10), skew: 1, code: code[:5], ok: false},
+		{name: "kode salah", now: at(10), skew: 1, code: "000000", ok: code == "000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ValidateTOTP(rfc6238Secret, tt.code, tt.now, tt.skew)
+			if ok != tt.ok {
+				t.Fatalf("ValidateTOTP ok = %v, want %v", ok, tt.ok)
+			}
+			// time-step yang dikembalikan adalah step kode, bukan step sekarang, agar replay tercegah
+			if ok && got != step {
+				t.Errorf("step = %d, want %d", got, step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPInvalidSecret(t *testing.T) {
+	if _, ok := ValidateTOTP("bukan-base32!", "123456", time.Now(), 1); ok {
+		t.Error("secret tidak valid diterima")
+	}
+}
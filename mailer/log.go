@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogMailer tidak mengirim email sungguhan. Email ditulis sebagai file .eml di Dir,
+// atau dicetak ke log aplikasi jika Dir kosong. Dipakai untuk development lokal.
+type LogMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	from := m.From
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	raw := buildMessage(from, msg)
+
+	if m.Dir == "" {
+		log.Printf("[mailer] email to %s:\n%s", msg.To, raw)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), filepath.Base(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), raw, 0o600)
+}
@@ -0,0 +1,59 @@
+// Package mailer menyediakan interface pengiriman email beserta implementasi SMTP
+// dan implementasi file/log untuk development lokal.
+package mailer
+
+import (
+	"os"
+	"strings"
+)
+
+// Message adalah email plain text yang akan dikirim.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var defaultMailer Mailer = &LogMailer{}
+
+// SetDefault mengganti mailer yang dipakai aplikasi.
+func SetDefault(m Mailer) {
+	if m != nil {
+		defaultMailer = m
+	}
+}
+
+// Default mengembalikan mailer yang sedang aktif.
+func Default() Mailer {
+	return defaultMailer
+}
+
+// FromEnv membuat mailer berdasarkan MAIL_DRIVER: "smtp" atau "log" (default).
+//
+// SMTP memakai MAIL_HOST, MAIL_PORT, MAIL_USERNAME, MAIL_PASSWORD, dan MAIL_FROM.
+// Driver log menulis email ke folder MAIL_LOG_DIR (jika diisi) atau ke log aplikasi.
+func FromEnv() Mailer {
+	from := os.Getenv("MAIL_FROM")
+
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_DRIVER"))) {
+	case "smtp":
+		port := os.Getenv("MAIL_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return &SMTPMailer{
+			Host:     os.Getenv("MAIL_HOST"),
+			Port:     port,
+			Username: os.Getenv("MAIL_USERNAME"),
+			Password: os.Getenv("MAIL_PASSWORD"),
+			From:     from,
+		}
+	default:
+		return &LogMailer{Dir: os.Getenv("MAIL_LOG_DIR"), From: from}
+	}
+}
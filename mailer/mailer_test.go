@@ -0,0 +1,88 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildMessage(t *testing.T) {
+	raw := string(buildMessage("no-reply@kampus.local", Message{
+		To:      "alice@kampus.local",
+		Subject: "Reset password",
+		Body:    "Halo Alice,\n\nBuka link berikut.\n",
+	}))
+
+	header, body, ok := strings.Cut(raw, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("message tanpa pemisah header dan body:\n%q", raw)
+	}
+	for _, want := range []string{
+		"From: no-reply@kampus.local",
+		"To: alice@kampus.local",
+		"Subject: Reset password",
+		"Content-Type: text/plain; charset=UTF-8",
+	} {
+		if !strings.Contains(header+"\r\n", want+"\r\n") {
+			t.Errorf("header tidak memuat %q:\n%s", want, header)
+		}
+	}
+	if body != "Halo Alice,\r\n\r\nBuka link berikut.\r\n" {
+		t.Errorf("body = %q, want baris diakhiri CRLF", body)
+	}
+}
+
+func TestLogMailerWritesEML(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := &LogMailer{Dir: dir}
+	if err := m.Send(Message{To: "alice@kampus.local", Subject: "Tes", Body: "isi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), "-alice@kampus.local.eml") {
+		t.Fatalf("file = %v, want satu file .eml untuk penerima", entries)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "From: no-reply@localhost\r\n") {
+		t.Errorf("email tanpa MAIL_FROM harus memakai pengirim default:\n%s", raw)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("MAIL_FROM", "no-reply@kampus.local")
+	t.Setenv("MAIL_HOST", "smtp.kampus.local")
+	t.Setenv("MAIL_PORT", "")
+
+	t.Setenv("MAIL_DRIVER", " SMTP ")
+	smtpMailer, ok := FromEnv().(*SMTPMailer)
+	if !ok {
+		t.Fatalf("MAIL_DRIVER=smtp menghasilkan %T, want *SMTPMailer", FromEnv())
+	}
+	if smtpMailer.Host != "smtp.kampus.local" || smtpMailer.Port != "587" || smtpMailer.From != "no-reply@kampus.local" {
+		t.Errorf("SMTPMailer = %+v, want host dari env dan port default 587", smtpMailer)
+	}
+
+	t.Setenv("MAIL_DRIVER", "")
+	t.Setenv("MAIL_LOG_DIR", "/tmp/mail")
+	logMailer, ok := FromEnv().(*LogMailer)
+	if !ok || logMailer.Dir != "/tmp/mail" {
+		t.Errorf("FromEnv() = %#v, want LogMailer ke MAIL_LOG_DIR", FromEnv())
+	}
+}
+
+func TestSMTPMailerRequiresHostAndFrom(t *testing.T) {
+	if err := (&SMTPMailer{Port: "587", From: "no-reply@kampus.local"}).Send(Message{To: "alice@kampus.local"}); err == nil {
+		t.Error("Send tanpa MAIL_HOST tidak mengembalikan error")
+	}
+	if err := (&SMTPMailer{Host: "smtp.kampus.local", Port: "587"}).Send(Message{To: "alice@kampus.local"}); err == nil {
+		t.Error("Send tanpa MAIL_FROM tidak mengembalikan error")
+	}
+}
@@ -8,12 +8,20 @@ import (
 	"net/http"
 	"os"
 	"gobase-app/config"
+	"gobase-app/controllers"
+	"gobase-app/mailer"
+	"gobase-app/middleware"
+	"gobase-app/migrations"
 	"gobase-app/models"
+	"gobase-app/oidc"
+	"gobase-app/repositories"
 	"gobase-app/routes"
+	"gobase-app/services"
+	"gobase-app/sessionstore"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -27,6 +35,44 @@ func main() {
 	// Initialize database / config
 	config.Connect()
 
+	// Subcommand CLI (mis. `gobase-app migrate status`) dijalankan lalu keluar tanpa start server
+	if len(os.Args) > 1 {
+		handled, err := runCommand(os.Args[1], os.Args[2:])
+		if err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		if !handled {
+			log.Fatalf("unknown command %q", os.Args[1])
+		}
+		return
+	}
+
+	// Jalankan migrasi schema yang belum tercatat (DB_AUTO_MIGRATE=false untuk hanya lewat `migrate up`)
+	if config.EnvBoolDefault("DB_AUTO_MIGRATE", true) {
+		if _, err := migrations.Up(config.DB, log.Printf); err != nil {
+			log.Fatalf("failed to run migrations: %v", err)
+		}
+	}
+
+	// Samakan tabel permissions dengan permission yang dideklarasikan di routes/permissions.go
+	permissionSvc := &services.PermissionService{Repo: &repositories.PermissionRepository{DB: config.DB}}
+	created, orphans, err := permissionSvc.Sync(models.AuditActor{Username: "system"})
+	if err != nil {
+		log.Fatalf("failed to sync permissions: %v", err)
+	}
+	if len(created) > 0 {
+		log.Printf("permissions created: %s", strings.Join(created, ", "))
+	}
+	if len(orphans) > 0 {
+		log.Printf("permissions not declared in code (orphan, see /permissions): %s", strings.Join(orphans, ", "))
+	}
+
+	// Mailer (MAIL_DRIVER=smtp|log) untuk email reset password dan notifikasi lain
+	mailer.SetDefault(mailer.FromEnv())
+
+	// Login SSO OpenID Connect, aktif jika OIDC_ISSUER_URL dan OIDC_CLIENT_ID diisi
+	oidc.SetDefault(oidc.FromEnv())
+
 	// Initialize Gin engine // menampilkan logger di terminal
 	// r := gin.Default()
 
@@ -37,6 +83,12 @@ func main() {
 	r := gin.New()
 	r.Use(gin.Recovery())
 
+	// Hanya percaya X-Forwarded-For dari proxy di TRUSTED_PROXIES agar IP client (dipakai
+	// untuk pembatasan login) tidak bisa dipalsukan lewat header.
+	if err := r.SetTrustedProxies(config.EnvList("TRUSTED_PROXIES")); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Custom template functions tambah
 	r.SetFuncMap(template.FuncMap{
 		"no": func(a, b int) int {
@@ -52,14 +104,37 @@ func main() {
 	// Templates & static files
 	r.LoadHTMLGlob("templates/**/*")
 	r.Static("/assets", "./assets")
+	r.Static(config.UploadURLPrefix, config.UploadDir())
 
 	useSecureCookie := strings.ToLower(os.Getenv("APP_SECURE_COOKIE")) == "true"
 
-	// Register custom session payload for gob encoder used by cookie store.
+	// Register custom session payload for gob encoder used by session store.
 	gob.Register(models.SessionUser{})
 
+	sessionKeys, err := config.SessionKeyPairs()
+	if err != nil {
+		log.Fatalf("invalid session configuration: %v", err)
+	}
+
+	// Session server-side: cookie hanya berisi ID session, data disimpan di backend
+	// (SESSION_DRIVER=memory untuk test/development, default database).
+	var sessionBackend sessionstore.Backend
+	if strings.ToLower(os.Getenv("SESSION_DRIVER")) == "memory" {
+		sessionBackend = sessionstore.NewMemoryBackend()
+	} else {
+		sessionBackend = &repositories.SessionRepository{DB: config.DB}
+	}
+	sessionstore.SetDefault(sessionBackend)
+	authEvents := &services.AuthEventService{Repo: &repositories.AuthEventRepository{DB: config.DB}}
+	sessionstore.StartCleanup(sessionBackend, 15*time.Minute, log.Printf, func(s models.Session) {
+		if err := authEvents.RecordSessionEnded(models.AuthEventSessionExpired, s, s.ExpiresAt); err != nil {
+			log.Printf("failed to record session expiry: %v", err)
+		}
+	})
+
 	// SESSION - must be registered BEFORE routes that use sessions
-	store := cookie.NewStore([]byte("secret-key"))
+	store := sessionstore.NewStore(sessionBackend, sessionKeys...)
+	store.AnonymousTTL(time.Duration(config.EnvInt("SESSION_ANONYMOUS_TTL_MINUTES", 60)) * time.Minute)
 	store.Options(sessions.Options{
 		Path:     "/",
 		MaxAge:   60 * 60 * 8, // 8 jam
@@ -68,13 +143,18 @@ func main() {
 		Secure:   useSecureCookie,
 		SameSite: http.SameSiteLaxMode,
 	})
-	r.Use(sessions.Sessions("mysession", store))
+	r.Use(sessionstore.ResolveClientIP(), sessions.Sessions("mysession", store))
 
 	// Register application routes
 	routes.RegisterWebRoutes(r)
+	routes.RegisterAPIRoutes(r)
 
 	// Render custom 404 page
 	r.NoRoute(func(c *gin.Context) {
+		if middleware.IsAPIRequest(c) {
+			controllers.APINotFound(c)
+			return
+		}
 		c.HTML(http.StatusNotFound, "error.html", gin.H{
 			"code_error": http.StatusNotFound,
 			"error":      "Page not found",
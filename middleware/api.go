@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIPrefix adalah prefix path REST API. Request di bawah prefix ini selalu dibalas JSON,
+// termasuk ketika ditolak oleh middleware auth, permission, atau CSRF.
+const APIPrefix = "/api/"
+
+// APIError adalah isi field "error" pada envelope error API.
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// IsAPIRequest mengecek apakah request ditujukan ke REST API.
+func IsAPIRequest(c *gin.Context) bool {
+	return strings.HasPrefix(c.Request.URL.Path, APIPrefix)
+}
+
+// AbortAPIError menghentikan request dengan envelope error standar: {"error": {"code", "message"}}.
+func AbortAPIError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, gin.H{"error": APIError{Code: code, Message: message}})
+}
@@ -3,7 +3,9 @@ package middleware
 import (
 	"net/http"
 	"gobase-app/models"
+	"gobase-app/permissions"
 	"gobase-app/services"
+	"strings"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -11,10 +13,19 @@ import (
 
 func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if _, ok := CurrentTokenPrincipal(c); ok {
+			c.Next()
+			return
+		}
+
 		session := sessions.Default(c)
 		user := session.Get("user")
 
 		if user == nil {
+			if IsAPIRequest(c) {
+				AbortAPIError(c, http.StatusUnauthorized, "unauthenticated", "Login diperlukan")
+				return
+			}
 			c.Redirect(http.StatusFound, "/login")
 			c.Abort()
 			return
@@ -62,17 +73,39 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 }
 
 func RequirePermission(perm string) gin.HandlerFunc {
+	return RequireAnyPermission(perm)
+}
+
+// RequireAnyPermission meloloskan request jika user memiliki minimal satu dari permission yang disebut.
+// Permission yang belum dideklarasikan di registry membuat panic saat route didaftarkan.
+func RequireAnyPermission(perms ...string) gin.HandlerFunc {
+	permissions.MustRegistered(perms...)
+
 	return func(c *gin.Context) {
-		sess := sessions.Default(c)
-		userID := extractUserID(sess)
+		userID := CurrentUserID(c)
 
 		if userID == 0 {
+			if IsAPIRequest(c) {
+				AbortAPIError(c, http.StatusUnauthorized, "unauthenticated", "Login diperlukan")
+				return
+			}
 			c.AbortWithStatus(401)
 			return
 		}
 
-		ok, err := services.UserHasPermission(userID, perm)
+		owned, err := CurrentPermissions(c)
+		ok := false
+		for _, perm := range perms {
+			if owned[perm] {
+				ok = true
+				break
+			}
+		}
 		if err != nil || !ok {
+			if IsAPIRequest(c) {
+				AbortAPIError(c, http.StatusForbidden, "forbidden", "Tidak punya permission "+strings.Join(perms, " atau "))
+				return
+			}
 			c.HTML(403, "error.html", gin.H{
 				// "error": "Tidak punya Aksess di Halaman ini: " + perm,
 				"code_error": 3,
@@ -136,21 +169,48 @@ func normalizeID(val interface{}) int {
 	}
 }
 
-func PermissionContext() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		sess := sessions.Default(c)
-		userID := extractUserID(sess)
+// PermissionsKey adalah key context berisi map permission user request saat ini (dipakai juga di render).
+const PermissionsKey = "Permissions"
 
-		if userID > 0 {
-			perms, err := services.GetUserPermissions(userID)
-			if err == nil {
-				c.Set("Permissions", perms) // simpan di context (dipakai di render)
-			}
+// CurrentPermissions mengambil permission user request saat ini, sudah dibatasi ability token jika
+// request memakai token API. Hasilnya di-memo di context sehingga PermissionContext dan semua
+// RequirePermission dalam satu request hanya memuatnya sekali.
+func CurrentPermissions(c *gin.Context) (map[string]bool, error) {
+	if v, ok := c.Get(PermissionsKey); ok {
+		if perms, ok := v.(map[string]bool); ok {
+			return perms, nil
 		}
+	}
 
-		c.Next()
+	userID := CurrentUserID(c)
+	if userID == 0 {
+		return map[string]bool{}, nil
 	}
-}
 
+	perms, err := services.GetUserPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+	// token hanya boleh memakai permission yang dipilih saat token dibuat
+	if principal, isToken := CurrentTokenPrincipal(c); isToken {
+		for name := range perms {
+			if !principal.Abilities[name] {
+				delete(perms, name)
+			}
+		}
+	}
 
+	c.Set(PermissionsKey, perms)
+	return perms, nil
+}
+
+// PermissionContext memuat permission user lebih awal agar tersedia untuk template lewat Render.
+func PermissionContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentUserID(c) > 0 {
+			CurrentPermissions(c)
+		}
 
+		c.Next()
+	}
+}
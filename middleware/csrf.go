@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	csrfSessionKey = "csrf_token"
+	csrfFormField  = "_csrf"
+	csrfHeader     = "X-CSRF-Token"
+
+	// CSRFContextKey adalah key gin.Context tempat token CSRF untuk template disimpan.
+	CSRFContextKey = "CSRFToken"
+)
+
+// CSRF memastikan setiap session punya token CSRF dan memvalidasi token tersebut
+// pada method yang mengubah data (POST, PUT, PATCH, DELETE).
+// Token dikirim lewat field form "_csrf" atau header "X-CSRF-Token".
+func CSRF() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Bearer token tidak dikirim otomatis oleh browser sehingga tidak rentan CSRF
+		if _, ok := CurrentTokenPrincipal(c); ok {
+			c.Next()
+			return
+		}
+
+		session := sessions.Default(c)
+
+		token, _ := session.Get(csrfSessionKey).(string)
+		if token == "" {
+			token = generateCSRFToken()
+			session.Set(csrfSessionKey, token)
+			if err := session.Save(); err != nil {
+				c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+					"code_error": http.StatusInternalServerError,
+					"error":      "Gagal menyiapkan sesi",
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(CSRFContextKey, token)
+		// klien API yang memakai cookie session membaca token dari header respons
+		if IsAPIRequest(c) {
+			c.Header(csrfHeader, token)
+		}
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		sent := c.GetHeader(csrfHeader)
+		if sent == "" {
+			sent = c.PostForm(csrfFormField)
+		}
+
+		if sent == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			if IsAPIRequest(c) {
+				AbortAPIError(c, http.StatusForbidden, "csrf_token_invalid", "Header X-CSRF-Token tidak valid")
+				return
+			}
+			c.HTML(http.StatusForbidden, "error.html", gin.H{
+				"code_error": http.StatusForbidden,
+				"error":      "Token keamanan form tidak valid atau sudah kedaluwarsa. Muat ulang halaman lalu coba lagi.",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func generateCSRFToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
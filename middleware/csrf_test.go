@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"gobase-app/models"
+	"gobase-app/sessionstore"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func newCSRFTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.SetHTMLTemplate(template.Must(template.New("error.html").Parse(`{{.error}}`)))
+	store := sessionstore.NewStore(sessionstore.NewMemoryBackend(), []byte("0123456789abcdef0123456789abcdef"))
+	r.Use(sessions.Sessions("mysession", store))
+	r.Use(func(c *gin.Context) {
+		// meniru BearerToken untuk request API yang membawa token
+		if c.GetHeader("Authorization") == "Bearer gbp_test" {
+			c.Set(TokenPrincipalKey, &models.TokenPrincipal{UserID: 5})
+		}
+		c.Next()
+	})
+	r.Use(CSRF())
+
+	ok := func(c *gin.Context) { c.String(http.StatusOK, c.GetString(CSRFContextKey)) }
+	r.GET("/form", ok)
+	r.POST("/form", ok)
+	r.GET("/api/items", ok)
+	r.POST("/api/items", ok)
+	return r
+}
+
+// csrfSession membuka halaman form dan mengembalikan cookie session beserta token CSRF-nya.
+func csrfSession(t *testing.T, r *gin.Engine) ([]*http.Cookie, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+	if w.Code != http.StatusOK || w.Body.String() == "" {
+		t.Fatalf("GET /form = %d %q, want token CSRF", w.Code, w.Body.String())
+	}
+	return w.Result().Cookies(), w.Body.String()
+}
+
+func TestCSRF(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		form       func(token string) url.Values
+		header     func(token string) http.Header
+		noSession  bool
+		wantStatus int
+	}{
+		{
+			name:       "token di form",
+			path:       "/form",
+			form:       func(token string) url.Values { return url.Values{"_csrf": {token}} },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "token di header",
+			path:       "/form",
+			header:     func(token string) http.Header { return http.Header{"X-Csrf-Token": {token}} },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "tanpa token",
+			path:       "/form",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "token salah",
+			path:       "/form",
+			form:       func(string) url.Values { return url.Values{"_csrf": {"token-lain"}} },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "token milik session lain",
+			path:       "/form",
+			form:       func(token string) url.Values { return url.Values{"_csrf": {token}} },
+			noSession:  true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "API dengan cookie session tanpa header",
+			path:       "/api/items",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "API dengan Bearer token tidak memerlukan token CSRF",
+			path:       "/api/items",
+			header:     func(string) http.Header { return http.Header{"Authorization": {"Bearer gbp_test"}} },
+			noSession:  true,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newCSRFTestRouter()
+			cookies, token := csrfSession(t, r)
+
+			body := ""
+			if tt.form != nil {
+				body = tt.form(token).Encode()
+			}
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.header != nil {
+				for key, values := range tt.header(token) {
+					req.Header[key] = values
+				}
+			}
+			if !tt.noSession {
+				for _, cookie := range cookies {
+					req.AddCookie(cookie)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("POST %s = %d, want %d: %s", tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code == http.StatusForbidden && strings.HasPrefix(tt.path, APIPrefix) &&
+				!strings.Contains(w.Body.String(), "csrf_token_invalid") {
+				t.Errorf("body = %s, want envelope error API", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCSRFTokenStableWithinSession(t *testing.T) {
+	r := newCSRFTestRouter()
+	cookies, token := csrfSession(t, r)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("X-CSRF-Token"); got != token {
+		t.Errorf("header X-CSRF-Token = %q, want token session %q", got, token)
+	}
+	if _, other := csrfSession(t, r); other == token {
+		t.Error("session baru mendapat token CSRF yang sama")
+	}
+}
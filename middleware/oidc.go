@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"gobase-app/oidc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCEnabled menyembunyikan route login SSO (404) jika OIDC_ISSUER_URL belum dikonfigurasi.
+func OIDCEnabled() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if oidc.Default() == nil {
+			c.HTML(http.StatusNotFound, "error.html", gin.H{
+				"code_error": http.StatusNotFound,
+				"error":      "Halaman tidak ditemukan",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
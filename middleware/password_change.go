@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/repositories"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordChangeKey menandai session user yang wajib mengganti password sebelum memakai aplikasi.
+const PasswordChangeKey = "password_change_required"
+
+// PasswordChangeRequired mengarahkan user yang ditandai admin ke halaman ganti password.
+// Halaman 2FA tetap dibuka agar tidak bentrok dengan TwoFactorEnrollment.
+func PasswordChangeRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		required, err := passwordChangeRequired(c)
+		if err != nil {
+			log.Printf("password change check failed: %v", err)
+			AbortAPIError(c, http.StatusInternalServerError, "internal_error", "Terjadi kesalahan pada server")
+			return
+		}
+
+		path := c.Request.URL.Path
+		if required && !strings.HasPrefix(path, "/profile/password") && !strings.HasPrefix(path, "/profile/2fa") {
+			if IsAPIRequest(c) {
+				AbortAPIError(c, http.StatusForbidden, "password_change_required", "Ganti password di /profile/password terlebih dahulu")
+				return
+			}
+			c.Redirect(http.StatusFound, "/profile/password")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// passwordChangeRequired membaca tanda wajib ganti password dari session. Request Bearer token
+// tidak punya session, jadi tanda tersebut dibaca langsung dari data user.
+func passwordChangeRequired(c *gin.Context) (bool, error) {
+	principal, ok := CurrentTokenPrincipal(c)
+	if !ok {
+		required, _ := sessions.Default(c).Get(PasswordChangeKey).(bool)
+		return required, nil
+	}
+	return (&repositories.UserRepository{DB: config.DB}).MustChangePassword(principal.UserID)
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"gobase-app/config"
+	"gobase-app/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestPasswordChangeRequiredChecksTokenUser(t *testing.T) {
+	const userID = 41
+
+	tests := []struct {
+		name       string
+		mustChange bool
+		wantStatus int
+	}{
+		{name: "wajib ganti password", mustChange: true, wantStatus: http.StatusForbidden},
+		{name: "tidak wajib ganti password", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			previous := config.DB
+			config.DB = db
+			t.Cleanup(func() {
+				config.DB = previous
+				db.Close()
+			})
+			mock.ExpectQuery(`SELECT must_change_password FROM users WHERE id = \?`).WithArgs(userID).
+				WillReturnRows(sqlmock.NewRows([]string{"must_change_password"}).AddRow(tt.mustChange))
+
+			// request token tidak membawa session, jadi tidak ada tanda wajib ganti password di session
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(func(c *gin.Context) { c.Set(TokenPrincipalKey, &models.TokenPrincipal{UserID: userID}) })
+			r.GET("/api/v1/users", PasswordChangeRequired(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"gobase-app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegistrationEnabled menyembunyikan route registrasi (404) jika REGISTRATION_ENABLED tidak aktif.
+func RegistrationEnabled() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !services.RegistrationEnabled() {
+			c.HTML(http.StatusNotFound, "error.html", gin.H{
+				"code_error": http.StatusNotFound,
+				"error":      "Halaman tidak ditemukan",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
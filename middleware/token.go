@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// TokenPrincipalKey adalah key gin.Context tempat principal Bearer token disimpan.
+const TokenPrincipalKey = "TokenPrincipal"
+
+// BearerToken mengautentikasi request API yang membawa header "Authorization: Bearer <token>".
+// Request dengan token tidak valid langsung ditolak 401; request tanpa header diteruskan
+// sehingga tetap bisa memakai cookie session.
+func BearerToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerCredentials(c.GetHeader("Authorization"))
+		if !IsAPIRequest(c) || !ok {
+			c.Next()
+			return
+		}
+
+		tokenSvc := &services.PersonalAccessTokenService{Repo: &repositories.PersonalAccessTokenRepository{DB: config.DB}}
+		principal, err := tokenSvc.Authenticate(token)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAccessToken) {
+				AbortAPIError(c, http.StatusUnauthorized, "invalid_token", err.Error())
+				return
+			}
+			log.Printf("bearer token authentication failed: %v", err)
+			AbortAPIError(c, http.StatusInternalServerError, "internal_error", "Terjadi kesalahan pada server")
+			return
+		}
+
+		c.Set(TokenPrincipalKey, principal)
+		c.Next()
+	}
+}
+
+// bearerCredentials mengambil token dari header Authorization dengan skema Bearer. Nama skema
+// tidak membedakan huruf besar/kecil (RFC 6750 / RFC 7235), jadi "bearer" dan "BEARER" juga diterima.
+func bearerCredentials(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return token, true
+}
+
+// CurrentTokenPrincipal mengembalikan principal Bearer token jika request diautentikasi dengan token.
+func CurrentTokenPrincipal(c *gin.Context) (*models.TokenPrincipal, bool) {
+	v, ok := c.Get(TokenPrincipalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := v.(*models.TokenPrincipal)
+	return principal, ok && principal != nil
+}
+
+// CurrentUserID mengambil id user dari Bearer token atau, jika tidak ada, dari session.
+func CurrentUserID(c *gin.Context) int {
+	if principal, ok := CurrentTokenPrincipal(c); ok {
+		return principal.UserID
+	}
+	return extractUserID(sessions.Default(c))
+}
+
+// CurrentPrincipal menyusun principal otorisasi request saat ini: user dari Bearer token beserta
+// ability-nya atau, jika tidak ada, user dari session tanpa batasan ability.
+func CurrentPrincipal(c *gin.Context) models.Principal {
+	if principal, ok := CurrentTokenPrincipal(c); ok {
+		return models.Principal{UserID: principal.UserID, Token: true, Abilities: principal.Abilities}
+	}
+	return models.Principal{UserID: extractUserID(sessions.Default(c))}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/services"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTokenLimitsPermissionsToAbilities(t *testing.T) {
+	const userID = 41
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	previous := config.DB
+	config.DB = db
+	services.InvalidateUserPermissions(userID)
+	t.Cleanup(func() {
+		config.DB = previous
+		services.InvalidateUserPermissions(userID)
+	})
+
+	mock.ExpectQuery(`SELECT role_id\s+FROM model_has_roles`).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id"}))
+	mock.ExpectQuery(`SELECT p.name\s+FROM permissions p`).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("items.view").AddRow("stock.view").AddRow("users.delete"))
+	mock.ExpectQuery(`SELECT COUNT\(1\)\s+FROM model_has_roles mhr`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT TRUE FROM users WHERE id = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT store_id FROM user_stores`).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery(`FROM model_has_store_permissions mhsp`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "store_id"}).AddRow("stock.issue", 2).AddRow("stock.receive", 2))
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/items", nil)
+	c.Set(TokenPrincipalKey, &models.TokenPrincipal{
+		UserID:    userID,
+		Abilities: map[string]bool{"items.view": true, "stock.issue": true, "roles.delete": true},
+	})
+
+	perms, err := CurrentPermissions(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"items.view": true}; !reflect.DeepEqual(perms, want) {
+		t.Errorf("permission token = %v, want %v", perms, want)
+	}
+
+	scope, err := CurrentStoreScope(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grants []string
+	for name := range scope.StoreGrants {
+		grants = append(grants, name)
+	}
+	sort.Strings(grants)
+	if !reflect.DeepEqual(grants, []string{"stock.issue"}) {
+		t.Errorf("permission per store token = %v, want [stock.issue]", grants)
+	}
+	if !scope.Can("stock.issue", 2) || scope.Can("stock.receive", 2) || scope.Can("stock.view", 1) {
+		t.Error("StoreScope token memakai permission di luar ability")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// permission user di cache tidak ikut terpotong oleh ability token
+	all, err := services.GetUserPermissions(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("cache permission user = %v, want tetap 3 permission", all)
+	}
+}
+
+func TestBearerCredentials(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantToken string
+		wantOK    bool
+	}{
+		{header: "Bearer gbp_abc", wantToken: "gbp_abc", wantOK: true},
+		{header: "bearer gbp_abc", wantToken: "gbp_abc", wantOK: true},
+		{header: "BEARER gbp_abc", wantToken: "gbp_abc", wantOK: true},
+		{header: "Basic YWxpY2U6cmFoYXNpYQ=="},
+		{header: "Bearer"},
+		{header: ""},
+	}
+
+	for _, tt := range tests {
+		token, ok := bearerCredentials(tt.header)
+		if token != tt.wantToken || ok != tt.wantOK {
+			t.Errorf("bearerCredentials(%q) = %q, %v; want %q, %v", tt.header, token, ok, tt.wantToken, tt.wantOK)
+		}
+	}
+}
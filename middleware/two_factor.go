@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"gobase-app/config"
+	"gobase-app/repositories"
+	"gobase-app/services"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorEnrollKey menandai session user yang role-nya mewajibkan 2FA tetapi belum mendaftar.
+const TwoFactorEnrollKey = "two_factor_enroll_required"
+
+// TwoFactorEnrollment memaksa user yang wajib 2FA untuk menyelesaikan pendaftaran
+// sebelum bisa membuka halaman lain.
+func TwoFactorEnrollment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		required, err := twoFactorEnrollRequired(c)
+		if err != nil {
+			log.Printf("two-factor enrollment check failed: %v", err)
+			AbortAPIError(c, http.StatusInternalServerError, "internal_error", "Terjadi kesalahan pada server")
+			return
+		}
+
+		if required && !strings.HasPrefix(c.Request.URL.Path, "/profile/2fa") {
+			if IsAPIRequest(c) {
+				AbortAPIError(c, http.StatusForbidden, "two_factor_enrollment_required", "Selesaikan pendaftaran 2FA di /profile/2fa terlebih dahulu")
+				return
+			}
+			c.Redirect(http.StatusFound, "/profile/2fa")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// twoFactorEnrollRequired membaca tanda wajib daftar 2FA dari session. Request Bearer token tidak
+// punya session, jadi kewajiban tersebut dicek langsung dari role dan status 2FA user.
+func twoFactorEnrollRequired(c *gin.Context) (bool, error) {
+	principal, ok := CurrentTokenPrincipal(c)
+	if !ok {
+		required, _ := sessions.Default(c).Get(TwoFactorEnrollKey).(bool)
+		return required, nil
+	}
+
+	twoFactorSvc := &services.TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: config.DB}}
+	required, err := twoFactorSvc.IsRequired(principal.UserID)
+	if err != nil || !required {
+		return false, err
+	}
+	state, err := twoFactorSvc.GetState(principal.UserID)
+	if err != nil {
+		return false, err
+	}
+	return !state.Enabled, nil
+}
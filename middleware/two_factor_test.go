@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"gobase-app/config"
+	"gobase-app/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTwoFactorEnrollmentChecksTokenUser(t *testing.T) {
+	const userID = 41
+
+	tests := []struct {
+		name       string
+		required   bool
+		enabled    bool
+		wantStatus int
+	}{
+		{name: "role wajib 2FA dan belum mendaftar", required: true, wantStatus: http.StatusForbidden},
+		{name: "role wajib 2FA dan sudah mendaftar", required: true, enabled: true, wantStatus: http.StatusOK},
+		{name: "role tidak mewajibkan 2FA", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			previous := config.DB
+			config.DB = db
+			t.Cleanup(func() {
+				config.DB = previous
+				db.Close()
+			})
+
+			mock.ExpectQuery(`SELECT role_id\s+FROM model_has_roles`).WithArgs(userID, sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow(3))
+			mock.ExpectQuery(`SELECT role_id, parent_id FROM role_parents`).
+				WillReturnRows(sqlmock.NewRows([]string{"role_id", "parent_id"}))
+			required := 0
+			if tt.required {
+				required = 1
+			}
+			mock.ExpectQuery(`AND requires_two_factor = 1`).WithArgs(3).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(required))
+			if tt.required {
+				var secret, enabledAt interface{}
+				if tt.enabled {
+					secret, enabledAt = "JBSWY3DPEHPK3PXP", time.Now()
+				}
+				mock.ExpectQuery(`SELECT\s+u.two_factor_secret`).WithArgs(userID).
+					WillReturnRows(sqlmock.NewRows([]string{"secret", "enabled_at", "last_step", "codes"}).AddRow(secret, enabledAt, nil, 0))
+			}
+
+			// request token tidak membawa session, jadi tidak ada tanda wajib 2FA di session
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(func(c *gin.Context) { c.Set(TokenPrincipalKey, &models.TokenPrincipal{UserID: userID}) })
+			r.GET("/api/v1/users", TwoFactorEnrollment(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
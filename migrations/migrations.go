@@ -0,0 +1,317 @@
+// Package migrations berisi migrasi schema database yang di-embed ke binary. Setiap migrasi
+// adalah pasangan file sql/NNNN_nama.up.sql dan sql/NNNN_nama.down.sql; versi yang sudah
+// dijalankan dicatat di tabel schema_migrations. Seluruh operasi memegang lock MySQL
+// (GET_LOCK) sehingga beberapa instance yang start bersamaan tidak menjalankan migrasi dua kali.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockTimeout adalah lama menunggu instance lain yang sedang menjalankan migrasi.
+const lockTimeout = 60 * time.Second
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration adalah satu versi schema. Down kosong berarti migrasi tidak bisa di-rollback.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status adalah kondisi satu migrasi di database. Missing berarti versi tercatat di
+// schema_migrations tetapi file migrasinya tidak ada di binary ini.
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Missing   bool
+}
+
+// Load membaca seluruh migrasi yang di-embed, terurut dari versi terkecil.
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migrations: nama file %s tidak sesuai format NNNN_nama.up.sql / NNNN_nama.down.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrations: versi file %s tidak valid", entry.Name())
+		}
+		content, err := files.ReadFile(path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrations: versi %d dipakai dua nama (%s dan %s)", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrations: versi %d (%s) tidak punya file up", m.Version, m.Name)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// Up menjalankan seluruh migrasi yang belum tercatat, dari versi terkecil, dan mengembalikan
+// migrasi yang dijalankan. Pada database lama yang schema-nya di-import manual dari dump
+// (schema_migrations masih kosong), migrasi dump dicatat tanpa dijalankan lewat detectBaseline.
+func Up(db *sql.DB, logf func(format string, args ...interface{})) ([]Migration, error) {
+	all, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err = withLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			baseline, err := detectBaseline(ctx, conn)
+			if err != nil {
+				return err
+			}
+			for _, m := range all {
+				if m.Version > baseline {
+					break
+				}
+				if err := recordVersion(ctx, conn, m); err != nil {
+					return err
+				}
+				applied[m.Version] = time.Now()
+				logf("migrations: schema sudah ada, %04d_%s dicatat tanpa dijalankan", m.Version, m.Name)
+			}
+		}
+
+		for _, m := range all {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := execScript(ctx, conn, m.Up); err != nil {
+				return fmt.Errorf("migrations: up %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if err := recordVersion(ctx, conn, m); err != nil {
+				return err
+			}
+			logf("migrations: up %04d_%s", m.Version, m.Name)
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down me-rollback steps migrasi terakhir yang sudah dijalankan, dari versi terbesar, dan
+// mengembalikan migrasi yang di-rollback.
+func Down(db *sql.DB, steps int, logf func(format string, args ...interface{})) ([]Migration, error) {
+	all, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]Migration, len(all))
+	for _, m := range all {
+		byVersion[m.Version] = m
+	}
+
+	var done []Migration
+	err = withLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			m, ok := byVersion[versions[i]]
+			if !ok {
+				return fmt.Errorf("migrations: file migrasi versi %d tidak ada, tidak bisa di-rollback", versions[i])
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migrations: %04d_%s tidak punya file down", m.Version, m.Name)
+			}
+			if err := execScript(ctx, conn, m.Down); err != nil {
+				return fmt.Errorf("migrations: down %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+				return err
+			}
+			logf("migrations: down %04d_%s", m.Version, m.Name)
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Statuses mengembalikan kondisi seluruh migrasi (file maupun yang hanya tercatat di database),
+// terurut berdasarkan versi.
+func Statuses(db *sql.DB) ([]Status, error) {
+	all, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Status
+	err = withLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[int64]bool, len(all))
+		for _, m := range all {
+			known[m.Version] = true
+			appliedAt, ok := applied[m.Version]
+			result = append(result, Status{Version: m.Version, Name: m.Name, Applied: ok, AppliedAt: appliedAt})
+		}
+		for version, appliedAt := range applied {
+			if !known[version] {
+				result = append(result, Status{Version: version, Applied: true, AppliedAt: appliedAt, Missing: true})
+			}
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+		return nil
+	})
+	return result, err
+}
+
+// withLock menjalankan fn di satu koneksi yang memegang lock migrasi. Lock dan perubahan session
+// dari file migrasi hanya berlaku di koneksi tersebut.
+func withLock(db *sql.DB, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx,
+		`SELECT GET_LOCK(CONCAT(DATABASE(), '.schema_migrations'), ?)`, int(lockTimeout.Seconds()),
+	).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return fmt.Errorf("migrations: lock tidak didapat dalam %s, migrasi lain masih berjalan", lockTimeout)
+	}
+	defer conn.ExecContext(ctx, `SELECT RELEASE_LOCK(CONCAT(DATABASE(), '.schema_migrations'))`)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint(20) UNSIGNED NOT NULL PRIMARY KEY,
+			name varchar(255) NOT NULL,
+			applied_at timestamp NOT NULL DEFAULT current_timestamp()
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+	`); err != nil {
+		return err
+	}
+
+	return fn(ctx, conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func recordVersion(ctx context.Context, conn *sql.Conn, m Migration) error {
+	_, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, m.Version, m.Name)
+	return err
+}
+
+// dumpVersion adalah migrasi berisi dump awal gobase_app.sql yang dulu di-import manual.
+const dumpVersion = 1
+
+// detectBaseline mengembalikan versi yang schema-nya sudah ada di database yang belum punya catatan
+// schema_migrations: dumpVersion jika dump awal sudah di-import (ditandai tabel users), atau 0 untuk
+// database kosong.
+func detectBaseline(ctx context.Context, conn *sql.Conn) (int64, error) {
+	exists, err := hasTable(ctx, conn, "users")
+	if err != nil || !exists {
+		return 0, err
+	}
+	return dumpVersion, nil
+}
+
+func hasTable(ctx context.Context, conn *sql.Conn, table string) (bool, error) {
+	var count int
+	err := conn.QueryRowContext(ctx, `
+		SELECT COUNT(1)
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+	`, table).Scan(&count)
+	return count > 0, err
+}
+
+// execScript menjalankan isi file migrasi statement per statement. DDL MySQL tidak bisa
+// di-rollback, jadi migrasi yang gagal di tengah jalan harus dibereskan manual.
+func execScript(ctx context.Context, conn *sql.Conn, script string) error {
+	for i, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDetectBaseline(t *testing.T) {
+	tests := []struct {
+		name  string
+		users int
+		want  int64
+	}{
+		{name: "database kosong", users: 0, want: 0},
+		{name: "dump awal sudah di-import", users: 1, want: dumpVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			mock.ExpectQuery(`FROM information_schema.TABLES`).WithArgs("users").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.users))
+
+			ctx := context.Background()
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			baseline, err := detectBaseline(ctx, conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if baseline != tt.want {
+				t.Errorf("baseline = %d, want %d", baseline, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package migrations
+
+import "strings"
+
+// splitStatements memecah isi file SQL menjadi statement per ";" di luar string, identifier
+// berkutip, dan komentar, karena driver MySQL tidak menjalankan banyak statement sekaligus.
+// Komentar "--" / "#" dan komentar blok biasa dibuang; komentar eksekusi bersyarat MySQL
+// ("/*! ... */") tetap dipertahankan.
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := quotedEnd(script, i)
+			current.WriteString(script[i:end])
+			i = end - 1
+		case ch == '#' || (ch == '-' && strings.HasPrefix(script[i:], "--") &&
+			(i+2 == len(script) || script[i+2] == ' ' || script[i+2] == '\t' || script[i+2] == '\n' || script[i+2] == '\r')):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end - 1
+			}
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script)
+			} else {
+				end += i + 4
+			}
+			if strings.HasPrefix(script[i:], "/*!") {
+				current.WriteString(script[i:end])
+			} else {
+				current.WriteByte(' ')
+			}
+			i = end - 1
+		case ch == ';':
+			flush()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// quotedEnd mengembalikan posisi setelah kutip penutup untuk string yang dimulai di start.
+// Kutip yang ditulis dua kali dan escape backslash (kecuali di identifier `...`) tidak menutup string.
+func quotedEnd(script string, start int) int {
+	quote := script[start]
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(script)
+}
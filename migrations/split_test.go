@@ -0,0 +1,97 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "beberapa statement",
+			script: "CREATE TABLE a (id int);\n\nCREATE TABLE b (id int);\n",
+			want:   []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"},
+		},
+		{
+			name:   "titik koma di dalam string",
+			script: "INSERT INTO t VALUES ('a;b'); INSERT INTO t VALUES (\"c;d\")",
+			want:   []string{"INSERT INTO t VALUES ('a;b')", `INSERT INTO t VALUES ("c;d")`},
+		},
+		{
+			name:   "kutip yang ditulis dua kali",
+			script: "INSERT INTO t VALUES ('it''s;ok'); SELECT 1",
+			want:   []string{"INSERT INTO t VALUES ('it''s;ok')", "SELECT 1"},
+		},
+		{
+			name:   "kutip dengan escape backslash",
+			script: `INSERT INTO t VALUES ('a\';b', "c\";d"); SELECT 1`,
+			want:   []string{`INSERT INTO t VALUES ('a\';b', "c\";d")`, "SELECT 1"},
+		},
+		{
+			// backslash di identifier bukan escape, jadi backtick berikutnya menutup identifier
+			name:   "identifier berkutip",
+			script: "SELECT `a;b\\`; SELECT 2",
+			want:   []string{"SELECT `a;b\\`", "SELECT 2"},
+		},
+		{
+			name:   "komentar baris dibuang",
+			script: "-- Migration 0001; dibuat dari dump\n# komentar; lain\nSELECT 1; -- akhir;\nSELECT 2",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			// "--" tanpa spasi sesudahnya adalah dua tanda minus, bukan komentar
+			name:   "minus ganda tanpa spasi",
+			script: "SELECT 5--1; SELECT 2",
+			want:   []string{"SELECT 5--1", "SELECT 2"},
+		},
+		{
+			name:   "komentar di akhir file tanpa baris baru",
+			script: "SELECT 1;\n-- selesai",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "komentar blok dibuang",
+			script: "SELECT /* kolom; pertama */ 1; /* ; */ SELECT 2",
+			want:   []string{"SELECT   1", "SELECT 2"},
+		},
+		{
+			name:   "komentar eksekusi bersyarat dipertahankan",
+			script: "/*!40101 SET NAMES utf8mb4 */;\nCREATE TABLE a (id int) /*!50100 ENGINE=InnoDB */;",
+			want:   []string{"/*!40101 SET NAMES utf8mb4 */", "CREATE TABLE a (id int) /*!50100 ENGINE=InnoDB */"},
+		},
+		{
+			name:   "tanda kutip di dalam komentar tidak membuka string",
+			script: "-- it's fine\nSELECT 1; /* don't */ SELECT 2",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "hanya komentar dan titik koma",
+			script: "-- kosong\n;;\n/* juga kosong */",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrationsSplit(t *testing.T) {
+	all, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range all {
+		if len(splitStatements(m.Up)) == 0 {
+			t.Errorf("migrasi %04d_%s tidak berisi statement", m.Version, m.Name)
+		}
+	}
+}
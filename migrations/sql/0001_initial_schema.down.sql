@@ -0,0 +1,13 @@
+-- Rollback migration 0001: hapus seluruh tabel schema awal.
+
+SET FOREIGN_KEY_CHECKS = 0;
+
+DROP TABLE IF EXISTS `users`;
+DROP TABLE IF EXISTS `stores`;
+DROP TABLE IF EXISTS `role_has_permissions`;
+DROP TABLE IF EXISTS `roles`;
+DROP TABLE IF EXISTS `permissions`;
+DROP TABLE IF EXISTS `model_has_roles`;
+DROP TABLE IF EXISTS `model_has_permissions`;
+
+SET FOREIGN_KEY_CHECKS = 1;
@@ -0,0 +1,3 @@
+-- Rollback migration 0002.
+
+DROP TABLE IF EXISTS `sessions`;
@@ -0,0 +1,5 @@
+-- Rollback migration 0003.
+
+DELETE FROM `permissions` WHERE `name` = 'user_unlock' AND `guard_name` = 'web';
+
+DROP TABLE IF EXISTS `login_throttles`;
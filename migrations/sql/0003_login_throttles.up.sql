@@ -0,0 +1,19 @@
+-- Migration 0003: throttle login per username dan IP beserta permission buka kunci akun.
+
+CREATE TABLE `login_throttles` (
+  `throttle_key` varchar(191) NOT NULL,
+  `failures` int(11) NOT NULL DEFAULT 0,
+  `last_failed_at` datetime NOT NULL,
+  `locked_until` datetime DEFAULT NULL,
+  PRIMARY KEY (`throttle_key`),
+  KEY `login_throttles_locked_until_index` (`locked_until`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT IGNORE INTO `permissions` (`name`, `group`, `guard_name`, `created_at`, `updated_at`) VALUES
+('user_unlock', 'user', 'web', current_timestamp(), current_timestamp());
+
+INSERT IGNORE INTO `role_has_permissions` (`permission_id`, `role_id`)
+SELECT p.`id`, r.`id`
+FROM `permissions` p
+JOIN `roles` r ON r.`name` = 'super-admin' AND r.`guard_name` = 'web'
+WHERE p.`name` = 'user_unlock' AND p.`guard_name` = 'web';
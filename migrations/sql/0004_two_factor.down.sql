@@ -0,0 +1,11 @@
+-- Rollback migration 0004.
+
+DROP TABLE IF EXISTS `user_recovery_codes`;
+
+ALTER TABLE `users`
+  DROP COLUMN `two_factor_last_step`,
+  DROP COLUMN `two_factor_enabled_at`,
+  DROP COLUMN `two_factor_secret`;
+
+ALTER TABLE `roles`
+  DROP COLUMN `requires_two_factor`;
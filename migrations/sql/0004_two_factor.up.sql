@@ -0,0 +1,20 @@
+-- Migration 0004: login dua langkah (TOTP) dengan recovery code dan kewajiban 2FA per role.
+
+ALTER TABLE `roles`
+  ADD COLUMN `requires_two_factor` tinyint(1) NOT NULL DEFAULT 0 AFTER `is_admin`;
+
+ALTER TABLE `users`
+  ADD COLUMN `two_factor_secret` varchar(64) DEFAULT NULL AFTER `store_id`,
+  ADD COLUMN `two_factor_enabled_at` datetime DEFAULT NULL AFTER `two_factor_secret`,
+  ADD COLUMN `two_factor_last_step` bigint(20) DEFAULT NULL AFTER `two_factor_enabled_at`;
+
+CREATE TABLE `user_recovery_codes` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) NOT NULL,
+  `code_hash` char(64) NOT NULL,
+  `used_at` datetime DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `user_recovery_codes_user_id_code_hash_unique` (`user_id`,`code_hash`),
+  CONSTRAINT `user_recovery_codes_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
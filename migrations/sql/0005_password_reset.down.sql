@@ -0,0 +1,3 @@
+-- Rollback migration 0005.
+
+DROP TABLE IF EXISTS `password_reset_tokens`;
@@ -0,0 +1,6 @@
+-- Rollback migration 0006.
+
+DROP TABLE IF EXISTS `password_histories`;
+
+ALTER TABLE `users`
+  DROP COLUMN `must_change_password`;
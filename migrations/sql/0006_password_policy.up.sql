@@ -0,0 +1,13 @@
+-- Migration 0006: riwayat password dan paksa ganti password saat login berikutnya.
+
+ALTER TABLE `users`
+  ADD COLUMN `must_change_password` tinyint(1) NOT NULL DEFAULT 0 AFTER `two_factor_last_step`;
+
+CREATE TABLE `password_histories` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `user_id` int(11) NOT NULL,
+  `password_hash` varchar(255) NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  KEY `password_histories_user_id_index` (`user_id`, `id`),
+  CONSTRAINT `password_histories_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
@@ -0,0 +1,10 @@
+-- Rollback migration 0007. User yang masih pending dijadikan non_active karena status pending
+-- dihapus dari enum.
+
+DROP TABLE IF EXISTS `email_verification_tokens`;
+
+UPDATE `users` SET `status` = 'non_active' WHERE `status` = 'pending';
+
+ALTER TABLE `users`
+  DROP COLUMN `email_verified_at`,
+  MODIFY `status` enum('active','non_active') DEFAULT 'active';
@@ -0,0 +1,18 @@
+-- Migration 0007: registrasi mandiri dengan verifikasi email dan persetujuan admin
+-- (status user pending).
+
+ALTER TABLE `users`
+  MODIFY `status` enum('active','non_active','pending') DEFAULT 'active',
+  ADD COLUMN `email_verified_at` datetime DEFAULT NULL AFTER `must_change_password`;
+
+CREATE TABLE `email_verification_tokens` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `user_id` int(11) NOT NULL,
+  `token_hash` char(64) NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  UNIQUE KEY `email_verification_tokens_token_hash_unique` (`token_hash`),
+  KEY `email_verification_tokens_user_id_index` (`user_id`),
+  CONSTRAINT `email_verification_tokens_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
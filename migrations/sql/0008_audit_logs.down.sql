@@ -0,0 +1,5 @@
+-- Rollback migration 0008.
+
+DELETE FROM `permissions` WHERE `name` = 'audit_log_access' AND `guard_name` = 'web';
+
+DROP TABLE IF EXISTS `audit_logs`;
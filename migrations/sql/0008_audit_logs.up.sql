@@ -0,0 +1,28 @@
+-- Migration 0008: audit trail perubahan data beserta permission halaman /audit.
+
+CREATE TABLE `audit_logs` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `actor_id` int(11) DEFAULT NULL,
+  `actor_name` varchar(255) NOT NULL DEFAULT '',
+  `action` varchar(64) NOT NULL,
+  `entity_type` varchar(64) NOT NULL,
+  `entity_id` bigint(20) NOT NULL,
+  `old_values` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL CHECK (json_valid(`old_values`)),
+  `new_values` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL CHECK (json_valid(`new_values`)),
+  `ip_address` varchar(45) NOT NULL DEFAULT '',
+  `user_agent` text DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  KEY `audit_logs_entity_index` (`entity_type`, `entity_id`),
+  KEY `audit_logs_actor_id_index` (`actor_id`),
+  KEY `audit_logs_action_index` (`action`),
+  KEY `audit_logs_created_at_index` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+INSERT IGNORE INTO `permissions` (`name`, `group`, `guard_name`, `created_at`, `updated_at`) VALUES
+('audit_log_access', 'audit', 'web', current_timestamp(), current_timestamp());
+
+INSERT IGNORE INTO `role_has_permissions` (`permission_id`, `role_id`)
+SELECT p.`id`, r.`id`
+FROM `permissions` p
+JOIN `roles` r ON r.`name` = 'super-admin' AND r.`guard_name` = 'web'
+WHERE p.`name` = 'audit_log_access' AND p.`guard_name` = 'web';
@@ -0,0 +1,5 @@
+-- Rollback migration 0009.
+
+DELETE FROM `permissions` WHERE `name` = 'user_session_access' AND `guard_name` = 'web';
+
+DROP TABLE IF EXISTS `auth_events`;
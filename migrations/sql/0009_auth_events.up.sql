@@ -0,0 +1,24 @@
+-- Migration 0009: riwayat login, logout, dan session kedaluwarsa beserta permission kelola
+-- session user lain.
+
+CREATE TABLE `auth_events` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `user_id` int(11) DEFAULT NULL,
+  `username` varchar(255) NOT NULL DEFAULT '',
+  `event` varchar(32) NOT NULL,
+  `ip_address` varchar(45) NOT NULL DEFAULT '',
+  `user_agent` text DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  KEY `auth_events_user_id_created_at_index` (`user_id`, `created_at`),
+  KEY `auth_events_event_index` (`event`),
+  KEY `auth_events_created_at_index` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+INSERT IGNORE INTO `permissions` (`name`, `group`, `guard_name`, `created_at`, `updated_at`) VALUES
+('user_session_access', 'user', 'web', current_timestamp(), current_timestamp());
+
+INSERT IGNORE INTO `role_has_permissions` (`permission_id`, `role_id`)
+SELECT p.`id`, r.`id`
+FROM `permissions` p
+JOIN `roles` r ON r.`name` = 'super-admin' AND r.`guard_name` = 'web'
+WHERE p.`name` = 'user_session_access' AND p.`guard_name` = 'web';
@@ -0,0 +1,3 @@
+-- Rollback migration 0010.
+
+DROP TABLE IF EXISTS `personal_access_tokens`;
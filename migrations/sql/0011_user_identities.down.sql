@@ -0,0 +1,3 @@
+-- Rollback migration 0011.
+
+DROP TABLE IF EXISTS `user_identities`;
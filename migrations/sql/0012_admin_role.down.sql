@@ -0,0 +1,3 @@
+-- Rollback migration 0012.
+
+UPDATE `roles` SET `is_admin` = 0 WHERE `name` = 'super-admin' AND `guard_name` = 'web';
@@ -0,0 +1,4 @@
+-- Migration 0012: role super-admin menjadi role admin (is_admin) yang lolos semua pengecekan
+-- permission.
+
+UPDATE `roles` SET `is_admin` = 1 WHERE `name` = 'super-admin' AND `guard_name` = 'web';
@@ -0,0 +1,6 @@
+-- Rollback migration 0013. Deny dihapus agar tidak berubah menjadi grant.
+
+DELETE FROM `model_has_permissions` WHERE `is_denied` = 1;
+
+ALTER TABLE `model_has_permissions`
+  DROP COLUMN `is_denied`;
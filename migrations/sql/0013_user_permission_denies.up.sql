@@ -0,0 +1,4 @@
+-- Migration 0013: permission langsung per user bisa berupa grant atau deny.
+
+ALTER TABLE `model_has_permissions`
+  ADD COLUMN `is_denied` tinyint(1) NOT NULL DEFAULT 0 AFTER `model_id`;
@@ -0,0 +1,4 @@
+-- Rollback migration 0014.
+
+ALTER TABLE `permissions`
+  DROP COLUMN `description`;
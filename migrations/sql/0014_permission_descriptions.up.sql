@@ -0,0 +1,4 @@
+-- Migration 0014: deskripsi permission dari registry di kode.
+
+ALTER TABLE `permissions`
+  ADD COLUMN `description` varchar(255) DEFAULT NULL AFTER `group`;
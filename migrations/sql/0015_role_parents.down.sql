@@ -0,0 +1,3 @@
+-- Rollback migration 0015.
+
+DROP TABLE IF EXISTS `role_parents`;
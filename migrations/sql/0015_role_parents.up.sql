@@ -0,0 +1,11 @@
+-- Migration 0015: hierarki role; role_id mewarisi seluruh permission parent_id beserta
+-- leluhurnya.
+
+CREATE TABLE `role_parents` (
+  `role_id` bigint(20) UNSIGNED NOT NULL,
+  `parent_id` bigint(20) UNSIGNED NOT NULL,
+  PRIMARY KEY (`role_id`, `parent_id`),
+  KEY `role_parents_parent_id_index` (`parent_id`),
+  CONSTRAINT `role_parents_role_id_foreign` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `role_parents_parent_id_foreign` FOREIGN KEY (`parent_id`) REFERENCES `roles` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
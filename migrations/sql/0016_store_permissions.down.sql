@@ -0,0 +1,6 @@
+-- Rollback migration 0016.
+
+DROP TABLE IF EXISTS `model_has_store_permissions`;
+
+ALTER TABLE `stores`
+  DROP PRIMARY KEY;
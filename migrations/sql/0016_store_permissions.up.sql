@@ -0,0 +1,16 @@
+-- Migration 0016: permission yang hanya berlaku di satu store, mis. stock_issue khusus MK2.
+
+ALTER TABLE `stores`
+  ADD PRIMARY KEY (`store_id`);
+
+CREATE TABLE `model_has_store_permissions` (
+  `permission_id` bigint(20) UNSIGNED NOT NULL,
+  `model_type` varchar(255) NOT NULL,
+  `model_id` bigint(20) UNSIGNED NOT NULL,
+  `store_id` int(11) NOT NULL,
+  PRIMARY KEY (`permission_id`, `model_id`, `model_type`, `store_id`),
+  KEY `model_has_store_permissions_model_id_model_type_index` (`model_id`, `model_type`),
+  KEY `model_has_store_permissions_store_id_index` (`store_id`),
+  CONSTRAINT `model_has_store_permissions_permission_id_foreign` FOREIGN KEY (`permission_id`) REFERENCES `permissions` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `model_has_store_permissions_store_id_foreign` FOREIGN KEY (`store_id`) REFERENCES `stores` (`store_id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
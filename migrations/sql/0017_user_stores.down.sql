@@ -0,0 +1,17 @@
+-- Rollback migration 0017: kolom JSON users.store_id diisi ulang dari user_stores.
+
+ALTER TABLE `users`
+  ADD COLUMN `store_id` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL AFTER `status`;
+
+UPDATE `users` u
+SET u.`store_id` = (
+  SELECT CONCAT('[', COALESCE(GROUP_CONCAT(us.`store_id` ORDER BY us.`store_id`), ''), ']')
+  FROM `user_stores` us
+  WHERE us.`user_id` = u.`id`
+);
+
+ALTER TABLE `users`
+  MODIFY `store_id` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL CHECK (json_valid(`store_id`)),
+  ADD KEY `store_id` (`store_id`(768));
+
+DROP TABLE IF EXISTS `user_stores`;
@@ -0,0 +1,21 @@
+-- Migration 0017: assignment store user pindah dari kolom JSON users.store_id ke tabel
+-- user_stores. Isi JSON disalin lalu kolomnya dihapus; id store yang tidak ada di tabel stores
+-- ikut terbuang karena memang tidak merujuk store mana pun.
+
+CREATE TABLE `user_stores` (
+  `user_id` int(11) NOT NULL,
+  `store_id` int(11) NOT NULL,
+  PRIMARY KEY (`user_id`, `store_id`),
+  KEY `user_stores_store_id_index` (`store_id`),
+  CONSTRAINT `user_stores_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `user_stores_store_id_foreign` FOREIGN KEY (`store_id`) REFERENCES `stores` (`store_id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT IGNORE INTO `user_stores` (`user_id`, `store_id`)
+SELECT u.`id`, s.`store_id`
+FROM `users` u
+JOIN `stores` s ON JSON_CONTAINS(u.`store_id`, CAST(s.`store_id` AS CHAR))
+WHERE JSON_VALID(u.`store_id`);
+
+ALTER TABLE `users`
+  DROP COLUMN `store_id`;
@@ -0,0 +1,9 @@
+-- Rollback migration 0018.
+
+SET FOREIGN_KEY_CHECKS = 0;
+
+ALTER TABLE `stores`
+  DROP INDEX `stores_store_code_unique`,
+  MODIFY `store_id` int(11) NOT NULL;
+
+SET FOREIGN_KEY_CHECKS = 1;
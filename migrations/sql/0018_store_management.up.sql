@@ -0,0 +1,11 @@
+-- Migration 0018: stores bisa dibuat dari aplikasi (/stores).
+-- store_id diberi AUTO_INCREMENT dan store_code dibuat unik. Foreign key check dimatikan
+-- sementara karena store_id sudah dirujuk model_has_store_permissions dan user_stores.
+
+SET FOREIGN_KEY_CHECKS = 0;
+
+ALTER TABLE `stores`
+  MODIFY `store_id` int(11) NOT NULL AUTO_INCREMENT,
+  ADD UNIQUE KEY `stores_store_code_unique` (`store_code`);
+
+SET FOREIGN_KEY_CHECKS = 1;
@@ -0,0 +1,3 @@
+-- Rollback migration 0019.
+
+DROP TABLE IF EXISTS `items`;
@@ -0,0 +1,20 @@
+-- Migration 0019: master data hadiah (/items).
+-- value berisi jumlah poin (value_type = point) atau nominal voucher dalam rupiah
+-- (value_type = voucher). Item tidak dihapus, hanya dinonaktifkan (is_active = 0).
+
+CREATE TABLE `items` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `sku` varchar(50) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `category` varchar(100) NOT NULL,
+  `unit` varchar(30) NOT NULL,
+  `image_path` varchar(255) DEFAULT NULL,
+  `value_type` enum('point','voucher') NOT NULL DEFAULT 'point',
+  `value` bigint(20) UNSIGNED NOT NULL DEFAULT 0,
+  `is_active` tinyint(1) NOT NULL DEFAULT 1,
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updated_at` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  UNIQUE KEY `items_sku_unique` (`sku`),
+  KEY `items_category_index` (`category`),
+  KEY `items_is_active_index` (`is_active`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
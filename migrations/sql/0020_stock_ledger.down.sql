@@ -0,0 +1,4 @@
+-- Rollback migration 0020.
+
+DROP TABLE IF EXISTS `stock_movements`;
+DROP TABLE IF EXISTS `stock_balances`;
@@ -0,0 +1,44 @@
+-- Migration 0020: ledger stok hadiah per store (/stock).
+-- stock_movements hanya pernah di-INSERT: setiap penerimaan, pengeluaran, penyesuaian, dan
+-- transfer dicatat sebagai baris baru dengan quantity bertanda (+ masuk, - keluar). Transfer
+-- dicatat dua baris (keluar di store asal, masuk di store tujuan) yang saling merujuk lewat
+-- counterpart_store_id dan transfer_ref yang sama.
+-- stock_balances adalah saldo per item per store yang selalu sama dengan SUM(quantity) di
+-- stock_movements; keduanya diubah dalam satu transaksi dengan baris saldo dikunci (FOR UPDATE).
+-- actor_id tidak diberi foreign key (sama seperti audit_logs) agar ledger tetap utuh saat user
+-- dihapus; actor_name menyimpan nama user saat itu.
+
+CREATE TABLE `stock_balances` (
+  `store_id` int(11) NOT NULL,
+  `item_id` bigint(20) UNSIGNED NOT NULL,
+  `quantity` bigint(20) UNSIGNED NOT NULL DEFAULT 0,
+  `updated_at` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`store_id`, `item_id`),
+  KEY `stock_balances_item_id_index` (`item_id`),
+  CONSTRAINT `stock_balances_store_id_foreign` FOREIGN KEY (`store_id`) REFERENCES `stores` (`store_id`),
+  CONSTRAINT `stock_balances_item_id_foreign` FOREIGN KEY (`item_id`) REFERENCES `items` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `stock_movements` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `store_id` int(11) NOT NULL,
+  `item_id` bigint(20) UNSIGNED NOT NULL,
+  `movement_type` enum('receipt','issue','adjustment','transfer') NOT NULL,
+  `quantity` bigint(20) NOT NULL,
+  `balance_after` bigint(20) UNSIGNED NOT NULL,
+  `counterpart_store_id` int(11) DEFAULT NULL,
+  `transfer_ref` char(32) DEFAULT NULL,
+  `reason` varchar(255) NOT NULL,
+  `reference` varchar(100) DEFAULT NULL,
+  `actor_id` int(11) DEFAULT NULL,
+  `actor_name` varchar(255) NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  KEY `stock_movements_store_id_item_id_index` (`store_id`, `item_id`, `id`),
+  KEY `stock_movements_item_id_index` (`item_id`),
+  KEY `stock_movements_movement_type_index` (`movement_type`),
+  KEY `stock_movements_transfer_ref_index` (`transfer_ref`),
+  KEY `stock_movements_created_at_index` (`created_at`),
+  CONSTRAINT `stock_movements_store_id_foreign` FOREIGN KEY (`store_id`) REFERENCES `stores` (`store_id`),
+  CONSTRAINT `stock_movements_item_id_foreign` FOREIGN KEY (`item_id`) REFERENCES `items` (`id`),
+  CONSTRAINT `stock_movements_counterpart_store_id_foreign` FOREIGN KEY (`counterpart_store_id`) REFERENCES `stores` (`store_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
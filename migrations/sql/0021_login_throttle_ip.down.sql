@@ -0,0 +1,4 @@
+-- Rollback migration 0021.
+
+ALTER TABLE `login_throttles`
+  DROP COLUMN `last_ip`;
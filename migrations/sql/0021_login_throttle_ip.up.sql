@@ -0,0 +1,5 @@
+-- Migration 0021: simpan IP terakhir pada counter username agar buka kunci oleh admin ikut
+-- membuka kunci IP yang memicunya.
+
+ALTER TABLE `login_throttles`
+  ADD COLUMN `last_ip` varchar(45) DEFAULT NULL AFTER `locked_until`;
@@ -0,0 +1,154 @@
+package migrations
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// userStoresVersion adalah migrasi yang memindahkan assignment store user dari kolom JSON
+// users.store_id ke tabel user_stores.
+const userStoresVersion = 17
+
+// UserStoresBackfillResult merangkum hasil BackfillUserStores.
+type UserStoresBackfillResult struct {
+	Users    int      // user yang kolom JSON-nya dibaca
+	Inserted int      // baris user_stores baru
+	Skipped  []string // entry yang tidak bisa dipindahkan, mis. "user 6: store 9 tidak ada"
+}
+
+// HasLegacyStoreColumn mengecek apakah kolom JSON users.store_id masih ada di database.
+func HasLegacyStoreColumn(db *sql.DB) (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(1)
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'users' AND COLUMN_NAME = 'store_id'
+	`).Scan(&count)
+	return count > 0, err
+}
+
+// BackfillUserStores menyalin isi kolom JSON users.store_id ke tabel user_stores dalam satu
+// transaksi memakai statement yang sama dengan migrasi 0017. Baris yang sudah ada dilewati sehingga
+// aman dijalankan berulang kali. Id store yang tidak ada di tabel stores dan JSON yang tidak valid
+// tidak ikut tersalin dan dicatat di Skipped, bukan dianggap error.
+func BackfillUserStores(db *sql.DB) (UserStoresBackfillResult, error) {
+	var result UserStoresBackfillResult
+
+	insert, err := userStoresBackfillStatement()
+	if err != nil {
+		return result, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return result, err
+	}
+
+	stores := make(map[int]bool)
+	storeRows, err := tx.Query(`SELECT store_id FROM stores`)
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	for storeRows.Next() {
+		var id int
+		if err := storeRows.Scan(&id); err != nil {
+			storeRows.Close()
+			tx.Rollback()
+			return result, err
+		}
+		stores[id] = true
+	}
+	storeRows.Close()
+	if err := storeRows.Err(); err != nil {
+		tx.Rollback()
+		return result, err
+	}
+
+	userRows, err := tx.Query(`SELECT id, COALESCE(store_id, '') FROM users ORDER BY id`)
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	for userRows.Next() {
+		var (
+			id        int64
+			storeJSON string
+		)
+		if err := userRows.Scan(&id, &storeJSON); err != nil {
+			userRows.Close()
+			tx.Rollback()
+			return result, err
+		}
+		result.Users++
+		result.Skipped = append(result.Skipped, legacyStoreProblems(id, storeJSON, stores)...)
+	}
+	userRows.Close()
+	if err := userRows.Err(); err != nil {
+		tx.Rollback()
+		return result, err
+	}
+
+	res, err := tx.Exec(insert)
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	result.Inserted = int(affected)
+
+	return result, tx.Commit()
+}
+
+// DropLegacyStoreColumn menghapus kolom JSON users.store_id (beserta index-nya) setelah backfill.
+func DropLegacyStoreColumn(db *sql.DB) error {
+	_, err := db.Exec(`ALTER TABLE users DROP COLUMN store_id`)
+	return err
+}
+
+// userStoresBackfillStatement mengambil statement migrasi 0017 yang menyalin isi kolom JSON
+// users.store_id ke tabel user_stores, sehingga konversinya hanya ditulis sekali.
+func userStoresBackfillStatement() (string, error) {
+	all, err := Load()
+	if err != nil {
+		return "", err
+	}
+	for _, m := range all {
+		if m.Version != userStoresVersion {
+			continue
+		}
+		for _, stmt := range splitStatements(m.Up) {
+			if strings.HasPrefix(stmt, "INSERT IGNORE INTO `user_stores`") {
+				return stmt, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("migrations: statement salin user_stores tidak ditemukan di migrasi %04d", userStoresVersion)
+}
+
+// legacyStoreProblems menjelaskan entry kolom JSON store_id milik user yang tidak ikut tersalin
+// oleh statement migrasi: JSON yang bukan array id atau id store yang tidak ada.
+func legacyStoreProblems(userID int64, storeJSON string, stores map[int]bool) []string {
+	if storeJSON == "" {
+		return nil
+	}
+
+	var storeIDs []int
+	if err := json.Unmarshal([]byte(storeJSON), &storeIDs); err != nil {
+		return []string{fmt.Sprintf("user %d: store_id bukan JSON array (%s)", userID, storeJSON)}
+	}
+
+	var problems []string
+	for _, storeID := range storeIDs {
+		if !stores[storeID] {
+			problems = append(problems, fmt.Sprintf("user %d: store %d tidak ada", userID, storeID))
+		}
+	}
+	return problems
+}
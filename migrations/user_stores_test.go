@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLegacyStoreProblems(t *testing.T) {
+	stores := map[int]bool{1: true, 2: true}
+
+	tests := []struct {
+		name      string
+		storeJSON string
+		want      []string
+	}{
+		{name: "kosong", storeJSON: ""},
+		{name: "semua store ada", storeJSON: "[1,2]"},
+		{name: "array kosong", storeJSON: "[]"},
+		{name: "store tidak ada", storeJSON: "[1,9,12]", want: []string{"user 6: store 9 tidak ada", "user 6: store 12 tidak ada"}},
+		{name: "bukan JSON", storeJSON: "1,2", want: []string{"user 6: store_id bukan JSON array (1,2)"}},
+		{name: "bukan array id", storeJSON: `{"store": 1}`, want: []string{`user 6: store_id bukan JSON array ({"store": 1})`}},
+		{name: "id berupa string", storeJSON: `["1"]`, want: []string{`user 6: store_id bukan JSON array (["1"])`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := legacyStoreProblems(6, tt.storeJSON, stores); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("legacyStoreProblems(%q) = %q, want %q", tt.storeJSON, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackfillUserStores(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT store_id FROM stores`).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery(`SELECT id, COALESCE\(store_id, ''\) FROM users`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id"}).
+			AddRow(5, "[1,2]").AddRow(6, "[2,9]").AddRow(7, "").AddRow(8, "rusak"))
+	// statement yang dijalankan adalah statement salin dari migrasi 0017
+	mock.ExpectExec("INSERT IGNORE INTO `user_stores`.*JSON_CONTAINS").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	result, err := BackfillUserStores(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UserStoresBackfillResult{
+		Users:    4,
+		Inserted: 3,
+		Skipped:  []string{"user 6: store 9 tidak ada", "user 8: store_id bukan JSON array (rusak)"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUserStoresBackfillStatement(t *testing.T) {
+	stmt, err := userStoresBackfillStatement()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stmt, "INSERT IGNORE INTO `user_stores`") || !strings.Contains(stmt, "JSON_CONTAINS") {
+		t.Errorf("statement = %q, want INSERT user_stores dari migrasi 0017", stmt)
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+// AuditActor berisi identitas pelaku perubahan yang dicatat di audit log.
+// UserID 0 berarti perubahan dilakukan tanpa login (mis. registrasi mandiri). AuditActor hanya
+// untuk pencatatan; pengecekan hak akses memakai Principal.
+type AuditActor struct {
+	UserID    int
+	Username  string
+	IPAddress string
+	UserAgent string
+}
+
+// AuditLog merepresentasikan satu baris pada tabel audit_logs.
+// OldValues/NewValues berisi JSON field yang berubah saja.
+type AuditLog struct {
+	ID               int64
+	ActorID          int
+	ActorName        string
+	Action           string
+	EntityType       string
+	EntityID         int64
+	OldValues        string
+	NewValues        string
+	IPAddress        string
+	UserAgent        string
+	CreatedAt        string
+	CreatedAtDisplay string
+}
+
+// AuditFilter menampung filter pencarian di halaman audit log.
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   int64
+	DateFrom   string
+	DateTo     string
+	Page       int
+	PerPage    int
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Jenis event autentikasi yang dicatat di tabel auth_events.
+const (
+	AuthEventLoginSuccess   = "login_success"
+	AuthEventLoginFailed    = "login_failed"
+	AuthEventLogout         = "logout"
+	AuthEventSessionExpired = "session_expired"
+	AuthEventSessionRevoked = "session_revoked"
+)
+
+// AuthEvent merepresentasikan satu kejadian login, logout, atau berakhirnya session.
+type AuthEvent struct {
+	ID        int64
+	UserID    int
+	Username  string
+	Event     string
+	IPAddress string
+	UserAgent string
+	CreatedAt time.Time
+
+	EventLabel       string
+	CreatedAtDisplay string
+}
+
+// ActiveSession adalah session aktif milik user untuk ditampilkan di halaman profil.
+// Key merupakan hash dari ID session sehingga ID aslinya tidak pernah dikirim ke browser.
+type ActiveSession struct {
+	Key                 string
+	IPAddress           string
+	UserAgent           string
+	LastActivityDisplay string
+	ExpiresAtDisplay    string
+	Current             bool
+}
@@ -0,0 +1,40 @@
+package models
+
+// Item merepresentasikan satu hadiah pada tabel items.
+type Item struct {
+	ID        int64
+	SKU       string
+	Name      string
+	Category  string
+	Unit      string
+	ImagePath string // path URL gambar (mis. /uploads/items/abc.jpg), kosong jika tanpa gambar
+	ValueType string // "point" atau "voucher"
+	Value     int64
+	IsActive  bool
+	// ValueDisplay adalah Value yang sudah diformat, mis. "1.250 poin" atau "Rp 50.000".
+	ValueDisplay     string
+	UpdatedAtDisplay string
+}
+
+// ItemFilter menampung pencarian dan filter di halaman daftar hadiah. Status "active",
+// "inactive", atau kosong untuk semua item.
+type ItemFilter struct {
+	Query    string
+	Category string
+	Status   string
+	Page     int
+	PerPage  int
+}
+
+// ItemInput menampung data dari form create/edit hadiah. ID 0 berarti item baru.
+type ItemInput struct {
+	ID        int64
+	SKU       string
+	Name      string
+	Category  string
+	Unit      string
+	ValueType string
+	Value     int64
+	// RemoveImage menghapus gambar yang tersimpan (diabaikan jika ada gambar baru yang diunggah).
+	RemoveImage bool
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// LoginThrottle menyimpan jumlah kegagalan login untuk satu key (username atau IP).
+type LoginThrottle struct {
+	Key          string
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  time.Time
+	LastIP       string // IP terakhir yang gagal; kosong untuk key IP dan permintaan reset password
+}
+
+// LockedAccount mewakili username yang sedang terkunci untuk ditampilkan di halaman admin.
+type LockedAccount struct {
+	Username           string
+	Failures           int
+	LastFailedDisplay  string
+	LockedUntilDisplay string
+}
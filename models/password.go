@@ -0,0 +1,10 @@
+package models
+
+// PasswordOwner berisi data user yang dipakai saat memvalidasi password baru.
+type PasswordOwner struct {
+	ID           int
+	NIP          int
+	Username     string
+	Name         string
+	PasswordHash string
+}
@@ -2,10 +2,13 @@ package models
 
 // Permission represents a permission record.
 type Permission struct {
-	ID        int64
-	Name      string
-	GroupName string
-	GuardName string
+	ID          int64
+	Name        string
+	GroupName   string
+	GuardName   string
+	Description string
+	// Orphan bernilai true jika permission ada di database tetapi tidak lagi dideklarasikan di kode.
+	Orphan bool
 }
 
 // PermissionGroup bundles permissions under the same group name.
@@ -14,3 +17,41 @@ type PermissionGroup struct {
 	Label       string
 	Permissions []Permission
 }
+
+// UserPermission adalah satu permission pada layar permission user beserta asal-usulnya.
+type UserPermission struct {
+	Permission
+	RoleNames []string // role yang memberikan permission ini
+	Granted   bool     // diberikan langsung ke user
+	Denied    bool     // ditolak langsung, mengalahkan role dan grant
+	Effective bool
+}
+
+// UserPermissionGroup mengelompokkan UserPermission berdasarkan group permission.
+type UserPermissionGroup struct {
+	Key         string
+	Label       string
+	Permissions []UserPermission
+}
+
+// UserPermissionOverview adalah permission efektif seorang user untuk ditampilkan di layar admin.
+type UserPermissionOverview struct {
+	UserID         int
+	Username       string
+	Name           string
+	AdminRoles     []string // role is_admin milik user; jika ada, semua permission efektif
+	Groups         []UserPermissionGroup
+	EffectiveCount int
+
+	Stores           []Store               // store milik user, pilihan untuk permission per store
+	StorePermissions []UserStorePermission // permission yang hanya berlaku di store tertentu
+}
+
+// UserStorePermission adalah permission yang diberikan ke user khusus untuk satu store.
+type UserStorePermission struct {
+	PermissionID   int64
+	PermissionName string
+	StoreID        int
+	StoreName      string
+	Denied         bool // permission ditolak langsung sehingga grant store ini tidak berlaku
+}
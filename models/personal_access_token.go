@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// PersonalAccessToken adalah token API milik user (tabel personal_access_tokens).
+// Token asli hanya ditampilkan sekali saat dibuat; database menyimpan hash SHA-256-nya.
+type PersonalAccessToken struct {
+	ID         int64
+	UserID     int
+	Name       string
+	Abilities  []string
+	LastUsedAt *time.Time
+	ExpiresAt  *time.Time
+	CreatedAt  time.Time
+
+	LastUsedDisplay  string
+	ExpiresAtDisplay string
+	CreatedAtDisplay string
+	Expired          bool
+}
+
+// TokenPrincipal adalah identitas request yang diautentikasi dengan Bearer token.
+// Abilities membatasi permission yang boleh dipakai token, di atas permission milik user.
+type TokenPrincipal struct {
+	TokenID   int64
+	UserID    int
+	Username  string
+	Abilities map[string]bool
+	// LastUsedAt adalah waktu terakhir token dipakai sebelum request ini (nil jika belum pernah).
+	LastUsedAt *time.Time
+}
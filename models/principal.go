@@ -0,0 +1,16 @@
+package models
+
+// Principal adalah identitas yang dipakai service untuk memutuskan otorisasi. Berbeda dengan
+// AuditActor yang hanya mencatat siapa pelaku perubahan, Principal juga membawa batasan ability
+// jika request memakai token API. Token true dengan Abilities kosong berarti token tanpa ability.
+type Principal struct {
+	UserID    int
+	Token     bool
+	Abilities map[string]bool
+}
+
+// Allows melaporkan apakah permission name boleh dipakai principal ini: login biasa tidak dibatasi,
+// sedangkan token hanya boleh memakai permission yang ada di ability-nya.
+func (p Principal) Allows(name string) bool {
+	return !p.Token || p.Abilities[name]
+}
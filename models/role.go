@@ -4,6 +4,7 @@ package models
 type Role struct {
 	ID              int
 	Name            string
+	IsAdmin         bool
 	PermissionCount int
 	UserCount       int
 	UpdatedAt       string
@@ -11,24 +12,41 @@ type Role struct {
 
 // RoleCreateInput mewakili payload untuk membuat role baru.
 type RoleCreateInput struct {
-	Name          string
-	GuardName     string
-	PermissionIDs []int64
+	Name              string
+	GuardName         string
+	IsAdmin           bool
+	RequiresTwoFactor bool
+	PermissionIDs     []int64
+	ParentIDs         []int
 }
 
 // RoleDetail mewakili detail role beserta daftar permission yang dimiliki.
 type RoleDetail struct {
-	ID            int
-	Name          string
-	GuardName     string
-	IsAdmin       bool
-	PermissionIDs []int64
+	ID                int
+	Name              string
+	GuardName         string
+	IsAdmin           bool
+	RequiresTwoFactor bool
+	PermissionIDs     []int64
+	ParentIDs         []int
 }
 
 // RoleUpdateInput mewakili payload untuk memperbarui role yang ada.
 type RoleUpdateInput struct {
+	ID                int
+	Name              string
+	GuardName         string
+	IsAdmin           bool
+	RequiresTwoFactor bool
+	PermissionIDs     []int64
+	ParentIDs         []int
+}
+
+// RoleOption adalah pilihan parent pada form role. Disabled bernilai true jika memilih role
+// tersebut akan membentuk siklus pewarisan.
+type RoleOption struct {
 	ID            int
 	Name          string
-	GuardName     string
-	PermissionIDs []int64
+	Disabled      bool
+	PermissionIDs []int64 // permission yang akan diwariskan, termasuk dari leluhur role ini
 }
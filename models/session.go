@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Session merepresentasikan data session server-side pada tabel sessions.
+type Session struct {
+	ID           string
+	UserID       int
+	IPAddress    string
+	UserAgent    string
+	Payload      []byte
+	LastActivity time.Time
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,84 @@
+package models
+
+// Jenis mutasi stok pada kolom stock_movements.movement_type.
+const (
+	StockReceipt    = "receipt"    // penerimaan barang dari luar (supplier/gudang pusat)
+	StockIssue      = "issue"      // pengeluaran hadiah ke pelanggan
+	StockAdjustment = "adjustment" // koreksi hasil hitung fisik (stock opname)
+	StockTransfer   = "transfer"   // perpindahan antar store, dicatat di kedua store
+)
+
+// StockBalance adalah saldo satu item di satu store (tabel stock_balances).
+type StockBalance struct {
+	StoreID          int
+	StoreCode        string
+	StoreName        string
+	ItemID           int64
+	ItemSKU          string
+	ItemName         string
+	ItemCategory     string
+	ItemUnit         string
+	ItemActive       bool
+	Quantity         int64
+	UpdatedAtDisplay string
+}
+
+// StockMovement adalah satu baris ledger stok. Quantity bertanda: positif untuk stok masuk dan
+// negatif untuk stok keluar. BalanceAfter adalah saldo item di store tersebut setelah mutasi.
+type StockMovement struct {
+	ID           int64
+	StoreID      int
+	StoreCode    string
+	StoreName    string
+	ItemID       int64
+	ItemSKU      string
+	ItemName     string
+	ItemUnit     string
+	Type         string
+	Quantity     int64
+	BalanceAfter int64
+	// CounterpartStoreID/-Name adalah store tujuan (transfer keluar) atau asal (transfer masuk).
+	CounterpartStoreID   int
+	CounterpartStoreName string
+	TransferRef          string
+	Reason               string
+	Reference            string
+	ActorID              int
+	ActorName            string
+	CreatedAt            string
+	CreatedAtDisplay     string
+}
+
+// StockBalanceFilter menampung filter di halaman saldo stok. StoreID 0 berarti semua store dalam
+// scope user.
+type StockBalanceFilter struct {
+	StoreID  int
+	Query    string
+	Category string
+	Page     int
+	PerPage  int
+}
+
+// StockMovementFilter menampung filter di halaman ledger stok. StoreID 0 berarti semua store
+// dalam scope user.
+type StockMovementFilter struct {
+	StoreID  int
+	Query    string
+	Type     string
+	DateFrom string
+	DateTo   string
+	Page     int
+	PerPage  int
+}
+
+// StockMovementInput menampung data dari form mutasi stok. Untuk penyesuaian, Quantity adalah
+// jumlah hasil hitung fisik (saldo baru), bukan selisihnya. ToStoreID hanya dipakai transfer.
+type StockMovementInput struct {
+	Type      string
+	StoreID   int
+	ItemID    int64
+	Quantity  int64
+	ToStoreID int
+	Reason    string
+	Reference string
+}
@@ -2,6 +2,32 @@ package models
 
 // Store merepresentasikan data toko.
 type Store struct {
-	StoreID   int
-	StoreName string
+	StoreID      int
+	StoreCode    string
+	StoreName    string
+	StoreAddress string
+	// IsActive bernilai false untuk store yang dinonaktifkan: tidak muncul di pilihan store form
+	// user, tetapi user yang masih ditugaskan ke store tersebut tetap berjalan normal.
+	IsActive bool
+}
+
+// StoreFilter membatasi query ke store tertentu. All bernilai true jika tidak ada batasan (admin).
+type StoreFilter struct {
+	All      bool
+	StoreIDs []int
+}
+
+// StoreSearch adalah filter pencarian di halaman /stores. Status "active", "inactive", atau
+// kosong untuk semua store.
+type StoreSearch struct {
+	Query  string
+	Status string
+}
+
+// StoreInput menampung data dari form create/edit store. ID 0 berarti store baru.
+type StoreInput struct {
+	ID      int
+	Code    string
+	Name    string
+	Address string
 }
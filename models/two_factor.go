@@ -0,0 +1,18 @@
+package models
+
+import "html/template"
+
+// TwoFactorState menyimpan status 2FA milik user.
+type TwoFactorState struct {
+	Secret                 string
+	Enabled                bool
+	LastUsedStep           int64
+	RemainingRecoveryCodes int
+}
+
+// TwoFactorSetup berisi data yang ditampilkan saat user mendaftarkan aplikasi authenticator.
+type TwoFactorSetup struct {
+	Secret          string
+	ProvisioningURI string
+	QRCode          template.URL
+}
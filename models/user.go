@@ -16,6 +16,10 @@ type User struct {
 	RoleNames        []string
 	CreatedAt        string
 	CreatedAtDisplay string
+	// MustChangePassword menandai user yang wajib mengganti password saat login berikutnya.
+	MustChangePassword bool
+	// EmailVerified bernilai true jika email sudah diverifikasi lewat link registrasi.
+	EmailVerified bool
 }
 
 // UserCreateInput menampung data yang dikirimkan dari form create user.
@@ -28,6 +32,8 @@ type UserCreateInput struct {
 	Status    string
 	StoreIDs  []int
 	RoleNames []string
+
+	MustChangePassword bool
 }
 
 // UserUpdateInput menampung data yang dikirimkan dari form edit user.
@@ -41,4 +47,6 @@ type UserUpdateInput struct {
 	Status    string
 	StoreIDs  []int
 	RoleNames []string
+
+	MustChangePassword bool
 }
@@ -0,0 +1,248 @@
+// Package oidc menyediakan client OpenID Connect minimal untuk login SSO: discovery issuer,
+// authorization code flow dengan PKCE, dan verifikasi ID token (RS256/ES256) lewat JWKS issuer.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config berisi pengaturan client OIDC yang terdaftar di identity provider.
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// ProviderName ditampilkan pada tombol login, mis. "Google Workspace".
+	ProviderName string
+	HTTPClient   *http.Client
+}
+
+// Provider adalah client untuk satu issuer OIDC. Metadata discovery dan JWKS di-cache
+// setelah berhasil diambil sehingga aman dipakai bersamaan oleh banyak request.
+type Provider struct {
+	cfg Config
+
+	mu            sync.Mutex
+	meta          *providerMetadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+var defaultProvider *Provider
+
+// SetDefault mengganti provider yang dipakai aplikasi; nil berarti login SSO dimatikan.
+func SetDefault(p *Provider) {
+	defaultProvider = p
+}
+
+// Default mengembalikan provider aktif, atau nil jika SSO tidak dikonfigurasi.
+func Default() *Provider {
+	return defaultProvider
+}
+
+// New membuat provider dari konfigurasi. Scope "openid" selalu ditambahkan.
+func New(cfg Config) *Provider {
+	cfg.IssuerURL = strings.TrimRight(strings.TrimSpace(cfg.IssuerURL), "/")
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.ProviderName == "" {
+		cfg.ProviderName = "SSO"
+	}
+
+	hasOpenID := false
+	for _, scope := range cfg.Scopes {
+		if scope == "openid" {
+			hasOpenID = true
+		}
+	}
+	if !hasOpenID {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+
+	return &Provider{cfg: cfg}
+}
+
+// FromEnv membuat provider dari OIDC_ISSUER_URL, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET,
+// OIDC_REDIRECT_URL (default BASE_URL + /login/oidc/callback), OIDC_SCOPES (dipisah koma,
+// default openid,email,profile), dan OIDC_PROVIDER_NAME. Mengembalikan nil jika issuer atau
+// client id tidak diisi.
+func FromEnv() *Provider {
+	issuer := strings.TrimSpace(os.Getenv("OIDC_ISSUER_URL"))
+	clientID := strings.TrimSpace(os.Getenv("OIDC_CLIENT_ID"))
+	if issuer == "" || clientID == "" {
+		return nil
+	}
+
+	redirectURL := strings.TrimSpace(os.Getenv("OIDC_REDIRECT_URL"))
+	if redirectURL == "" {
+		redirectURL = strings.TrimRight(os.Getenv("BASE_URL"), "/") + "/login/oidc/callback"
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(os.Getenv("OIDC_SCOPES"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	return New(Config{
+		IssuerURL:    issuer,
+		ClientID:     clientID,
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+		ProviderName: strings.TrimSpace(os.Getenv("OIDC_PROVIDER_NAME")),
+	})
+}
+
+// Name mengembalikan nama provider untuk ditampilkan di halaman login.
+func (p *Provider) Name() string {
+	return p.cfg.ProviderName
+}
+
+// AuthCodeURL menyusun URL authorization endpoint dengan state, nonce, dan challenge PKCE (S256)
+// dari code verifier yang disimpan di session.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("authorization_endpoint tidak valid: %w", err)
+	}
+
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	authURL.RawQuery = q.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange menukar authorization code beserta code verifier ke token endpoint, lalu
+// memverifikasi ID token yang diterima terhadap nonce yang diharapkan.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghubungi token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("respons token endpoint tidak valid (HTTP %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("token endpoint menolak permintaan: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token endpoint tidak mengembalikan id_token")
+	}
+
+	return p.VerifyIDToken(ctx, token.IDToken, nonce)
+}
+
+// metadata mengambil dokumen discovery issuer sekali lalu menyimpannya.
+func (p *Provider) metadata(ctx context.Context) (*providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	var meta providerMetadata
+	if err := p.getJSON(ctx, p.cfg.IssuerURL+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, fmt.Errorf("discovery OIDC gagal: %w", err)
+	}
+	if strings.TrimRight(meta.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("issuer discovery %q tidak sama dengan OIDC_ISSUER_URL", meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("dokumen discovery OIDC tidak lengkap")
+	}
+
+	p.meta = &meta
+	return p.meta, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s mengembalikan HTTP %d", target, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(dst)
+}
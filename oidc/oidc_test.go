@@ -0,0 +1,294 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID = "client-1"
+	testNonce    = "nonce-1"
+)
+
+// mockIssuer adalah identity provider OIDC palsu: discovery, JWKS, dan token endpoint yang
+// memeriksa PKCE. Key RSA (kid "rsa-1") dan EC P-256 (kid "ec-1") dibuat per test.
+type mockIssuer struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+
+	mu        sync.Mutex
+	jwks      []jsonWebKey
+	jwksHits  int
+	tokenHits int
+	grants    map[string]mockGrant
+}
+
+// mockGrant adalah authorization code yang terikat ke code challenge PKCE tertentu.
+type mockGrant struct {
+	challenge string
+	idToken   string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockIssuer{rsaKey: rsaKey, ecKey: ecKey, grants: map[string]mockGrant{}}
+	m.jwks = []jsonWebKey{rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)}
+
+	mux := http.NewServeMux()
+	discovery := func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	}
+	mux.HandleFunc("/.well-known/openid-configuration", discovery)
+	// dokumen yang sama di path lain untuk menguji issuer discovery yang tidak cocok
+	mux.HandleFunc("/tenant-lain/.well-known/openid-configuration", discovery)
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.jwksHits++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": m.jwks})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.tokenHits++
+
+		grant, ok := m.grants[r.PostFormValue("code")]
+		if r.Method != http.MethodPost || r.PostFormValue("grant_type") != "authorization_code" ||
+			r.PostFormValue("client_id") != testClientID || !ok ||
+			CodeChallenge(r.PostFormValue("code_verifier")) != grant.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "code atau code_verifier salah"})
+			return
+		}
+		delete(m.grants, r.PostFormValue("code"))
+		json.NewEncoder(w).Encode(map[string]string{"id_token": grant.idToken, "token_type": "Bearer"})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockIssuer) provider() *Provider {
+	return New(Config{
+		IssuerURL:   m.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: "https://app.test/login/oidc/callback",
+		HTTPClient:  m.server.Client(),
+	})
+}
+
+// grant mendaftarkan authorization code untuk code verifier verifier.
+func (m *mockIssuer) grant(code, verifier, idToken string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.grants[code] = mockGrant{challenge: CodeChallenge(verifier), idToken: idToken}
+}
+
+func (m *mockIssuer) hits() (jwks, token int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jwksHits, m.tokenHits
+}
+
+// claims mengembalikan claim ID token yang valid untuk provider dari mockIssuer.
+func (m *mockIssuer) claims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":            m.server.URL,
+		"sub":            "user-1",
+		"aud":            testClientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          testNonce,
+		"email":          "alice@kampus.local",
+		"email_verified": true,
+		"groups":         []string{"gudang"},
+	}
+}
+
+func (m *mockIssuer) signRS256(t *testing.T, kid string, claims map[string]interface{}) string {
+	return signToken(t, map[string]string{"alg": "RS256", "kid": kid}, claims, func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, m.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	})
+}
+
+func (m *mockIssuer) signES256(t *testing.T, kid string, claims map[string]interface{}) string {
+	return signToken(t, map[string]string{"alg": "ES256", "kid": kid}, claims, func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, m.ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig
+	})
+}
+
+// signHS256 menandatangani token dengan HMAC memakai modulus public key RSA issuer sebagai
+// secret, bentuk serangan key confusion yang harus ditolak.
+func (m *mockIssuer) signHS256(t *testing.T, kid string, claims map[string]interface{}) string {
+	return signToken(t, map[string]string{"alg": "HS256", "kid": kid}, claims, func(input []byte) []byte {
+		mac := hmac.New(sha256.New, m.rsaKey.PublicKey.N.Bytes())
+		mac.Write(input)
+		return mac.Sum(nil)
+	})
+}
+
+func signToken(t *testing.T, header map[string]string, claims map[string]interface{}, sign func([]byte) []byte) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(p)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func rsaJWK(kid string, pub *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, pub *ecdsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func TestAuthCodeURLUsesPKCE(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider()
+
+	raw, err := p.AuthCodeURL(context.Background(), "state-1", testNonce, "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(raw, m.server.URL+"/authorize?") {
+		t.Errorf("AuthCodeURL = %s, want authorization_endpoint issuer", raw)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"state":                 "state-1",
+		"nonce":                 testNonce,
+		"code_challenge":        CodeChallenge("verifier-1"),
+		"code_challenge_method": "S256",
+		"scope":                 "openid",
+	}
+	for key, val := range want {
+		if got := u.Query().Get(key); got != val {
+			t.Errorf("%s = %q, want %q", key, got, val)
+		}
+	}
+	if u.Query().Get("code_challenge") == "verifier-1" {
+		t.Error("code verifier tidak boleh dikirim ke authorization endpoint")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		verifier string
+		wantErr  bool
+	}{
+		{name: "code dan verifier cocok", code: "code-1", verifier: "verifier-1"},
+		{name: "verifier PKCE berbeda", code: "code-1", verifier: "verifier-lain", wantErr: true},
+		{name: "code tidak dikenal", code: "code-lain", verifier: "verifier-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockIssuer(t)
+			m.grant("code-1", "verifier-1", m.signRS256(t, "rsa-1", m.claims()))
+
+			claims, err := m.provider().Exchange(context.Background(), tt.code, tt.verifier, testNonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Exchange berhasil, want error")
+				}
+				if errors.Is(err, ErrInvalidIDToken) {
+					t.Errorf("err = %v, penolakan token endpoint bukan error ID token", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Subject != "user-1" || claims.Email != "alice@kampus.local" || !claims.EmailVerified {
+				t.Errorf("claims = %+v", claims)
+			}
+			if got := claims.Strings("groups"); len(got) != 1 || got[0] != "gudang" {
+				t.Errorf("groups = %v, want [gudang]", got)
+			}
+		})
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	m := newMockIssuer(t)
+	p := New(Config{IssuerURL: m.server.URL + "/tenant-lain", ClientID: testClientID, HTTPClient: m.server.Client()})
+
+	if _, err := p.AuthCodeURL(context.Background(), "state-1", testNonce, "verifier-1"); err == nil {
+		t.Fatal("AuthCodeURL berhasil dengan issuer discovery yang berbeda")
+	}
+}
@@ -0,0 +1,341 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockSkew adalah toleransi selisih jam antara aplikasi dan identity provider.
+const clockSkew = time.Minute
+
+// jwksRefreshInterval membatasi pengambilan ulang JWKS saat token memakai kid yang belum dikenal.
+const jwksRefreshInterval = time.Minute
+
+// ErrInvalidIDToken dikembalikan jika ID token gagal diverifikasi.
+var ErrInvalidIDToken = errors.New("id token tidak valid")
+
+// Claims berisi claim ID token yang sudah terverifikasi. Raw menyimpan seluruh claim
+// sehingga claim tambahan (mis. NIP atau groups) bisa dibaca lewat String/Strings.
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Raw               map[string]interface{}
+}
+
+// String membaca claim bertipe string atau angka; kosong jika tidak ada.
+func (c *Claims) String(name string) string {
+	switch v := c.Raw[name].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// Strings membaca claim berupa array string (mis. groups); nilai tunggal dianggap array satu elemen.
+func (c *Claims) Strings(name string) []string {
+	var result []string
+	switch v := c.Raw[name].(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				result = append(result, strings.TrimSpace(s))
+			}
+		}
+	}
+	return result
+}
+
+// VerifyIDToken memverifikasi tanda tangan ID token dengan JWKS issuer, lalu memeriksa
+// iss, aud, azp, exp, iat, dan nonce.
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: format JWT salah", ErrInvalidIDToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header tidak valid", ErrInvalidIDToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature tidak valid", ErrInvalidIDToken)
+	}
+
+	key, err := p.signingKey(ctx, meta.JWKSURI, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	raws := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &raws); err != nil {
+		return nil, fmt.Errorf("%w: payload tidak valid", ErrInvalidIDToken)
+	}
+	claims := &Claims{Raw: raws}
+	claims.Issuer = claims.String("iss")
+	claims.Subject = claims.String("sub")
+	claims.Email = claims.String("email")
+	claims.EmailVerified = claims.String("email_verified") == "true"
+	claims.Name = claims.String("name")
+	claims.PreferredUsername = claims.String("preferred_username")
+
+	if claims.Issuer != meta.Issuer {
+		return nil, fmt.Errorf("%w: issuer tidak sesuai", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: claim sub kosong", ErrInvalidIDToken)
+	}
+
+	audiences := claims.Strings("aud")
+	if !containsString(audiences, p.cfg.ClientID) {
+		return nil, fmt.Errorf("%w: audience tidak sesuai", ErrInvalidIDToken)
+	}
+	if azp := claims.String("azp"); azp != "" && azp != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp tidak sesuai", ErrInvalidIDToken)
+	}
+
+	now := time.Now()
+	exp, ok := numericClaim(raws["exp"])
+	if !ok || now.After(time.Unix(exp, 0).Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: token sudah kedaluwarsa", ErrInvalidIDToken)
+	}
+	if iat, ok := numericClaim(raws["iat"]); ok && time.Unix(iat, 0).After(now.Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: iat di masa depan", ErrInvalidIDToken)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.String("nonce")), []byte(nonce)) != 1 || nonce == "" {
+		return nil, fmt.Errorf("%w: nonce tidak sesuai", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+// signingKey mencari public key berdasarkan kid. JWKS diambil ulang (dibatasi interval)
+// jika kid belum dikenal, agar rotasi key di identity provider langsung terbaca.
+func (p *Provider) signingKey(ctx context.Context, jwksURI, kid, alg string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := pickKey(p.keys, kid, alg); key != nil {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("%w: key %q tidak ditemukan", ErrInvalidIDToken, kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("gagal mengambil JWKS: %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		id := jwk.Kid
+		if id == "" {
+			id = "#" + strconv.Itoa(i)
+		}
+		keys[id] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key := pickKey(p.keys, kid, alg); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: key %q tidak ditemukan", ErrInvalidIDToken, kid)
+}
+
+// pickKey memilih key berdasarkan kid; token tanpa kid hanya diterima jika JWKS berisi
+// tepat satu key yang cocok dengan algoritmanya.
+func pickKey(keys map[string]interface{}, kid, alg string) interface{} {
+	if kid != "" {
+		return keys[kid]
+	}
+
+	var found interface{}
+	for _, key := range keys {
+		if keyMatchesAlg(key, alg) {
+			if found != nil {
+				return nil
+			}
+			found = key
+		}
+	}
+	return found
+}
+
+func keyMatchesAlg(key interface{}, alg string) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return alg == "RS256"
+	case *ecdsa.PublicKey:
+		return alg == "ES256"
+	}
+	return false
+}
+
+// verifySignature hanya menerima RS256 dan ES256; "none" dan algoritma HMAC selalu ditolak.
+func verifySignature(alg string, key interface{}, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key tidak cocok dengan algoritma", ErrInvalidIDToken)
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: signature salah", ErrInvalidIDToken)
+		}
+		return nil
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return fmt.Errorf("%w: key tidak cocok dengan algoritma", ErrInvalidIDToken)
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return fmt.Errorf("%w: signature salah", ErrInvalidIDToken)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: algoritma %q tidak didukung", ErrInvalidIDToken, alg)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("eksponen RSA tidak valid")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("kurva %q tidak didukung", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !pub.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("titik EC tidak valid")
+		}
+		return pub, nil
+	}
+	return nil, fmt.Errorf("jenis key %q tidak didukung", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("nilai key tidak valid")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func decodeSegment(segment string, dst interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(dst)
+}
+
+func numericClaim(v interface{}) (int64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if i, err := n.Int64(); err == nil {
+		return i, true
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
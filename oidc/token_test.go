@@ -0,0 +1,325 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyIDToken(t *testing.T) {
+	m := newMockIssuer(t)
+
+	with := func(changes map[string]interface{}) map[string]interface{} {
+		claims := m.claims()
+		for key, val := range changes {
+			if val == nil {
+				delete(claims, key)
+				continue
+			}
+			claims[key] = val
+		}
+		return claims
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		nonce   string
+		wantErr bool
+	}{
+		{
+			name:  "RS256 valid",
+			token: func(t *testing.T) string { return m.signRS256(t, "rsa-1", m.claims()) },
+		},
+		{
+			name:  "ES256 valid",
+			token: func(t *testing.T) string { return m.signES256(t, "ec-1", m.claims()) },
+		},
+		{
+			name: "aud berupa array yang memuat client id",
+			token: func(t *testing.T) string {
+				return m.signRS256(t, "rsa-1", with(map[string]interface{}{"aud": []string{"client-lain", testClientID}, "azp": testClientID}))
+			},
+		},
+		{
+			name: "exp baru lewat masih dalam toleransi jam",
+			token: func(t *testing.T) string {
+				return m.signRS256(t, "rsa-1", with(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}))
+			},
+		},
+		{
+			name: "alg none",
+			token: func(t *testing.T) string {
+				return signToken(t, map[string]string{"alg": "none", "kid": "rsa-1"}, m.claims(), func([]byte) []byte { return nil })
+			},
+			wantErr: true,
+		},
+		{
+			name:    "HS256 dengan public key RSA sebagai secret",
+			token:   func(t *testing.T) string { return m.signHS256(t, "rsa-1", m.claims()) },
+			wantErr: true,
+		},
+		{
+			name:    "RS256 dengan kid milik key EC",
+			token:   func(t *testing.T) string { return m.signRS256(t, "ec-1", m.claims()) },
+			wantErr: true,
+		},
+		{
+			name:    "kid tidak dikenal",
+			token:   func(t *testing.T) string { return m.signRS256(t, "rsa-lain", m.claims()) },
+			wantErr: true,
+		},
+		{
+			name: "signature diubah",
+			token: func(t *testing.T) string {
+				raw := m.signRS256(t, "rsa-1", m.claims())
+				parts := strings.Split(raw, ".")
+				sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+				sig[0] ^= 0xff
+				return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(sig)
+			},
+			wantErr: true,
+		},
+		{
+			name: "payload diganti setelah ditandatangani",
+			token: func(t *testing.T) string {
+				raw := m.signES256(t, "ec-1", m.claims())
+				forged := signToken(t, map[string]string{"alg": "ES256", "kid": "ec-1"}, with(map[string]interface{}{"sub": "admin"}), func([]byte) []byte { return nil })
+				return strings.Join(strings.Split(forged, ".")[:2], ".") + "." + strings.Split(raw, ".")[2]
+			},
+			wantErr: true,
+		},
+		{
+			name:    "iss berbeda",
+			token:   func(t *testing.T) string { return m.signRS256(t, "rsa-1", with(map[string]interface{}{"iss": "https://issuer-lain.test"})) },
+			wantErr: true,
+		},
+		{
+			name:    "aud berbeda",
+			token:   func(t *testing.T) string { return m.signRS256(t, "rsa-1", with(map[string]interface{}{"aud": "client-lain"})) },
+			wantErr: true,
+		},
+		{
+			name:    "azp berbeda",
+			token:   func(t *testing.T) string { return m.signRS256(t, "rsa-1", with(map[string]interface{}{"azp": "client-lain"})) },
+			wantErr: true,
+		},
+		{
+			name:    "nonce berbeda",
+			token:   func(t *testing.T) string { return m.signRS256(t, "rsa-1", with(map[string]interface{}{"nonce": "nonce-lain"})) },
+			wantErr: true,
+		},
+		{
+			name: "nonce session kosong",
+			token: func(t *testing.T) string {
+				return m.signRS256(t, "rsa-1", with(map[string]interface{}{"nonce": nil}))
+			},
+			nonce:   "-",
+			wantErr: true,
+		},
+		{
+			name: "exp sudah lewat",
+			token: func(t *testing.T) string {
+				return m.signRS256(t, "rsa-1", with(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()}))
+			},
+			wantErr: true,
+		},
+		{
+			name:    "tanpa exp",
+			token:   func(t *testing.T) string { return m.signRS256(t, "rsa-1", with(map[string]interface{}{"exp": nil})) },
+			wantErr: true,
+		},
+		{
+			name: "iat di masa depan",
+			token: func(t *testing.T) string {
+				return m.signRS256(t, "rsa-1", with(map[string]interface{}{"iat": now.Add(5 * time.Minute).Unix()}))
+			},
+			wantErr: true,
+		},
+		{
+			name:    "sub kosong",
+			token:   func(t *testing.T) string { return m.signRS256(t, "rsa-1", with(map[string]interface{}{"sub": nil})) },
+			wantErr: true,
+		},
+		{
+			name:    "bukan JWT",
+			token:   func(t *testing.T) string { return "bukan.jwt" },
+			wantErr: true,
+		},
+	}
+
+	p := m.provider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nonce := testNonce
+			if tt.nonce == "-" {
+				nonce = ""
+			} else if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			claims, err := p.VerifyIDToken(context.Background(), tt.token(t), nonce)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidIDToken) {
+					t.Fatalf("err = %v, want ErrInvalidIDToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Issuer != m.server.URL || claims.Subject != "user-1" {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestSigningKeyRefreshIsRateLimited(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider()
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, m.signRS256(t, "rsa-1", m.claims()), testNonce); err != nil {
+		t.Fatal(err)
+	}
+	if jwks, _ := m.hits(); jwks != 1 {
+		t.Fatalf("JWKS diambil %d kali, want 1", jwks)
+	}
+
+	// issuer merotasi key: kid baru belum dikenal dan JWKS belum boleh diambil ulang
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	m.jwks = append(m.jwks, rsaJWK("rsa-2", &rotated.PublicKey))
+	m.mu.Unlock()
+	m.rsaKey = rotated
+
+	token := m.signRS256(t, "rsa-2", m.claims())
+	if _, err := p.VerifyIDToken(ctx, token, testNonce); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("err = %v, want ErrInvalidIDToken sebelum interval refresh lewat", err)
+	}
+	if jwks, _ := m.hits(); jwks != 1 {
+		t.Fatalf("JWKS diambil %d kali dalam interval refresh, want 1", jwks)
+	}
+
+	// setelah interval lewat, kid yang belum dikenal memicu satu kali pengambilan ulang
+	p.mu.Lock()
+	p.keysFetchedAt = time.Now().Add(-jwksRefreshInterval - time.Second)
+	p.mu.Unlock()
+	if _, err := p.VerifyIDToken(ctx, token, testNonce); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.VerifyIDToken(ctx, m.signRS256(t, "rsa-3", m.claims()), testNonce); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("err = %v, want ErrInvalidIDToken untuk kid yang tetap tidak ada", err)
+	}
+	if jwks, _ := m.hits(); jwks != 2 {
+		t.Fatalf("JWKS diambil %d kali, want 2", jwks)
+	}
+}
+
+func TestPickKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPub, ecPub := &rsaKey.PublicKey, &ecKey.PublicKey
+
+	tests := []struct {
+		name string
+		keys map[string]interface{}
+		kid  string
+		alg  string
+		want interface{}
+	}{
+		{name: "kid cocok", keys: map[string]interface{}{"a": rsaPub, "b": ecPub}, kid: "b", alg: "ES256", want: ecPub},
+		{name: "kid tidak ada", keys: map[string]interface{}{"a": rsaPub}, kid: "x", alg: "RS256", want: nil},
+		{name: "tanpa kid dengan satu key yang cocok", keys: map[string]interface{}{"a": rsaPub, "b": ecPub}, alg: "RS256", want: rsaPub},
+		{name: "tanpa kid dengan dua key yang cocok", keys: map[string]interface{}{"a": rsaPub, "b": &rsa.PublicKey{N: rsaPub.N, E: rsaPub.E}}, alg: "RS256", want: nil},
+		{name: "tanpa kid dan algoritma tidak cocok", keys: map[string]interface{}{"a": rsaPub}, alg: "ES256", want: nil},
+		{name: "tanpa kid dengan alg none", keys: map[string]interface{}{"a": rsaPub}, alg: "none", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickKey(tt.keys, tt.kid, tt.alg); got != tt.want {
+				t.Errorf("pickKey = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONWebKeyPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weakExponent := rsaJWK("a", &rsaKey.PublicKey)
+	weakExponent.E = base64.RawURLEncoding.EncodeToString([]byte{1})
+	otherCurve := ecJWK("b", &ecKey.PublicKey)
+	otherCurve.Crv = "P-384"
+	offCurve := ecJWK("c", &ecKey.PublicKey)
+	offCurve.Y = offCurve.X
+	badBase64 := rsaJWK("d", &rsaKey.PublicKey)
+	badBase64.N = "***"
+
+	tests := []struct {
+		name    string
+		jwk     jsonWebKey
+		wantErr bool
+	}{
+		{name: "RSA", jwk: rsaJWK("rsa", &rsaKey.PublicKey)},
+		{name: "EC P-256", jwk: ecJWK("ec", &ecKey.PublicKey)},
+		{name: "eksponen RSA 1", jwk: weakExponent, wantErr: true},
+		{name: "kurva selain P-256", jwk: otherCurve, wantErr: true},
+		{name: "titik di luar kurva", jwk: offCurve, wantErr: true},
+		{name: "modulus bukan base64url", jwk: badBase64, wantErr: true},
+		{name: "key simetris", jwk: jsonWebKey{Kty: "oct", Kid: "e"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.jwk.publicKey()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("publicKey = %v, want error", key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch pub := key.(type) {
+			case *rsa.PublicKey:
+				if pub.N.Cmp(rsaKey.N) != 0 || pub.E != rsaKey.E {
+					t.Error("public key RSA tidak sama")
+				}
+			case *ecdsa.PublicKey:
+				if !pub.Equal(&ecKey.PublicKey) {
+					t.Error("public key EC tidak sama")
+				}
+			default:
+				t.Errorf("jenis key %T", key)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package permissions berisi registry permission yang dideklarasikan di kode. Setiap permission
+// yang dicek aplikasi wajib didaftarkan di sini (lihat routes/permissions.go) sehingga salah
+// ketik nama permission langsung ketahuan saat aplikasi start, dan tabel permissions bisa
+// disinkronkan otomatis.
+package permissions
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Definition adalah satu permission yang dideklarasikan di kode. Group dan Description hanya
+// dipakai saat permission pertama kali dibuat di database; setelah itu bisa diubah dari halaman
+// /permissions.
+type Definition struct {
+	Name        string
+	Group       string
+	Description string
+}
+
+var registry = struct {
+	sync.RWMutex
+	defs map[string]Definition
+}{defs: make(map[string]Definition)}
+
+// Register mendaftarkan permission. Nama kosong atau nama yang didaftarkan dua kali membuat panic
+// karena berarti ada kesalahan di kode.
+func Register(defs ...Definition) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, def := range defs {
+		if def.Name == "" {
+			panic("permissions: nama permission kosong")
+		}
+		if _, exists := registry.defs[def.Name]; exists {
+			panic(fmt.Sprintf("permissions: permission %q didaftarkan dua kali", def.Name))
+		}
+		registry.defs[def.Name] = def
+	}
+}
+
+// Registered mengecek apakah permission sudah dideklarasikan.
+func Registered(name string) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	_, ok := registry.defs[name]
+	return ok
+}
+
+// MustRegistered membuat panic jika salah satu permission belum dideklarasikan. Dipanggil saat
+// route didaftarkan agar salah ketik nama permission menggagalkan start aplikasi.
+func MustRegistered(names ...string) {
+	for _, name := range names {
+		if !Registered(name) {
+			panic(fmt.Sprintf("permissions: permission %q belum dideklarasikan, tambahkan di routes/permissions.go", name))
+		}
+	}
+}
+
+// All mengembalikan seluruh permission terdaftar, diurutkan berdasarkan group lalu nama.
+func All() []Definition {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	result := make([]Definition, 0, len(registry.defs))
+	for _, def := range registry.defs {
+		result = append(result, def)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Group != result[j].Group {
+			return result[i].Group < result[j].Group
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
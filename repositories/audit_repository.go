@@ -0,0 +1,406 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"gobase-app/models"
+	"strings"
+	"time"
+)
+
+type AuditRepository struct {
+	DB *sql.DB
+}
+
+// sensitiveAuditKeys tidak pernah disimpan nilainya di audit log, hanya ditandai berubah.
+var sensitiveAuditKeys = map[string]bool{"password": true}
+
+const auditHiddenValue = "[hidden]"
+
+// auditQueryer dipenuhi *sql.DB maupun *sql.Tx sehingga snapshot bisa dibaca di dalam transaksi.
+type auditQueryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// insertAuditLog mencatat perubahan di dalam transaksi yang sama dengan perubahannya.
+// before nil berarti data baru dibuat, after nil berarti data dihapus.
+// Update tanpa perubahan field apa pun tidak dicatat.
+func insertAuditLog(tx *sql.Tx, actor models.AuditActor, action, entityType string, entityID int64, before, after map[string]interface{}) error {
+	oldValues, newValues := auditDiff(before, after)
+	if before != nil && after != nil && oldValues == nil {
+		return nil
+	}
+
+	var actorID interface{}
+	if actor.UserID > 0 {
+		actorID = actor.UserID
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO audit_logs (actor_id, actor_name, action, entity_type, entity_id, old_values, new_values, ip_address, user_agent)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, actorID, actor.Username, action, entityType, entityID, oldValues, newValues, actor.IPAddress, actor.UserAgent)
+	return err
+}
+
+// auditDiff mengembalikan JSON field lama dan baru yang berbeda. Nilai nil berarti NULL.
+func auditDiff(before, after map[string]interface{}) (interface{}, interface{}) {
+	oldDiff := make(map[string]interface{})
+	newDiff := make(map[string]interface{})
+
+	for key, val := range before {
+		if after == nil || !sameAuditValue(val, after[key]) {
+			oldDiff[key] = maskAuditValue(key, val)
+		}
+	}
+	for key, val := range after {
+		if before == nil || !sameAuditValue(before[key], val) {
+			newDiff[key] = maskAuditValue(key, val)
+		}
+	}
+
+	return auditJSON(oldDiff), auditJSON(newDiff)
+}
+
+func sameAuditValue(a, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func maskAuditValue(key string, val interface{}) interface{} {
+	if sensitiveAuditKeys[key] {
+		return auditHiddenValue
+	}
+	return val
+}
+
+func auditJSON(values map[string]interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	return string(b)
+}
+
+// userAuditSnapshot membaca kondisi user (termasuk role dan permission langsung) untuk dibandingkan
+// di audit log. Permission langsung ditulis "+nama" untuk grant dan "-nama" untuk deny, permission
+// per store ditulis "nama@KODE_STORE".
+func userAuditSnapshot(q auditQueryer, id int64) (map[string]interface{}, error) {
+	var (
+		nip           int
+		username      string
+		name          string
+		email         sql.NullString
+		status        string
+		mustChange    bool
+		emailVerified bool
+		password      string
+	)
+	err := q.QueryRow(`
+		SELECT nip, username, name, email, status, must_change_password, email_verified_at IS NOT NULL, password
+		FROM users
+		WHERE id = ?
+	`, id).Scan(&nip, &username, &name, &email, &status, &mustChange, &emailVerified, &password)
+	if err != nil {
+		return nil, err
+	}
+
+	storeIDs, err := userStoreIDs(q, id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := auditStrings(q, `
+		SELECT r.name
+		FROM model_has_roles mhr
+		JOIN roles r ON r.id = mhr.role_id
+		WHERE mhr.model_id = ? AND mhr.model_type = ?
+		ORDER BY r.name
+	`, id, userModelType)
+	if err != nil {
+		return nil, err
+	}
+
+	directPermissions, err := auditStrings(q, `
+		SELECT CONCAT(IF(mhp.is_denied = 1, '-', '+'), p.name)
+		FROM model_has_permissions mhp
+		JOIN permissions p ON p.id = mhp.permission_id
+		WHERE mhp.model_id = ? AND mhp.model_type = ?
+		ORDER BY p.name
+	`, id, userModelType)
+	if err != nil {
+		return nil, err
+	}
+
+	storePermissions, err := auditStrings(q, `
+		SELECT CONCAT(p.name, '@', s.store_code)
+		FROM model_has_store_permissions mhsp
+		JOIN permissions p ON p.id = mhsp.permission_id
+		JOIN stores s ON s.store_id = mhsp.store_id
+		WHERE mhsp.model_id = ? AND mhsp.model_type = ?
+		ORDER BY p.name, s.store_code
+	`, id, userModelType)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"nip":                  nip,
+		"username":             username,
+		"name":                 name,
+		"email":                email.String,
+		"status":               status,
+		"store_ids":            storeIDs,
+		"roles":                roles,
+		"direct_permissions":   directPermissions,
+		"store_permissions":    storePermissions,
+		"must_change_password": mustChange,
+		"email_verified":       emailVerified,
+		"password":             password,
+	}, nil
+}
+
+// roleAuditSnapshot membaca kondisi role beserta daftar permission dan parent untuk audit log.
+func roleAuditSnapshot(q auditQueryer, id int64) (map[string]interface{}, error) {
+	var (
+		name              string
+		guardName         string
+		isAdmin           bool
+		requiresTwoFactor bool
+	)
+	err := q.QueryRow(`
+		SELECT name, guard_name, is_admin, requires_two_factor
+		FROM roles
+		WHERE id = ?
+	`, id).Scan(&name, &guardName, &isAdmin, &requiresTwoFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := auditStrings(q, `
+		SELECT p.name
+		FROM role_has_permissions rhp
+		JOIN permissions p ON p.id = rhp.permission_id
+		WHERE rhp.role_id = ?
+		ORDER BY p.name
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parents, err := auditStrings(q, `
+		SELECT r.name
+		FROM role_parents rp
+		JOIN roles r ON r.id = rp.parent_id
+		WHERE rp.role_id = ?
+		ORDER BY r.name
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"name":                name,
+		"guard_name":          guardName,
+		"is_admin":            isAdmin,
+		"requires_two_factor": requiresTwoFactor,
+		"permissions":         permissions,
+		"parents":             parents,
+	}, nil
+}
+
+// storeAuditSnapshot membaca kondisi store untuk audit log.
+func storeAuditSnapshot(q auditQueryer, id int64) (map[string]interface{}, error) {
+	var (
+		code     string
+		name     string
+		address  string
+		isActive bool
+	)
+	err := q.QueryRow(`
+		SELECT store_code, store_name, store_address, is_active
+		FROM stores
+		WHERE store_id = ?
+	`, id).Scan(&code, &name, &address, &isActive)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"store_code":    code,
+		"store_name":    name,
+		"store_address": address,
+		"is_active":     isActive,
+	}, nil
+}
+
+// itemAuditSnapshot membaca kondisi item hadiah untuk audit log.
+func itemAuditSnapshot(q auditQueryer, id int64) (map[string]interface{}, error) {
+	var (
+		sku       string
+		name      string
+		category  string
+		unit      string
+		imagePath sql.NullString
+		valueType string
+		value     int64
+		isActive  bool
+	)
+	err := q.QueryRow(`
+		SELECT sku, name, category, unit, image_path, value_type, value, is_active
+		FROM items
+		WHERE id = ?
+	`, id).Scan(&sku, &name, &category, &unit, &imagePath, &valueType, &value, &isActive)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"sku":        sku,
+		"name":       name,
+		"category":   category,
+		"unit":       unit,
+		"image_path": imagePath.String,
+		"value_type": valueType,
+		"value":      value,
+		"is_active":  isActive,
+	}, nil
+}
+
+// permissionAuditSnapshot membaca kondisi permission untuk audit log.
+func permissionAuditSnapshot(q auditQueryer, id int64) (map[string]interface{}, error) {
+	var (
+		name        string
+		group       sql.NullString
+		description sql.NullString
+		guardName   string
+	)
+	err := q.QueryRow(`
+		SELECT name, `+"`group`"+`, description, guard_name
+		FROM permissions
+		WHERE id = ?
+	`, id).Scan(&name, &group, &description, &guardName)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"name":        name,
+		"group":       group.String,
+		"description": description.String,
+		"guard_name":  guardName,
+	}, nil
+}
+
+func auditStrings(q auditQueryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []string{}
+	for rows.Next() {
+		var val string
+		if err := rows.Scan(&val); err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+	return result, rows.Err()
+}
+
+// Search mengambil audit log sesuai filter, diurutkan dari yang terbaru.
+// PerPage <= 0 berarti seluruh baris (dipakai untuk export CSV).
+func (r *AuditRepository) Search(filter models.AuditFilter) ([]models.AuditLog, int, error) {
+	where, args := auditWhere(filter)
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(1) FROM audit_logs`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, COALESCE(actor_id, 0), actor_name, action, entity_type, entity_id,
+			COALESCE(old_values, ''), COALESCE(new_values, ''), ip_address, COALESCE(user_agent, ''), created_at
+		FROM audit_logs` + where + `
+		ORDER BY id DESC`
+	if filter.PerPage > 0 {
+		page := filter.Page
+		if page < 1 {
+			page = 1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.PerPage, (page-1)*filter.PerPage)
+	}
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var logs []models.AuditLog
+	for rows.Next() {
+		var (
+			l         models.AuditLog
+			createdAt time.Time
+		)
+		if err := rows.Scan(&l.ID, &l.ActorID, &l.ActorName, &l.Action, &l.EntityType, &l.EntityID,
+			&l.OldValues, &l.NewValues, &l.IPAddress, &l.UserAgent, &createdAt); err != nil {
+			return nil, 0, err
+		}
+		l.CreatedAt = createdAt.Format("2006-01-02 15:04:05")
+		l.CreatedAtDisplay = createdAt.Format("02 Jan 2006 15:04:05")
+		logs = append(logs, l)
+	}
+
+	return logs, total, rows.Err()
+}
+
+// GetActions mengambil daftar action yang pernah tercatat untuk pilihan filter.
+func (r *AuditRepository) GetActions() ([]string, error) {
+	return auditStrings(r.DB, `SELECT DISTINCT action FROM audit_logs ORDER BY action`)
+}
+
+func auditWhere(filter models.AuditFilter) (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.Actor != "" {
+		conds = append(conds, "actor_name LIKE ?")
+		args = append(args, "%"+filter.Actor+"%")
+	}
+	if filter.Action != "" {
+		conds = append(conds, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.EntityType != "" {
+		conds = append(conds, "entity_type = ?")
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID > 0 {
+		conds = append(conds, "entity_id = ?")
+		args = append(args, filter.EntityID)
+	}
+	if filter.DateFrom != "" {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, filter.DateFrom+" 00:00:00")
+	}
+	if filter.DateTo != "" {
+		conds = append(conds, "created_at <= ?")
+		args = append(args, filter.DateTo+" 23:59:59")
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
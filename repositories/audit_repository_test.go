@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"gobase-app/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAuditDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  map[string]interface{}
+		after   map[string]interface{}
+		wantOld interface{}
+		wantNew interface{}
+	}{
+		{
+			name:    "data baru",
+			after:   map[string]interface{}{"name": "Mug", "value": 150},
+			wantNew: `{"name":"Mug","value":150}`,
+		},
+		{
+			name:    "data dihapus",
+			before:  map[string]interface{}{"name": "Mug", "is_active": true},
+			wantOld: `{"is_active":true,"name":"Mug"}`,
+		},
+		{
+			name:    "hanya field yang berubah",
+			before:  map[string]interface{}{"name": "Mug", "unit": "pcs", "value": 150},
+			after:   map[string]interface{}{"name": "Mug Kampus", "unit": "pcs", "value": 150},
+			wantOld: `{"name":"Mug"}`,
+			wantNew: `{"name":"Mug Kampus"}`,
+		},
+		{
+			name:   "tanpa perubahan",
+			before: map[string]interface{}{"roles": []string{"admin"}, "value": 150},
+			after:  map[string]interface{}{"roles": []string{"admin"}, "value": int64(150)},
+		},
+		{
+			name:    "urutan isi slice dianggap perubahan",
+			before:  map[string]interface{}{"roles": []string{"admin", "kasir"}},
+			after:   map[string]interface{}{"roles": []string{"kasir", "admin"}},
+			wantOld: `{"roles":["admin","kasir"]}`,
+			wantNew: `{"roles":["kasir","admin"]}`,
+		},
+		{
+			name:    "field baru muncul",
+			before:  map[string]interface{}{"name": "Mug"},
+			after:   map[string]interface{}{"name": "Mug", "image_path": "/uploads/items/a.png"},
+			wantOld: nil,
+			wantNew: `{"image_path":"/uploads/items/a.png"}`,
+		},
+		{
+			name:    "password hanya ditandai berubah",
+			before:  map[string]interface{}{"password": "$2a$10$lama"},
+			after:   map[string]interface{}{"password": "$2a$10$baru"},
+			wantOld: `{"password":"[hidden]"}`,
+			wantNew: `{"password":"[hidden]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOld, gotNew := auditDiff(tt.before, tt.after)
+			if gotOld != tt.wantOld {
+				t.Errorf("old = %v, want %v", gotOld, tt.wantOld)
+			}
+			if gotNew != tt.wantNew {
+				t.Errorf("new = %v, want %v", gotNew, tt.wantNew)
+			}
+		})
+	}
+}
+
+func TestInsertAuditLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		actor     models.AuditActor
+		before    map[string]interface{}
+		after     map[string]interface{}
+		wantActor interface{}
+		wantSkip  bool
+	}{
+		{
+			name:      "perubahan oleh user login",
+			actor:     models.AuditActor{UserID: 3, Username: "alice", IPAddress: "10.0.0.2", UserAgent: "curl"},
+			before:    map[string]interface{}{"name": "Mug"},
+			after:     map[string]interface{}{"name": "Mug Kampus"},
+			wantActor: 3,
+		},
+		{
+			// registrasi mandiri tidak punya actor sehingga actor_id disimpan NULL
+			name:      "tanpa login",
+			actor:     models.AuditActor{Username: "alice", IPAddress: "10.0.0.2", UserAgent: "curl"},
+			after:     map[string]interface{}{"name": "Mug Kampus"},
+			wantActor: nil,
+		},
+		{
+			name:     "update tanpa perubahan tidak dicatat",
+			actor:    models.AuditActor{UserID: 3, Username: "alice"},
+			before:   map[string]interface{}{"name": "Mug"},
+			after:    map[string]interface{}{"name": "Mug"},
+			wantSkip: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			if !tt.wantSkip {
+				oldValues, newValues := auditDiff(tt.before, tt.after)
+				mock.ExpectExec(`INSERT INTO audit_logs`).
+					WithArgs(tt.wantActor, tt.actor.Username, "item.update", "item", int64(11), oldValues, newValues, tt.actor.IPAddress, tt.actor.UserAgent).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := insertAuditLog(tx, tt.actor, "item.update", "item", 11, tt.before, tt.after); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+	"time"
+)
+
+type AuthEventRepository struct {
+	DB *sql.DB
+}
+
+// Insert mencatat satu event autentikasi. UserID 0 dicari dari username (NULL jika username tidak
+// dikenal), dan username kosong diisi dari tabel users agar riwayat tetap terbaca setelah user dihapus.
+func (r *AuthEventRepository) Insert(e models.AuthEvent) error {
+	var userID interface{}
+	if e.UserID > 0 {
+		userID = e.UserID
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO auth_events (user_id, username, event, ip_address, user_agent, created_at)
+		VALUES (
+			COALESCE(?, (SELECT id FROM users WHERE username = ? LIMIT 1)),
+			COALESCE(NULLIF(?, ''), (SELECT username FROM users WHERE id = ?), ''),
+			?, ?, ?, ?
+		)
+	`, userID, e.Username, e.Username, userID, e.Event, e.IPAddress, e.UserAgent, e.CreatedAt)
+	return err
+}
+
+// RecentByUserID mengambil event terbaru milik user, diurutkan dari yang paling baru.
+func (r *AuthEventRepository) RecentByUserID(userID, limit int) ([]models.AuthEvent, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, COALESCE(user_id, 0), username, event, ip_address, COALESCE(user_agent, ''), created_at
+		FROM auth_events
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.AuthEvent
+	for rows.Next() {
+		var e models.AuthEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Username, &e.Event, &e.IPAddress, &e.UserAgent, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ItemRepository struct {
+	DB *sql.DB
+}
+
+const itemColumns = `id, sku, name, category, unit, COALESCE(image_path, ''), value_type, value, is_active, updated_at`
+
+// Search mengambil satu halaman item sesuai filter beserta total item yang cocok.
+// PerPage <= 0 berarti seluruh baris.
+func (r *ItemRepository) Search(filter models.ItemFilter) ([]models.Item, int, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		conds = append(conds, "(sku LIKE ? OR name LIKE ?)")
+		like := "%" + q + "%"
+		args = append(args, like, like)
+	}
+	if filter.Category != "" {
+		conds = append(conds, "category = ?")
+		args = append(args, filter.Category)
+	}
+	switch filter.Status {
+	case "active":
+		conds = append(conds, "is_active = 1")
+	case "inactive":
+		conds = append(conds, "is_active = 0")
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(1) FROM items`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + itemColumns + ` FROM items` + where + ` ORDER BY is_active DESC, name ASC, id ASC`
+	if filter.PerPage > 0 {
+		page := filter.Page
+		if page < 1 {
+			page = 1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.PerPage, (page-1)*filter.PerPage)
+	}
+
+	items, err := r.queryItems(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// GetByID mengambil satu item. Mengembalikan sql.ErrNoRows jika item tidak ada.
+func (r *ItemRepository) GetByID(id int64) (*models.Item, error) {
+	items, err := r.queryItems(`SELECT `+itemColumns+` FROM items WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+// GetCategories mengambil seluruh kategori yang pernah dipakai, untuk pilihan filter dan isian form.
+func (r *ItemRepository) GetCategories() ([]string, error) {
+	return auditStrings(r.DB, `SELECT DISTINCT category FROM items ORDER BY category`)
+}
+
+// ExistsBySKUExceptID mengecek apakah SKU sudah dipakai item lain (id 0 untuk item baru).
+func (r *ItemRepository) ExistsBySKUExceptID(sku string, id int64) (bool, error) {
+	var count int
+	err := r.DB.QueryRow(`SELECT COUNT(1) FROM items WHERE sku = ? AND id <> ?`, sku, id).Scan(&count)
+	return count > 0, err
+}
+
+// Create menyimpan item baru (aktif) beserta audit log dalam satu transaksi.
+func (r *ItemRepository) Create(input models.ItemInput, imagePath string, actor models.AuditActor) (int64, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO items (sku, name, category, unit, image_path, value_type, value, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1)
+	`, input.SKU, input.Name, input.Category, input.Unit, nullableString(imagePath), input.ValueType, input.Value)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	after, err := itemAuditSnapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := insertAuditLog(tx, actor, "item.create", "item", id, nil, after); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// Update mengubah data item termasuk path gambarnya (kosong = tanpa gambar) beserta audit log.
+func (r *ItemRepository) Update(input models.ItemInput, imagePath string, actor models.AuditActor) error {
+	return r.updateItem(input.ID, "item.update", actor, `
+		UPDATE items
+		SET sku = ?, name = ?, category = ?, unit = ?, image_path = ?, value_type = ?, value = ?
+		WHERE id = ?
+	`, input.SKU, input.Name, input.Category, input.Unit, nullableString(imagePath), input.ValueType, input.Value, input.ID)
+}
+
+// SetActive mengaktifkan atau menonaktifkan item.
+func (r *ItemRepository) SetActive(id int64, active bool, actor models.AuditActor) error {
+	action := "item.deactivate"
+	if active {
+		action = "item.activate"
+	}
+	return r.updateItem(id, action, actor, `UPDATE items SET is_active = ? WHERE id = ?`, active, id)
+}
+
+func (r *ItemRepository) updateItem(id int64, action string, actor models.AuditActor, query string, args ...interface{}) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := itemAuditSnapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after, err := itemAuditSnapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, action, "item", id, before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ItemRepository) queryItems(query string, args ...interface{}) ([]models.Item, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.Item{}
+	for rows.Next() {
+		var (
+			item      models.Item
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&item.ID, &item.SKU, &item.Name, &item.Category, &item.Unit, &item.ImagePath,
+			&item.ValueType, &item.Value, &item.IsActive, &updatedAt); err != nil {
+			return nil, err
+		}
+
+		if item.ValueType == "voucher" {
+			item.ValueDisplay = "Rp " + formatThousands(item.Value)
+		} else {
+			item.ValueDisplay = formatThousands(item.Value) + " poin"
+		}
+		item.UpdatedAtDisplay = updatedAt.Format("02 Jan 2006 15:04")
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// formatThousands menulis angka dengan pemisah ribuan titik, mis. 1250000 -> "1.250.000".
+func formatThousands(val int64) string {
+	digits := strconv.FormatInt(val, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+	"strings"
+	"time"
+)
+
+type LoginThrottleRepository struct {
+	DB *sql.DB
+}
+
+// GetByKeys mengambil data throttle untuk beberapa key sekaligus.
+func (r *LoginThrottleRepository) GetByKeys(keys ...string) ([]models.LoginThrottle, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		placeholders[i] = "?"
+		args[i] = key
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT throttle_key, failures, last_failed_at, locked_until, last_ip
+		FROM login_throttles
+		WHERE throttle_key IN (`+strings.Join(placeholders, ",")+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.LoginThrottle
+	for rows.Next() {
+		var (
+			t           models.LoginThrottle
+			lockedUntil sql.NullTime
+			lastIP      sql.NullString
+		)
+		if err := rows.Scan(&t.Key, &t.Failures, &t.LastFailedAt, &lockedUntil, &lastIP); err != nil {
+			return nil, err
+		}
+		if lockedUntil.Valid {
+			t.LockedUntil = lockedUntil.Time
+		}
+		t.LastIP = lastIP.String
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+// RegisterFailure menambah counter kegagalan secara atomik dan mengembalikan jumlah terbaru.
+// Counter dimulai ulang dari 1 jika kegagalan terakhir lebih lama dari resetBefore dan key tidak terkunci.
+// ip (boleh kosong) disimpan sebagai IP terakhir yang gagal untuk key tersebut.
+func (r *LoginThrottleRepository) RegisterFailure(key, ip string, now, resetBefore time.Time) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO login_throttles (throttle_key, failures, last_failed_at, last_ip)
+		VALUES (?, 1, ?, NULLIF(?, ''))
+		ON DUPLICATE KEY UPDATE
+			failures = IF(last_failed_at < ? AND (locked_until IS NULL OR locked_until <= ?), 1, failures + 1),
+			last_failed_at = VALUES(last_failed_at),
+			last_ip = COALESCE(VALUES(last_ip), last_ip)
+	`, key, now, ip, resetBefore, now); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	var failures int
+	if err := tx.QueryRow(`SELECT failures FROM login_throttles WHERE throttle_key = ?`, key).Scan(&failures); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return failures, tx.Commit()
+}
+
+// SetLockedUntil mengatur batas waktu key tidak boleh mencoba login.
+func (r *LoginThrottleRepository) SetLockedUntil(key string, until time.Time) error {
+	_, err := r.DB.Exec(`UPDATE login_throttles SET locked_until = ? WHERE throttle_key = ?`, until, key)
+	return err
+}
+
+// Clear menghapus counter untuk key tertentu (login sukses atau unlock oleh admin).
+func (r *LoginThrottleRepository) Clear(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		placeholders[i] = "?"
+		args[i] = key
+	}
+
+	_, err := r.DB.Exec(`DELETE FROM login_throttles WHERE throttle_key IN (`+strings.Join(placeholders, ",")+`)`, args...)
+	return err
+}
+
+// GetLocked mengambil key dengan prefix tertentu yang masih terkunci dan sudah mencapai batas kegagalan.
+func (r *LoginThrottleRepository) GetLocked(prefix string, minFailures int, now time.Time) ([]models.LoginThrottle, error) {
+	rows, err := r.DB.Query(`
+		SELECT throttle_key, failures, last_failed_at, locked_until
+		FROM login_throttles
+		WHERE throttle_key LIKE ? AND failures >= ? AND locked_until > ?
+		ORDER BY last_failed_at DESC
+	`, prefix+"%", minFailures, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.LoginThrottle
+	for rows.Next() {
+		var t models.LoginThrottle
+		if err := rows.Scan(&t.Key, &t.Failures, &t.LastFailedAt, &t.LockedUntil); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+type PasswordResetRepository struct {
+	DB *sql.DB
+}
+
+// FindActiveUserByEmail mengambil id, nama, dan email user aktif berdasarkan email.
+func (r *PasswordResetRepository) FindActiveUserByEmail(email string) (int, string, string, error) {
+	var (
+		id    int
+		name  string
+		found string
+	)
+	err := r.DB.QueryRow(`
+		SELECT id, name, email
+		FROM users
+		WHERE email = ? AND status = 'active'
+	`, email).Scan(&id, &name, &found)
+	return id, name, found, err
+}
+
+// CreateToken menyimpan hash token baru dan membatalkan token lama user yang belum dipakai.
+func (r *PasswordResetRepository) CreateToken(userID int, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM password_reset_tokens WHERE user_id = ? AND used_at IS NULL`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES (?, ?, ?)
+	`, userID, tokenHash, expiresAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindValidToken mengambil id token dan user untuk token yang belum dipakai dan belum kedaluwarsa.
+func (r *PasswordResetRepository) FindValidToken(tokenHash string, now time.Time) (int64, int, error) {
+	var (
+		tokenID int64
+		userID  int
+	)
+	err := r.DB.QueryRow(`
+		SELECT t.id, t.user_id
+		FROM password_reset_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = ? AND t.used_at IS NULL AND t.expires_at > ? AND u.status = 'active'
+	`, tokenHash, now).Scan(&tokenID, &userID)
+	return tokenID, userID, err
+}
+
+// ResetPassword mengganti password dan menandai token terpakai dalam satu transaksi.
+// Token dikunci (FOR UPDATE) agar token yang sama tidak bisa dipakai dua kali secara bersamaan.
+func (r *PasswordResetRepository) ResetPassword(tokenID int64, userID int, hashedPassword string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	var usedAt sql.NullTime
+	if err := tx.QueryRow(`SELECT used_at FROM password_reset_tokens WHERE id = ? FOR UPDATE`, tokenID).Scan(&usedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if usedAt.Valid {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET password = ?, must_change_password = 0 WHERE id = ?`, hashedPassword, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertPasswordHistory(tx, int64(userID), hashedPassword); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = ? AND used_at IS NULL`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+	"strings"
+)
+
+// GetByID mengambil satu permission. Mengembalikan sql.ErrNoRows jika tidak ada.
+func (r *PermissionRepository) GetByID(id int64) (*models.Permission, error) {
+	var perm models.Permission
+	err := r.DB.QueryRow(`
+		SELECT id, name, COALESCE(`+"`group`"+`, ''), guard_name, COALESCE(description, '')
+		FROM permissions
+		WHERE id = ?
+	`, id).Scan(&perm.ID, &perm.Name, &perm.GroupName, &perm.GuardName, &perm.Description)
+	if err != nil {
+		return nil, err
+	}
+	return &perm, nil
+}
+
+// GetAll mengambil seluruh permission, diurutkan berdasarkan group lalu nama.
+func (r *PermissionRepository) GetAll() ([]models.Permission, error) {
+	groups, err := r.GetGrouped()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.Permission
+	for _, group := range groups {
+		result = append(result, group.Permissions...)
+	}
+	return result, nil
+}
+
+// Create menambahkan permission baru beserta audit log dalam satu transaksi.
+func (r *PermissionRepository) Create(perm models.Permission, actor models.AuditActor) (int64, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO permissions (name, `+"`group`"+`, description, guard_name, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
+	`, perm.Name, nullableString(perm.GroupName), nullableString(perm.Description), perm.GuardName)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	after, err := permissionAuditSnapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := insertAuditLog(tx, actor, "permission.create", "permission", id, nil, after); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// UpdateDetails mengubah group dan deskripsi permission. Nama permission tidak bisa diubah karena
+// dipakai langsung di kode.
+func (r *PermissionRepository) UpdateDetails(id int64, group, description string, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := updatePermissionDetails(tx, id, group, description, actor); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RenameGroup memindahkan seluruh permission dari group from ke group to. Setiap permission
+// yang berubah dicatat di audit log. Mengembalikan jumlah permission yang dipindahkan.
+func (r *PermissionRepository) RenameGroup(from, to string, actor models.AuditActor) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(description, '')
+		FROM permissions
+		WHERE COALESCE(`+"`group`"+`, '') = ?
+	`, from)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	type item struct {
+		id          int64
+		description string
+	}
+	var items []item
+	for rows.Next() {
+		var it item
+		if err := rows.Scan(&it.id, &it.description); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return 0, err
+		}
+		items = append(items, it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for _, it := range items {
+		if err := updatePermissionDetails(tx, it.id, to, it.description, actor); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	return len(items), tx.Commit()
+}
+
+// DeleteByID menghapus permission beserta relasinya ke role dan user dalam satu transaksi.
+func (r *PermissionRepository) DeleteByID(id int64, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := permissionAuditSnapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_has_permissions WHERE permission_id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM model_has_permissions WHERE permission_id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM model_has_store_permissions WHERE permission_id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM permissions WHERE id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertAuditLog(tx, actor, "permission.delete", "permission", id, before, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func updatePermissionDetails(tx *sql.Tx, id int64, group, description string, actor models.AuditActor) error {
+	before, err := permissionAuditSnapshot(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE permissions
+		SET `+"`group`"+` = ?, description = ?, updated_at = NOW()
+		WHERE id = ?
+	`, nullableString(group), nullableString(description), id); err != nil {
+		return err
+	}
+
+	after, err := permissionAuditSnapshot(tx, id)
+	if err != nil {
+		return err
+	}
+	return insertAuditLog(tx, actor, "permission.update", "permission", id, before, after)
+}
+
+func nullableString(val string) interface{} {
+	if strings.TrimSpace(val) == "" {
+		return nil
+	}
+	return val
+}
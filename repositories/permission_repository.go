@@ -17,7 +17,8 @@ func (r *PermissionRepository) GetGrouped() ([]models.PermissionGroup, error) {
 			id,
 			name,
 			COALESCE(` + "`group`" + `, '') AS group_name,
-			guard_name
+			guard_name,
+			COALESCE(description, '')
 		FROM permissions
 		ORDER BY group_name, name
 	`)
@@ -31,7 +32,7 @@ func (r *PermissionRepository) GetGrouped() ([]models.PermissionGroup, error) {
 
 	for rows.Next() {
 		var perm models.Permission
-		if err := rows.Scan(&perm.ID, &perm.Name, &perm.GroupName, &perm.GuardName); err != nil {
+		if err := rows.Scan(&perm.ID, &perm.Name, &perm.GroupName, &perm.GuardName, &perm.Description); err != nil {
 			return nil, err
 		}
 
@@ -69,3 +70,64 @@ func formatGroupLabel(groupKey string) string {
 	return strings.Title(normalized)
 }
 
+// GetUserPermissionNames mengambil seluruh permission efektif user: permission milik roleIDs
+// (role user beserta role yang diwarisi, sudah diuraikan oleh service) dan permission yang
+// diberikan langsung (model_has_permissions), dikurangi permission yang ditolak langsung
+// (is_denied = 1). User yang memiliki langsung role is_admin mendapat semua permission; role admin
+// tidak bisa diwarisi (lihat services.validateRoleParents).
+func (r *PermissionRepository) GetUserPermissionNames(userID int, roleIDs []int) (map[string]bool, error) {
+	perms := make(map[string]bool)
+
+	roleCondition := "FALSE"
+	args := []interface{}{userID, userModelType}
+	if len(roleIDs) > 0 {
+		placeholders := make([]string, len(roleIDs))
+		for i, id := range roleIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		roleCondition = `EXISTS (
+					SELECT 1
+					FROM role_has_permissions rhp
+					WHERE rhp.permission_id = p.id AND rhp.role_id IN (` + strings.Join(placeholders, ",") + `)
+				)`
+	}
+	args = append(args, userID, userModelType, userID, userModelType)
+
+	rows, err := r.DB.Query(`
+		SELECT p.name
+		FROM permissions p
+		WHERE EXISTS (
+			SELECT 1
+			FROM model_has_roles mhr
+			JOIN roles r ON r.id = mhr.role_id
+			WHERE mhr.model_id = ? AND mhr.model_type = ? AND r.is_admin = 1
+		) OR (
+			(
+				`+roleCondition+` OR EXISTS (
+					SELECT 1
+					FROM model_has_permissions mhp
+					WHERE mhp.permission_id = p.id AND mhp.model_id = ? AND mhp.model_type = ? AND mhp.is_denied = 0
+				)
+			) AND NOT EXISTS (
+				SELECT 1
+				FROM model_has_permissions mhp
+				WHERE mhp.permission_id = p.id AND mhp.model_id = ? AND mhp.model_type = ? AND mhp.is_denied = 1
+			)
+		)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		perms[name] = true
+	}
+
+	return perms, rows.Err()
+}
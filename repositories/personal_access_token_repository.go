@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"gobase-app/models"
+	"time"
+)
+
+type PersonalAccessTokenRepository struct {
+	DB *sql.DB
+}
+
+// Create menyimpan token baru (dalam bentuk hash) dan mengembalikan id-nya.
+func (r *PersonalAccessTokenRepository) Create(userID int, name, tokenHash string, abilities []string, expiresAt *time.Time) (int64, error) {
+	abilityJSON, err := json.Marshal(abilities)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	res, err := r.DB.Exec(`
+		INSERT INTO personal_access_tokens (tokenable_type, tokenable_id, name, token, abilities, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, userModelType, userID, name, tokenHash, string(abilityJSON), expiresAt, now, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ListByUserID mengambil seluruh token milik user, terbaru lebih dulu.
+func (r *PersonalAccessTokenRepository) ListByUserID(userID int) ([]models.PersonalAccessToken, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, tokenable_id, name, COALESCE(abilities, '[]'), last_used_at, expires_at, created_at
+		FROM personal_access_tokens
+		WHERE tokenable_type = ? AND tokenable_id = ?
+		ORDER BY created_at DESC, id DESC
+	`, userModelType, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.PersonalAccessToken
+	for rows.Next() {
+		var (
+			t           models.PersonalAccessToken
+			abilityJSON string
+			lastUsed    sql.NullTime
+			expiresAt   sql.NullTime
+		)
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &abilityJSON, &lastUsed, &expiresAt, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(abilityJSON), &t.Abilities); err != nil {
+			t.Abilities = nil
+		}
+		if lastUsed.Valid {
+			t.LastUsedAt = &lastUsed.Time
+		}
+		if expiresAt.Valid {
+			t.ExpiresAt = &expiresAt.Time
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// DeleteForUser menghapus token milik user. Mengembalikan false jika token tidak ditemukan.
+func (r *PersonalAccessTokenRepository) DeleteForUser(userID int, id int64) (bool, error) {
+	res, err := r.DB.Exec(`
+		DELETE FROM personal_access_tokens
+		WHERE id = ? AND tokenable_type = ? AND tokenable_id = ?
+	`, id, userModelType, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// FindPrincipal mencari token yang belum kedaluwarsa berdasarkan hash, hanya untuk user aktif.
+// Mengembalikan nil jika token tidak valid.
+func (r *PersonalAccessTokenRepository) FindPrincipal(tokenHash string, now time.Time) (*models.TokenPrincipal, error) {
+	var (
+		p           models.TokenPrincipal
+		abilityJSON string
+		lastUsed    sql.NullTime
+	)
+	err := r.DB.QueryRow(`
+		SELECT t.id, u.id, u.username, COALESCE(t.abilities, '[]'), t.last_used_at
+		FROM personal_access_tokens t
+		JOIN users u ON u.id = t.tokenable_id
+		WHERE t.token = ?
+			AND t.tokenable_type = ?
+			AND (t.expires_at IS NULL OR t.expires_at > ?)
+			AND u.status = 'active'
+	`, tokenHash, userModelType, now).Scan(&p.TokenID, &p.UserID, &p.Username, &abilityJSON, &lastUsed)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lastUsed.Valid {
+		p.LastUsedAt = &lastUsed.Time
+	}
+
+	var abilities []string
+	if err := json.Unmarshal([]byte(abilityJSON), &abilities); err != nil {
+		abilities = nil
+	}
+	p.Abilities = make(map[string]bool, len(abilities))
+	for _, a := range abilities {
+		p.Abilities[a] = true
+	}
+	return &p, nil
+}
+
+// TouchLastUsed mencatat waktu terakhir token dipakai.
+func (r *PersonalAccessTokenRepository) TouchLastUsed(id int64, now time.Time) error {
+	_, err := r.DB.Exec(`UPDATE personal_access_tokens SET last_used_at = ? WHERE id = ?`, now, id)
+	return err
+}
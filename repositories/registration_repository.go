@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+	"time"
+)
+
+type RegistrationRepository struct {
+	DB *sql.DB
+}
+
+// CreateVerificationToken menyimpan hash token verifikasi email dan membatalkan token lama yang belum dipakai.
+func (r *RegistrationRepository) CreateVerificationToken(userID int, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM email_verification_tokens WHERE user_id = ? AND used_at IS NULL`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO email_verification_tokens (user_id, token_hash, expires_at)
+		VALUES (?, ?, ?)
+	`, userID, tokenHash, expiresAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// VerifyEmail menandai email user terverifikasi dan token terpakai dalam satu transaksi.
+// Mengembalikan sql.ErrNoRows jika token tidak ada, sudah dipakai, atau kedaluwarsa.
+func (r *RegistrationRepository) VerifyEmail(tokenHash string, now time.Time) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	var (
+		tokenID int64
+		userID  int
+	)
+	if err := tx.QueryRow(`
+		SELECT id, user_id
+		FROM email_verification_tokens
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+		FOR UPDATE
+	`, tokenHash, now).Scan(&tokenID, &userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET email_verified_at = ? WHERE id = ?`, now, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE email_verification_tokens SET used_at = ? WHERE id = ?`, now, tokenID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetApprovalState mengambil status dan status verifikasi email user untuk proses approval.
+func (r *RegistrationRepository) GetApprovalState(userID int) (string, bool, error) {
+	var (
+		status   string
+		verified bool
+	)
+	err := r.DB.QueryRow(`
+		SELECT status, email_verified_at IS NOT NULL
+		FROM users
+		WHERE id = ?
+	`, userID).Scan(&status, &verified)
+	return status, verified, err
+}
+
+// Approve mengaktifkan user yang masih berstatus pending dan mencatatnya di audit log.
+func (r *RegistrationRepository) Approve(userID int, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET status = 'active' WHERE id = ? AND status = 'pending'`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "user.approve", "user", int64(userID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
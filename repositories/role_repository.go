@@ -12,19 +12,23 @@ type RoleRepository struct {
 
 // RoleCreateParams menampung data yang diperlukan untuk menyimpan role baru.
 type RoleCreateParams struct {
-	Name          string
-	GuardName     string
-	IsAdmin       bool
-	PermissionIDs []int64
+	Name              string
+	GuardName         string
+	IsAdmin           bool
+	RequiresTwoFactor bool
+	PermissionIDs     []int64
+	ParentIDs         []int
 }
 
 // RoleUpdateParams menampung data yang diperlukan untuk memperbarui role.
 type RoleUpdateParams struct {
-	ID            int
-	Name          string
-	GuardName     string
-	IsAdmin       bool
-	PermissionIDs []int64
+	ID                int
+	Name              string
+	GuardName         string
+	IsAdmin           bool
+	RequiresTwoFactor bool
+	PermissionIDs     []int64
+	ParentIDs         []int
 }
 
 // GetAll mengambil seluruh data role beserta jumlah permission dan user yang terkait.
@@ -33,13 +37,14 @@ func (r *RoleRepository) GetAll() ([]models.Role, error) {
 		SELECT 
 			r.id,
 			r.name,
+			r.is_admin,
 			COUNT(DISTINCT rhp.permission_id) AS permission_count,
 			COUNT(DISTINCT mhr.model_id) AS user_count,
 			r.updated_at
 		FROM roles r
 		LEFT JOIN role_has_permissions rhp ON rhp.role_id = r.id
 		LEFT JOIN model_has_roles mhr ON mhr.role_id = r.id
-		GROUP BY r.id, r.name, r.updated_at
+		GROUP BY r.id, r.name, r.is_admin, r.updated_at
 		ORDER BY r.updated_at DESC
 	`)
 	if err != nil {
@@ -58,6 +63,7 @@ func (r *RoleRepository) GetAll() ([]models.Role, error) {
 		if err := rows.Scan(
 			&role.ID,
 			&role.Name,
+			&role.IsAdmin,
 			&role.PermissionCount,
 			&role.UserCount,
 			&updatedAt,
@@ -91,11 +97,11 @@ func (r *RoleRepository) ExistsByNameAndGuardExceptID(name, guardName string, ex
 	return count > 0, err
 }
 
-// GetByID mengambil detail role dan permission yang dimilikinya.
+// GetByID mengambil detail role, permission milik sendiri, dan parent langsungnya.
 func (r *RoleRepository) GetByID(id int) (*models.RoleDetail, error) {
 	var role models.RoleDetail
-	if err := r.DB.QueryRow(`SELECT id, name, guard_name, is_admin FROM roles WHERE id = ?`, id).
-		Scan(&role.ID, &role.Name, &role.GuardName, &role.IsAdmin); err != nil {
+	if err := r.DB.QueryRow(`SELECT id, name, guard_name, is_admin, requires_two_factor FROM roles WHERE id = ?`, id).
+		Scan(&role.ID, &role.Name, &role.GuardName, &role.IsAdmin, &role.RequiresTwoFactor); err != nil {
 		return nil, err
 	}
 
@@ -117,6 +123,24 @@ func (r *RoleRepository) GetByID(id int) (*models.RoleDetail, error) {
 		return nil, err
 	}
 
+	parentRows, err := r.DB.Query(`SELECT parent_id FROM role_parents WHERE role_id = ? ORDER BY parent_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer parentRows.Close()
+
+	for parentRows.Next() {
+		var parentID int
+		if err := parentRows.Scan(&parentID); err != nil {
+			return nil, err
+		}
+		role.ParentIDs = append(role.ParentIDs, parentID)
+	}
+
+	if err := parentRows.Err(); err != nil {
+		return nil, err
+	}
+
 	return &role, nil
 }
 
@@ -152,17 +176,17 @@ func (r *RoleRepository) FindExistingPermissionIDs(ids []int64) (map[int64]bool,
 	return result, rows.Err()
 }
 
-// CreateRoleWithPermissions menyimpan role baru beserta relasi permission dalam satu transaksi.
-func (r *RoleRepository) CreateRoleWithPermissions(params RoleCreateParams) (int64, error) {
+// CreateRoleWithPermissions menyimpan role baru beserta relasi permission dan audit log dalam satu transaksi.
+func (r *RoleRepository) CreateRoleWithPermissions(params RoleCreateParams, actor models.AuditActor) (int64, error) {
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return 0, err
 	}
 
 	res, err := tx.Exec(`
-		INSERT INTO roles (name, guard_name, is_admin)
-		VALUES (?, ?, ?)
-	`, params.Name, params.GuardName, params.IsAdmin)
+		INSERT INTO roles (name, guard_name, is_admin, requires_two_factor)
+		VALUES (?, ?, ?, ?)
+	`, params.Name, params.GuardName, params.IsAdmin, params.RequiresTwoFactor)
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -190,6 +214,21 @@ func (r *RoleRepository) CreateRoleWithPermissions(params RoleCreateParams) (int
 		}
 	}
 
+	if err := insertRoleParents(tx, roleID, params.ParentIDs); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	after, err := roleAuditSnapshot(tx, roleID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := insertAuditLog(tx, actor, "role.create", "role", roleID, nil, after); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
 		return 0, err
@@ -198,18 +237,24 @@ func (r *RoleRepository) CreateRoleWithPermissions(params RoleCreateParams) (int
 	return roleID, nil
 }
 
-// UpdateRoleWithPermissions memperbarui data role beserta relasi permission dalam satu transaksi.
-func (r *RoleRepository) UpdateRoleWithPermissions(params RoleUpdateParams) error {
+// UpdateRoleWithPermissions memperbarui data role beserta relasi permission dan audit log dalam satu transaksi.
+func (r *RoleRepository) UpdateRoleWithPermissions(params RoleUpdateParams, actor models.AuditActor) error {
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
 	}
 
+	before, err := roleAuditSnapshot(tx, int64(params.ID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if _, err := tx.Exec(`
 		UPDATE roles 
-		SET name = ?, guard_name = ?, is_admin = ?
+		SET name = ?, guard_name = ?, is_admin = ?, requires_two_factor = ?
 		WHERE id = ?
-	`, params.Name, params.GuardName, params.IsAdmin, params.ID); err != nil {
+	`, params.Name, params.GuardName, params.IsAdmin, params.RequiresTwoFactor, params.ID); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -235,16 +280,42 @@ func (r *RoleRepository) UpdateRoleWithPermissions(params RoleUpdateParams) erro
 		}
 	}
 
+	if _, err := tx.Exec(`DELETE FROM role_parents WHERE role_id = ?`, params.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertRoleParents(tx, int64(params.ID), params.ParentIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after, err := roleAuditSnapshot(tx, int64(params.ID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "role.update", "role", int64(params.ID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
-// DeleteByID removes a role and its related mappings in a single transaction.
-func (r *RoleRepository) DeleteByID(id int) error {
+// DeleteByID removes a role and its related mappings in a single transaction,
+// recording the removed data in the audit log.
+func (r *RoleRepository) DeleteByID(id int, actor models.AuditActor) error {
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
 	}
 
+	before, err := roleAuditSnapshot(tx, int64(id))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM role_has_permissions WHERE role_id = ?`, id); err != nil {
 		tx.Rollback()
 		return err
@@ -255,11 +326,210 @@ func (r *RoleRepository) DeleteByID(id int) error {
 		return err
 	}
 
+	if _, err := tx.Exec(`DELETE FROM role_parents WHERE role_id = ? OR parent_id = ?`, id, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM roles WHERE id = ?`, id); err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	if err := insertAuditLog(tx, actor, "role.delete", "role", int64(id), before, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// UserIsAdmin mengecek apakah user memiliki langsung minimal satu role is_admin. Role admin tidak
+// bisa diwarisi sehingga role turunan tidak perlu ditelusuri.
+func (r *RoleRepository) UserIsAdmin(userID int) (bool, error) {
+	var count int
+	err := r.DB.QueryRow(`
+		SELECT COUNT(1)
+		FROM model_has_roles mhr
+		JOIN roles r ON r.id = mhr.role_id
+		WHERE mhr.model_id = ? AND mhr.model_type = ? AND r.is_admin = 1
+	`, userID, userModelType).Scan(&count)
+	return count > 0, err
+}
+
+// ContainsAdminRole mengecek apakah salah satu id role merupakan role is_admin.
+func (r *RoleRepository) ContainsAdminRole(ids []int64) (bool, error) {
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	var count int
+	err := r.DB.QueryRow(`SELECT COUNT(1) FROM roles WHERE is_admin = 1 AND id IN (`+strings.Join(placeholders, ",")+`)`, args...).Scan(&count)
+	return count > 0, err
+}
+
+// CountAdminRoles menghitung role is_admin selain excludeRoleID (0 = tidak ada yang dikecualikan).
+func (r *RoleRepository) CountAdminRoles(excludeRoleID int) (int, error) {
+	var count int
+	err := r.DB.QueryRow(`SELECT COUNT(1) FROM roles WHERE is_admin = 1 AND id <> ?`, excludeRoleID).Scan(&count)
+	return count, err
+}
+
+// CountActiveAdminUsers menghitung user aktif yang tetap admin jika role excludeRoleID tidak lagi
+// admin dan user excludeUserID tidak lagi admin (0 = tidak ada yang dikecualikan).
+func (r *RoleRepository) CountActiveAdminUsers(excludeRoleID, excludeUserID int) (int, error) {
+	var count int
+	err := r.DB.QueryRow(`
+		SELECT COUNT(DISTINCT u.id)
+		FROM users u
+		JOIN model_has_roles mhr ON mhr.model_id = u.id AND mhr.model_type = ?
+		JOIN roles r ON r.id = mhr.role_id
+		WHERE r.is_admin = 1 AND u.status = 'active' AND r.id <> ? AND u.id <> ?
+	`, userModelType, excludeRoleID, excludeUserID).Scan(&count)
+	return count, err
+}
+
+// GetUserAdminRoleNames mengambil nama role is_admin yang dimiliki user.
+func (r *RoleRepository) GetUserAdminRoleNames(userID int) ([]string, error) {
+	return auditStrings(r.DB, `
+		SELECT r.name
+		FROM model_has_roles mhr
+		JOIN roles r ON r.id = mhr.role_id
+		WHERE mhr.model_id = ? AND mhr.model_type = ? AND r.is_admin = 1
+		ORDER BY r.name
+	`, userID, userModelType)
+}
+
+// GetParentMap mengambil seluruh relasi pewarisan role: id role -> id parent langsung.
+// Tabelnya kecil sehingga dimuat utuh lalu ditelusuri di service.
+func (r *RoleRepository) GetParentMap() (map[int][]int, error) {
+	rows, err := r.DB.Query(`SELECT role_id, parent_id FROM role_parents ORDER BY role_id, parent_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parents := make(map[int][]int)
+	for rows.Next() {
+		var roleID, parentID int
+		if err := rows.Scan(&roleID, &parentID); err != nil {
+			return nil, err
+		}
+		parents[roleID] = append(parents[roleID], parentID)
+	}
+
+	return parents, rows.Err()
+}
+
+// GetUserRoleIDs mengambil id role yang dimiliki langsung oleh user.
+func (r *RoleRepository) GetUserRoleIDs(userID int) ([]int, error) {
+	rows, err := r.DB.Query(`
+		SELECT role_id
+		FROM model_has_roles
+		WHERE model_id = ? AND model_type = ?
+		ORDER BY role_id
+	`, userID, userModelType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetPermissionIDsByRoles mengambil permission milik sendiri (tanpa warisan) setiap role: id role -> id permission.
+func (r *RoleRepository) GetPermissionIDsByRoles(roleIDs []int) (map[int][]int64, error) {
+	result := make(map[int][]int64)
+	if len(roleIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, len(roleIDs))
+	args := make([]interface{}, len(roleIDs))
+	for i, id := range roleIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT role_id, permission_id
+		FROM role_has_permissions
+		WHERE role_id IN (`+strings.Join(placeholders, ",")+`)
+		ORDER BY role_id, permission_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			roleID int
+			permID int64
+		)
+		if err := rows.Scan(&roleID, &permID); err != nil {
+			return nil, err
+		}
+		result[roleID] = append(result[roleID], permID)
+	}
+
+	return result, rows.Err()
+}
+
+// GetNames mengambil nama seluruh role: id role -> nama.
+func (r *RoleRepository) GetNames() (map[int]string, error) {
+	rows, err := r.DB.Query(`SELECT id, name FROM roles`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[int]string)
+	for rows.Next() {
+		var (
+			id   int
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+
+	return names, rows.Err()
+}
+
+func insertRoleParents(tx *sql.Tx, roleID int64, parentIDs []int) error {
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO role_parents (role_id, parent_id) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, parentID := range parentIDs {
+		if _, err := stmt.Exec(roleID, parentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
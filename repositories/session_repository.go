@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+	"time"
+)
+
+type SessionRepository struct {
+	DB *sql.DB
+}
+
+// Find mengambil session yang masih berlaku berdasarkan ID. Mengembalikan nil jika tidak ada.
+func (r *SessionRepository) Find(id string) (*models.Session, error) {
+	var (
+		s      models.Session
+		userID sql.NullInt64
+		ip     sql.NullString
+		agent  sql.NullString
+	)
+
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, ip_address, user_agent, payload, last_activity, expires_at
+		FROM sessions
+		WHERE id = ? AND expires_at > ?
+	`, id, time.Now()).Scan(&s.ID, &userID, &ip, &agent, &s.Payload, &s.LastActivity, &s.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.UserID = int(userID.Int64)
+	s.IPAddress = ip.String
+	s.UserAgent = agent.String
+	return &s, nil
+}
+
+// Save menyimpan atau memperbarui session berdasarkan ID.
+func (r *SessionRepository) Save(s models.Session) error {
+	var userID interface{}
+	if s.UserID > 0 {
+		userID = s.UserID
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO sessions (id, user_id, ip_address, user_agent, payload, last_activity, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			user_id = VALUES(user_id),
+			ip_address = VALUES(ip_address),
+			user_agent = VALUES(user_agent),
+			payload = VALUES(payload),
+			last_activity = VALUES(last_activity),
+			expires_at = VALUES(expires_at)
+	`, s.ID, userID, s.IPAddress, s.UserAgent, s.Payload, s.LastActivity, s.ExpiresAt)
+	return err
+}
+
+// Touch memperbarui waktu aktivitas terakhir session tanpa menulis ulang payload.
+func (r *SessionRepository) Touch(id string, at time.Time) error {
+	_, err := r.DB.Exec(`UPDATE sessions SET last_activity = ? WHERE id = ?`, at, id)
+	return err
+}
+
+// Delete menghapus satu session berdasarkan ID.
+func (r *SessionRepository) Delete(id string) error {
+	_, err := r.DB.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+	return err
+}
+
+// DeleteByUserID menghapus seluruh session milik user sehingga user langsung ter-logout.
+func (r *SessionRepository) DeleteByUserID(userID int) error {
+	_, err := r.DB.Exec(`DELETE FROM sessions WHERE user_id = ?`, userID)
+	return err
+}
+
+// FindByUserID mengambil session yang masih berlaku milik user, aktivitas terbaru lebih dulu.
+func (r *SessionRepository) FindByUserID(userID int) ([]models.Session, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, COALESCE(user_id, 0), COALESCE(ip_address, ''), COALESCE(user_agent, ''), last_activity, expires_at
+		FROM sessions
+		WHERE user_id = ? AND expires_at > ?
+		ORDER BY last_activity DESC
+	`, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.Session
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.IPAddress, &s.UserAgent, &s.LastActivity, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+
+	return result, rows.Err()
+}
+
+// DeleteExpired membersihkan session yang sudah kedaluwarsa dan mengembalikan session
+// yang dihapus (tanpa payload) agar bisa dicatat sebagai event session_expired.
+func (r *SessionRepository) DeleteExpired() ([]models.Session, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(user_id, 0), COALESCE(ip_address, ''), COALESCE(user_agent, ''), last_activity, expires_at
+		FROM sessions
+		WHERE expires_at <= ?
+		FOR UPDATE
+	`, now)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var expired []models.Session
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.IPAddress, &s.UserAgent, &s.LastActivity, &s.ExpiresAt); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		expired = append(expired, s)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		tx.Rollback()
+		return nil, err
+	}
+	rows.Close()
+
+	if _, err := tx.Exec(`DELETE FROM sessions WHERE expires_at <= ?`, now); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
@@ -0,0 +1,320 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"gobase-app/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+type StockRepository struct {
+	DB *sql.DB
+}
+
+// InsufficientStockError dikembalikan saat mutasi akan membuat saldo stok negatif. Seluruh
+// transaksi mutasi dibatalkan.
+type InsufficientStockError struct {
+	StoreID   int
+	ItemID    int64
+	Available int64
+	Requested int64
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("stok item %d di store %d tidak cukup: tersedia %d, diminta %d", e.ItemID, e.StoreID, e.Available, e.Requested)
+}
+
+// stockLeg adalah perubahan saldo satu item di satu store dalam satu mutasi. Transfer terdiri dari
+// dua leg (keluar dan masuk); jenis mutasi lain satu leg.
+type stockLeg struct {
+	storeID     int
+	delta       int64 // perubahan saldo; diabaikan jika setTo
+	setTo       bool  // penyesuaian: saldo baru = counted
+	counted     int64
+	counterpart int
+}
+
+// Receive mencatat penerimaan input.Quantity item ke store input.StoreID.
+func (r *StockRepository) Receive(input models.StockMovementInput, actor models.AuditActor) error {
+	return r.record(input, "", actor, stockLeg{storeID: input.StoreID, delta: input.Quantity})
+}
+
+// Issue mencatat pengeluaran input.Quantity item dari store input.StoreID.
+func (r *StockRepository) Issue(input models.StockMovementInput, actor models.AuditActor) error {
+	return r.record(input, "", actor, stockLeg{storeID: input.StoreID, delta: -input.Quantity})
+}
+
+// Adjust menyamakan saldo item di store input.StoreID dengan hasil hitung fisik input.Quantity.
+// Selisihnya (boleh 0) dicatat sebagai mutasi penyesuaian.
+func (r *StockRepository) Adjust(input models.StockMovementInput, actor models.AuditActor) error {
+	return r.record(input, "", actor, stockLeg{storeID: input.StoreID, setTo: true, counted: input.Quantity})
+}
+
+// Transfer memindahkan input.Quantity item dari store input.StoreID ke input.ToStoreID. Kedua
+// baris ledger memakai transferRef yang sama.
+func (r *StockRepository) Transfer(input models.StockMovementInput, transferRef string, actor models.AuditActor) error {
+	return r.record(input, transferRef, actor,
+		stockLeg{storeID: input.StoreID, delta: -input.Quantity, counterpart: input.ToStoreID},
+		stockLeg{storeID: input.ToStoreID, delta: input.Quantity, counterpart: input.StoreID},
+	)
+}
+
+// record menerapkan seluruh leg dalam satu transaksi. Baris saldo dikunci (FOR UPDATE) dengan
+// urutan store_id agar mutasi bersamaan di store yang sama saling menunggu tanpa deadlock, sehingga
+// saldo tidak pernah negatif. stock_movements hanya di-INSERT.
+func (r *StockRepository) record(input models.StockMovementInput, transferRef string, actor models.AuditActor, legs ...stockLeg) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	storeIDs := make([]int, 0, len(legs))
+	for _, leg := range legs {
+		storeIDs = append(storeIDs, leg.storeID)
+	}
+	sort.Ints(storeIDs)
+
+	balances := make(map[int]int64, len(storeIDs))
+	for _, storeID := range storeIDs {
+		if _, err := tx.Exec(`
+			INSERT INTO stock_balances (store_id, item_id, quantity) VALUES (?, ?, 0)
+			ON DUPLICATE KEY UPDATE quantity = quantity
+		`, storeID, input.ItemID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		var quantity int64
+		if err := tx.QueryRow(`
+			SELECT quantity FROM stock_balances WHERE store_id = ? AND item_id = ? FOR UPDATE
+		`, storeID, input.ItemID).Scan(&quantity); err != nil {
+			tx.Rollback()
+			return err
+		}
+		balances[storeID] = quantity
+	}
+
+	var actorID interface{}
+	if actor.UserID > 0 {
+		actorID = actor.UserID
+	}
+
+	for _, leg := range legs {
+		current := balances[leg.storeID]
+		delta := leg.delta
+		if leg.setTo {
+			delta = leg.counted - current
+		}
+		if current+delta < 0 {
+			tx.Rollback()
+			return &InsufficientStockError{StoreID: leg.storeID, ItemID: input.ItemID, Available: current, Requested: -delta}
+		}
+		balance := current + delta
+
+		if _, err := tx.Exec(`
+			UPDATE stock_balances SET quantity = ? WHERE store_id = ? AND item_id = ?
+		`, balance, leg.storeID, input.ItemID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		var counterpart interface{}
+		if leg.counterpart > 0 {
+			counterpart = leg.counterpart
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO stock_movements (store_id, item_id, movement_type, quantity, balance_after,
+				counterpart_store_id, transfer_ref, reason, reference, actor_id, actor_name)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, leg.storeID, input.ItemID, input.Type, delta, balance,
+			counterpart, nullableString(transferRef), input.Reason, nullableString(input.Reference), actorID, actor.Username); err != nil {
+			tx.Rollback()
+			return err
+		}
+		balances[leg.storeID] = balance
+	}
+
+	return tx.Commit()
+}
+
+// SearchBalances mengambil satu halaman saldo stok dalam scope beserta total baris yang cocok.
+func (r *StockRepository) SearchBalances(scope models.StoreFilter, filter models.StockBalanceFilter) ([]models.StockBalance, int, error) {
+	storeWhere, args := storeFilterClause("b.store_id", scope)
+	conds := []string{storeWhere}
+	if filter.StoreID > 0 {
+		conds = append(conds, "b.store_id = ?")
+		args = append(args, filter.StoreID)
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		conds = append(conds, "(i.sku LIKE ? OR i.name LIKE ?)")
+		like := "%" + q + "%"
+		args = append(args, like, like)
+	}
+	if filter.Category != "" {
+		conds = append(conds, "i.category = ?")
+		args = append(args, filter.Category)
+	}
+
+	from := `
+		FROM stock_balances b
+		JOIN stores s ON s.store_id = b.store_id
+		JOIN items i ON i.id = b.item_id
+		WHERE ` + strings.Join(conds, " AND ")
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(1)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT b.store_id, s.store_code, s.store_name, b.item_id, i.sku, i.name, i.category, i.unit,
+			i.is_active, b.quantity, b.updated_at` + from + `
+		ORDER BY s.store_name ASC, i.name ASC, b.item_id ASC`
+	if filter.PerPage > 0 {
+		page := filter.Page
+		if page < 1 {
+			page = 1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.PerPage, (page-1)*filter.PerPage)
+	}
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var balances []models.StockBalance
+	for rows.Next() {
+		var (
+			b         models.StockBalance
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&b.StoreID, &b.StoreCode, &b.StoreName, &b.ItemID, &b.ItemSKU, &b.ItemName,
+			&b.ItemCategory, &b.ItemUnit, &b.ItemActive, &b.Quantity, &updatedAt); err != nil {
+			return nil, 0, err
+		}
+		b.UpdatedAtDisplay = updatedAt.Format("02 Jan 2006 15:04")
+		balances = append(balances, b)
+	}
+
+	return balances, total, rows.Err()
+}
+
+// SearchMovements mengambil satu halaman ledger stok dalam scope (terbaru lebih dulu) beserta
+// total baris yang cocok.
+func (r *StockRepository) SearchMovements(scope models.StoreFilter, filter models.StockMovementFilter) ([]models.StockMovement, int, error) {
+	storeWhere, args := storeFilterClause("m.store_id", scope)
+	conds := []string{storeWhere}
+	if filter.StoreID > 0 {
+		conds = append(conds, "m.store_id = ?")
+		args = append(args, filter.StoreID)
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		conds = append(conds, "(i.sku LIKE ? OR i.name LIKE ? OR m.reference LIKE ?)")
+		like := "%" + q + "%"
+		args = append(args, like, like, like)
+	}
+	if filter.Type != "" {
+		conds = append(conds, "m.movement_type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.DateFrom != "" {
+		conds = append(conds, "m.created_at >= ?")
+		args = append(args, filter.DateFrom+" 00:00:00")
+	}
+	if filter.DateTo != "" {
+		conds = append(conds, "m.created_at <= ?")
+		args = append(args, filter.DateTo+" 23:59:59")
+	}
+
+	from := `
+		FROM stock_movements m
+		JOIN stores s ON s.store_id = m.store_id
+		JOIN items i ON i.id = m.item_id
+		LEFT JOIN stores cs ON cs.store_id = m.counterpart_store_id
+		WHERE ` + strings.Join(conds, " AND ")
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(1)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT m.id, m.store_id, s.store_code, s.store_name, m.item_id, i.sku, i.name, i.unit,
+			m.movement_type, m.quantity, m.balance_after, COALESCE(m.counterpart_store_id, 0),
+			COALESCE(cs.store_name, ''), COALESCE(m.transfer_ref, ''), m.reason, COALESCE(m.reference, ''),
+			COALESCE(m.actor_id, 0), m.actor_name, m.created_at` + from + `
+		ORDER BY m.id DESC`
+	if filter.PerPage > 0 {
+		page := filter.Page
+		if page < 1 {
+			page = 1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.PerPage, (page-1)*filter.PerPage)
+	}
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var movements []models.StockMovement
+	for rows.Next() {
+		var (
+			m         models.StockMovement
+			createdAt time.Time
+		)
+		if err := rows.Scan(&m.ID, &m.StoreID, &m.StoreCode, &m.StoreName, &m.ItemID, &m.ItemSKU, &m.ItemName,
+			&m.ItemUnit, &m.Type, &m.Quantity, &m.BalanceAfter, &m.CounterpartStoreID, &m.CounterpartStoreName,
+			&m.TransferRef, &m.Reason, &m.Reference, &m.ActorID, &m.ActorName, &createdAt); err != nil {
+			return nil, 0, err
+		}
+		m.CreatedAt = createdAt.Format("2006-01-02 15:04:05")
+		m.CreatedAtDisplay = createdAt.Format("02 Jan 2006 15:04:05")
+		movements = append(movements, m)
+	}
+
+	return movements, total, rows.Err()
+}
+
+// StockBalanceMismatch adalah saldo di stock_balances yang berbeda dengan jumlah mutasinya di
+// stock_movements.
+type StockBalanceMismatch struct {
+	StoreID int
+	ItemID  int64
+	Cached  int64 // stock_balances.quantity
+	Ledger  int64 // SUM(stock_movements.quantity)
+}
+
+// FindBalanceMismatches membandingkan setiap saldo dengan total mutasinya. Dalam kondisi normal
+// hasilnya kosong karena keduanya selalu diubah dalam satu transaksi.
+func (r *StockRepository) FindBalanceMismatches() ([]StockBalanceMismatch, error) {
+	rows, err := r.DB.Query(`
+		SELECT b.store_id, b.item_id, b.quantity, COALESCE(SUM(m.quantity), 0) AS ledger
+		FROM stock_balances b
+		LEFT JOIN stock_movements m ON m.store_id = b.store_id AND m.item_id = b.item_id
+		GROUP BY b.store_id, b.item_id, b.quantity
+		HAVING b.quantity <> ledger
+		ORDER BY b.store_id, b.item_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mismatches []StockBalanceMismatch
+	for rows.Next() {
+		var m StockBalanceMismatch
+		if err := rows.Scan(&m.StoreID, &m.ItemID, &m.Cached, &m.Ledger); err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, rows.Err()
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"errors"
+	"gobase-app/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectLockedBalance mengharapkan baris saldo store dibuat jika belum ada lalu dikunci FOR UPDATE.
+func expectLockedBalance(mock sqlmock.Sqlmock, storeID int, quantity int64) {
+	mock.ExpectExec(`INSERT INTO stock_balances \(store_id, item_id, quantity\) VALUES \(\?, \?, 0\)\s+ON DUPLICATE KEY UPDATE`).
+		WithArgs(storeID, int64(11)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT quantity FROM stock_balances WHERE store_id = \? AND item_id = \? FOR UPDATE`).
+		WithArgs(storeID, int64(11)).
+		WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(quantity))
+}
+
+// expectLeg mengharapkan saldo baru dan satu baris ledger untuk satu store.
+func expectLeg(mock sqlmock.Sqlmock, storeID int, movementType string, delta, balance int64, counterpart, transferRef interface{}) {
+	mock.ExpectExec(`UPDATE stock_balances SET quantity = \? WHERE store_id = \? AND item_id = \?`).
+		WithArgs(balance, storeID, int64(11)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO stock_movements`).
+		WithArgs(storeID, int64(11), movementType, delta, balance, counterpart, transferRef, "opname", nil, 3, "alice").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestStockRepositoryRecord(t *testing.T) {
+	actor := models.AuditActor{UserID: 3, Username: "alice"}
+	input := func(movementType string, quantity int64) models.StockMovementInput {
+		return models.StockMovementInput{Type: movementType, StoreID: 5, ItemID: 11, Quantity: quantity, ToStoreID: 2, Reason: "opname"}
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		run     func(*StockRepository) error
+		wantErr *InsufficientStockError
+	}{
+		{
+			name: "penerimaan menambah saldo",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectLockedBalance(mock, 5, 10)
+				expectLeg(mock, 5, models.StockReceipt, 4, 14, nil, nil)
+			},
+			run: func(r *StockRepository) error { return r.Receive(input(models.StockReceipt, 4), actor) },
+		},
+		{
+			name: "pengeluaran sampai saldo habis",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectLockedBalance(mock, 5, 10)
+				expectLeg(mock, 5, models.StockIssue, -10, 0, nil, nil)
+			},
+			run: func(r *StockRepository) error { return r.Issue(input(models.StockIssue, 10), actor) },
+		},
+		{
+			name:    "pengeluaran melebihi saldo dibatalkan",
+			setup:   func(mock sqlmock.Sqlmock) { expectLockedBalance(mock, 5, 10) },
+			run:     func(r *StockRepository) error { return r.Issue(input(models.StockIssue, 11), actor) },
+			wantErr: &InsufficientStockError{StoreID: 5, ItemID: 11, Available: 10, Requested: 11},
+		},
+		{
+			name: "penyesuaian mencatat selisih hasil hitung",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectLockedBalance(mock, 5, 10)
+				expectLeg(mock, 5, models.StockAdjustment, -6, 4, nil, nil)
+			},
+			run: func(r *StockRepository) error { return r.Adjust(input(models.StockAdjustment, 4), actor) },
+		},
+		{
+			// store 2 dikunci lebih dulu meskipun store asal 5, agar transfer berlawanan arah tidak deadlock
+			name: "transfer mengunci kedua store berurutan store_id",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectLockedBalance(mock, 2, 1)
+				expectLockedBalance(mock, 5, 10)
+				expectLeg(mock, 5, models.StockTransfer, -3, 7, 2, "TRF-1")
+				expectLeg(mock, 2, models.StockTransfer, 3, 4, 5, "TRF-1")
+			},
+			run: func(r *StockRepository) error { return r.Transfer(input(models.StockTransfer, 3), "TRF-1", actor) },
+		},
+		{
+			name: "transfer melebihi saldo store asal dibatalkan",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectLockedBalance(mock, 2, 50)
+				expectLockedBalance(mock, 5, 2)
+			},
+			run:     func(r *StockRepository) error { return r.Transfer(input(models.StockTransfer, 3), "TRF-1", actor) },
+			wantErr: &InsufficientStockError{StoreID: 5, ItemID: 11, Available: 2, Requested: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			tt.setup(mock)
+			if tt.wantErr != nil {
+				// saldo dan ledger tidak boleh berubah sama sekali
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+
+			err = tt.run(&StockRepository{DB: db})
+			if tt.wantErr != nil {
+				var insufficient *InsufficientStockError
+				if !errors.As(err, &insufficient) || *insufficient != *tt.wantErr {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
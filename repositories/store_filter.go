@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"gobase-app/models"
+	"strings"
+)
+
+// storeFilterClause menghasilkan kondisi SQL yang membatasi column (berisi id store) sesuai filter.
+// Filter tanpa store menghasilkan kondisi yang selalu salah sehingga tidak ada data yang bocor.
+func storeFilterClause(column string, filter models.StoreFilter) (string, []interface{}) {
+	if filter.All {
+		return "TRUE", nil
+	}
+	if len(filter.StoreIDs) == 0 {
+		return "FALSE", nil
+	}
+
+	placeholders := make([]string, len(filter.StoreIDs))
+	args := make([]interface{}, len(filter.StoreIDs))
+	for i, id := range filter.StoreIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return column + " IN (" + strings.Join(placeholders, ",") + ")", args
+}
+
+// userStoreFilterClause seperti storeFilterClause untuk user (alias u) lewat tabel user_stores:
+// user lolos jika minimal satu store-nya ada di filter.
+func userStoreFilterClause(filter models.StoreFilter) (string, []interface{}) {
+	if filter.All {
+		return "TRUE", nil
+	}
+
+	where, args := storeFilterClause("us.store_id", filter)
+	if len(args) == 0 {
+		return where, nil
+	}
+	return "EXISTS (SELECT 1 FROM user_stores us WHERE us.user_id = u.id AND " + where + ")", args
+}
@@ -10,28 +10,74 @@ type StoreRepository struct {
 	DB *sql.DB
 }
 
-// GetAll mengambil seluruh data store.
+const storeColumns = `store_id, store_code, store_name, store_address, is_active`
+
+// GetAll mengambil seluruh data store, termasuk yang nonaktif.
 func (r *StoreRepository) GetAll() ([]models.Store, error) {
-	rows, err := r.DB.Query(`
-		SELECT store_id, store_name
+	return r.queryStores(`
+		SELECT ` + storeColumns + `
 		FROM stores
 		ORDER BY store_id asc
 	`)
+}
+
+// GetActive mengambil store yang masih aktif, misalnya untuk pilihan store di form registrasi.
+func (r *StoreRepository) GetActive() ([]models.Store, error) {
+	return r.queryStores(`
+		SELECT ` + storeColumns + `
+		FROM stores
+		WHERE is_active = 1
+		ORDER BY store_id asc
+	`)
+}
+
+// GetFiltered mengambil store yang termasuk filter, misalnya store milik user yang sedang login.
+func (r *StoreRepository) GetFiltered(filter models.StoreFilter) ([]models.Store, error) {
+	where, args := storeFilterClause("store_id", filter)
+	return r.queryStores(`
+		SELECT `+storeColumns+`
+		FROM stores
+		WHERE `+where+`
+		ORDER BY store_id asc
+	`, args...)
+}
+
+// Search mengambil store dalam filter yang kode, nama, atau alamatnya mengandung search.Query,
+// dengan status sesuai search.Status.
+func (r *StoreRepository) Search(filter models.StoreFilter, search models.StoreSearch) ([]models.Store, error) {
+	where, args := storeFilterClause("store_id", filter)
+	conds := []string{where}
+
+	if q := strings.TrimSpace(search.Query); q != "" {
+		conds = append(conds, "(store_code LIKE ? OR store_name LIKE ? OR store_address LIKE ?)")
+		like := "%" + q + "%"
+		args = append(args, like, like, like)
+	}
+	switch search.Status {
+	case "active":
+		conds = append(conds, "is_active = 1")
+	case "inactive":
+		conds = append(conds, "is_active = 0")
+	}
+
+	return r.queryStores(`
+		SELECT `+storeColumns+`
+		FROM stores
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY is_active DESC, store_code asc
+	`, args...)
+}
+
+// GetByID mengambil satu store. Mengembalikan sql.ErrNoRows jika store tidak ada.
+func (r *StoreRepository) GetByID(id int) (*models.Store, error) {
+	stores, err := r.GetByIDs([]int{id})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var stores []models.Store
-	for rows.Next() {
-		var s models.Store
-		if err := rows.Scan(&s.StoreID, &s.StoreName); err != nil {
-			return nil, err
-		}
-		stores = append(stores, s)
+	if len(stores) == 0 {
+		return nil, sql.ErrNoRows
 	}
-
-	return stores, rows.Err()
+	return &stores[0], nil
 }
 
 // GetByIDs mengambil daftar store berdasarkan id yang diberikan.
@@ -47,28 +93,117 @@ func (r *StoreRepository) GetByIDs(ids []int) ([]models.Store, error) {
 		args[i] = id
 	}
 
-	query := `
-		SELECT store_id, store_name
+	return r.queryStores(`
+		SELECT `+storeColumns+`
 		FROM stores
-		WHERE store_id IN (` + strings.Join(placeholders, ",") + `)
+		WHERE store_id IN (`+strings.Join(placeholders, ",")+`)
 		ORDER BY store_name
-	`
+	`, args...)
+}
+
+// ExistsByCodeExceptID mengecek apakah kode store sudah dipakai store lain (id 0 untuk store baru).
+func (r *StoreRepository) ExistsByCodeExceptID(code string, id int) (bool, error) {
+	var count int
+	err := r.DB.QueryRow(`SELECT COUNT(1) FROM stores WHERE store_code = ? AND store_id <> ?`, code, id).Scan(&count)
+	return count > 0, err
+}
+
+// Create menyimpan store baru (aktif) beserta audit log dalam satu transaksi.
+func (r *StoreRepository) Create(input models.StoreInput, actor models.AuditActor) (int64, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
 
+	res, err := tx.Exec(`
+		INSERT INTO stores (store_code, store_name, store_address, is_active)
+		VALUES (?, ?, ?, 1)
+	`, input.Code, input.Name, input.Address)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	after, err := storeAuditSnapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := insertAuditLog(tx, actor, "store.create", "store", id, nil, after); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// Update mengubah kode, nama, dan alamat store beserta audit log dalam satu transaksi.
+func (r *StoreRepository) Update(input models.StoreInput, actor models.AuditActor) error {
+	return r.updateStore(int64(input.ID), "store.update", actor, `
+		UPDATE stores SET store_code = ?, store_name = ?, store_address = ? WHERE store_id = ?
+	`, input.Code, input.Name, input.Address, input.ID)
+}
+
+// SetActive mengaktifkan atau menonaktifkan store. Assignment user ke store tidak diubah.
+func (r *StoreRepository) SetActive(id int, active bool, actor models.AuditActor) error {
+	action := "store.deactivate"
+	if active {
+		action = "store.activate"
+	}
+	return r.updateStore(int64(id), action, actor, `UPDATE stores SET is_active = ? WHERE store_id = ?`, active, id)
+}
+
+func (r *StoreRepository) updateStore(id int64, action string, actor models.AuditActor, query string, args ...interface{}) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := storeAuditSnapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after, err := storeAuditSnapshot(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, action, "store", id, before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *StoreRepository) queryStores(query string, args ...interface{}) ([]models.Store, error) {
 	rows, err := r.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var stores []models.Store
+	stores := []models.Store{}
 	for rows.Next() {
 		var s models.Store
-		if err := rows.Scan(&s.StoreID, &s.StoreName); err != nil {
+		if err := rows.Scan(&s.StoreID, &s.StoreCode, &s.StoreName, &s.StoreAddress, &s.IsActive); err != nil {
 			return nil, err
 		}
 		stores = append(stores, s)
 	}
 
-	return stores, nil
+	return stores, rows.Err()
 }
-
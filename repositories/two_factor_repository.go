@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+	"strings"
+)
+
+type TwoFactorRepository struct {
+	DB *sql.DB
+}
+
+// GetState mengambil secret, status aktif, dan sisa recovery code milik user.
+func (r *TwoFactorRepository) GetState(userID int) (*models.TwoFactorState, error) {
+	var (
+		state     models.TwoFactorState
+		secret    sql.NullString
+		enabledAt sql.NullTime
+		lastStep  sql.NullInt64
+	)
+
+	err := r.DB.QueryRow(`
+		SELECT
+			u.two_factor_secret,
+			u.two_factor_enabled_at,
+			u.two_factor_last_step,
+			(SELECT COUNT(1) FROM user_recovery_codes rc WHERE rc.user_id = u.id AND rc.used_at IS NULL)
+		FROM users u
+		WHERE u.id = ?
+	`, userID).Scan(&secret, &enabledAt, &lastStep, &state.RemainingRecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Secret = secret.String
+	state.Enabled = enabledAt.Valid && secret.Valid
+	state.LastUsedStep = lastStep.Int64
+	return &state, nil
+}
+
+// SavePendingSecret menyimpan secret baru yang belum dikonfirmasi (2FA belum aktif).
+func (r *TwoFactorRepository) SavePendingSecret(userID int, secret string) error {
+	_, err := r.DB.Exec(`
+		UPDATE users
+		SET two_factor_secret = ?, two_factor_enabled_at = NULL, two_factor_last_step = NULL
+		WHERE id = ?
+	`, secret, userID)
+	return err
+}
+
+// Enable mengaktifkan 2FA dan mengganti seluruh recovery code dalam satu transaksi.
+func (r *TwoFactorRepository) Enable(userID int, usedStep int64, codeHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users
+		SET two_factor_enabled_at = NOW(), two_factor_last_step = ?
+		WHERE id = ?
+	`, usedStep, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes mengganti recovery code user dengan yang baru.
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Disable mematikan 2FA, menghapus secret, dan seluruh recovery code user.
+func (r *TwoFactorRepository) Disable(userID int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users
+		SET two_factor_secret = NULL, two_factor_enabled_at = NULL, two_factor_last_step = NULL
+		WHERE id = ?
+	`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkStepUsed mencatat time-step TOTP terakhir yang dipakai. Mengembalikan false jika
+// step tersebut (atau yang lebih baru) sudah pernah dipakai sehingga kode tidak bisa di-replay.
+func (r *TwoFactorRepository) MarkStepUsed(userID int, step int64) (bool, error) {
+	res, err := r.DB.Exec(`
+		UPDATE users
+		SET two_factor_last_step = ?
+		WHERE id = ? AND (two_factor_last_step IS NULL OR two_factor_last_step < ?)
+	`, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// UseRecoveryCode menandai recovery code sebagai terpakai. Mengembalikan false jika tidak cocok.
+func (r *TwoFactorRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	res, err := r.DB.Exec(`
+		UPDATE user_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// RoleRequiresTwoFactor mengecek apakah salah satu roleIDs (role user beserta role yang
+// diwarisinya) mewajibkan 2FA.
+func (r *TwoFactorRepository) RoleRequiresTwoFactor(roleIDs []int) (bool, error) {
+	if len(roleIDs) == 0 {
+		return false, nil
+	}
+
+	placeholders := make([]string, len(roleIDs))
+	args := make([]interface{}, len(roleIDs))
+	for i, id := range roleIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	var count int
+	err := r.DB.QueryRow(`
+		SELECT COUNT(1)
+		FROM roles
+		WHERE id IN (`+strings.Join(placeholders, ",")+`) AND requires_two_factor = 1
+	`, args...).Scan(&count)
+	return count > 0, err
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, hash := range codeHashes {
+		if _, err := stmt.Exec(userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
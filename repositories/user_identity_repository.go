@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// UserIdentityRepository menyimpan hubungan user lokal dengan akun di identity provider (SSO).
+type UserIdentityRepository struct {
+	DB *sql.DB
+}
+
+// FindUserID mengambil id user yang terhubung dengan pasangan issuer dan subject; sql.ErrNoRows jika belum ada.
+func (r *UserIdentityRepository) FindUserID(provider, subject string) (int, error) {
+	var userID int
+	err := r.DB.QueryRow(`
+		SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?
+	`, provider, subject).Scan(&userID)
+	return userID, err
+}
+
+// Link menghubungkan user dengan identitas SSO, atau memperbarui email dan waktu login jika sudah terhubung.
+func (r *UserIdentityRepository) Link(userID int, provider, subject, email string, now time.Time) error {
+	var emailVal interface{}
+	if strings.TrimSpace(email) != "" {
+		emailVal = email
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE email = VALUES(email), last_login_at = VALUES(last_login_at)
+	`, userID, provider, subject, emailVal, now, now)
+	return err
+}
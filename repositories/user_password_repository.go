@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+)
+
+// FindActiveCredentials mengambil id dan hash password user aktif berdasarkan username.
+// Mengembalikan sql.ErrNoRows jika user tidak ada atau tidak aktif.
+func (r *UserRepository) FindActiveCredentials(username string) (int, string, error) {
+	var (
+		id   int
+		hash string
+	)
+	err := r.DB.QueryRow(`
+		SELECT id, password
+		FROM users
+		WHERE username = ? AND status = 'active'
+	`, username).Scan(&id, &hash)
+	return id, hash, err
+}
+
+// GetPasswordOwner mengambil data user yang dibutuhkan untuk validasi password baru.
+func (r *UserRepository) GetPasswordOwner(id int) (models.PasswordOwner, error) {
+	owner := models.PasswordOwner{ID: id}
+	err := r.DB.QueryRow(`
+		SELECT nip, username, name, password
+		FROM users
+		WHERE id = ?
+	`, id).Scan(&owner.NIP, &owner.Username, &owner.Name, &owner.PasswordHash)
+	return owner, err
+}
+
+// RecentPasswordHashes mengambil hash password saat ini ditambah limit hash terakhir dari riwayat.
+// Password saat ini ikut disertakan agar user lama yang belum punya riwayat tetap tercek.
+func (r *UserRepository) RecentPasswordHashes(userID int, limit int) ([]string, error) {
+	rows, err := r.DB.Query(`
+		(SELECT password FROM users WHERE id = ?)
+		UNION ALL
+		(SELECT password_hash FROM password_histories WHERE user_id = ? ORDER BY id DESC LIMIT ?)
+	`, userID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+// ChangePassword mengganti password user, mencatat riwayatnya, dan menghapus tanda wajib ganti password.
+func (r *UserRepository) ChangePassword(userID int, hashedPassword string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET password = ?, must_change_password = 0 WHERE id = ?`, hashedPassword, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertPasswordHistory(tx, int64(userID), hashedPassword); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MustChangePassword mengecek apakah user wajib mengganti password sebelum memakai aplikasi.
+func (r *UserRepository) MustChangePassword(userID int) (bool, error) {
+	var mustChange bool
+	err := r.DB.QueryRow(`SELECT must_change_password FROM users WHERE id = ?`, userID).Scan(&mustChange)
+	return mustChange, err
+}
+
+// insertPasswordHistory mencatat hash password baru di dalam transaksi yang sedang berjalan.
+func insertPasswordHistory(tx *sql.Tx, userID int64, hashedPassword string) error {
+	_, err := tx.Exec(`
+		INSERT INTO password_histories (user_id, password_hash)
+		VALUES (?, ?)
+	`, userID, hashedPassword)
+	return err
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"database/sql"
+	"gobase-app/models"
+)
+
+// GetDirectPermissions mengembalikan permission yang diatur langsung untuk user:
+// id permission -> true jika ditolak (deny), false jika diberikan (grant).
+func (r *PermissionRepository) GetDirectPermissions(userID int) (map[int64]bool, error) {
+	rows, err := r.DB.Query(`
+		SELECT permission_id, is_denied
+		FROM model_has_permissions
+		WHERE model_id = ? AND model_type = ?
+	`, userID, userModelType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	direct := make(map[int64]bool)
+	for rows.Next() {
+		var (
+			permID int64
+			denied bool
+		)
+		if err := rows.Scan(&permID, &denied); err != nil {
+			return nil, err
+		}
+		direct[permID] = denied
+	}
+
+	return direct, rows.Err()
+}
+
+// SetDirectPermission memberikan (denied = false) atau menolak (denied = true) permission langsung
+// ke user. Entry yang sudah ada untuk permission yang sama ditimpa. Dicatat sebagai user.update.
+func (r *PermissionRepository) SetDirectPermission(userID int, permissionID int64, denied bool, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO model_has_permissions (permission_id, model_type, model_id, is_denied)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE is_denied = VALUES(is_denied)
+	`, permissionID, userModelType, userID, denied); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "user.update", "user", int64(userID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteDirectPermission menghapus grant (denied = false) atau deny (denied = true) langsung milik
+// user. Mengembalikan sql.ErrNoRows jika entry tersebut tidak ada.
+func (r *PermissionRepository) DeleteDirectPermission(userID int, permissionID int64, denied bool, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	res, err := tx.Exec(`
+		DELETE FROM model_has_permissions
+		WHERE permission_id = ? AND model_id = ? AND model_type = ? AND is_denied = ?
+	`, permissionID, userID, userModelType, denied)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		tx.Rollback()
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+
+	after, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "user.update", "user", int64(userID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetStorePermissions mengambil permission per store milik user untuk layar permission user.
+func (r *PermissionRepository) GetStorePermissions(userID int) ([]models.UserStorePermission, error) {
+	rows, err := r.DB.Query(`
+		SELECT p.id, p.name, s.store_id, s.store_name,
+			EXISTS (
+				SELECT 1 FROM model_has_permissions mhp
+				WHERE mhp.permission_id = p.id AND mhp.model_id = mhsp.model_id
+					AND mhp.model_type = mhsp.model_type AND mhp.is_denied = 1
+			) AS denied
+		FROM model_has_store_permissions mhsp
+		JOIN permissions p ON p.id = mhsp.permission_id
+		JOIN stores s ON s.store_id = mhsp.store_id
+		WHERE mhsp.model_id = ? AND mhsp.model_type = ?
+		ORDER BY s.store_name, p.name
+	`, userID, userModelType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.UserStorePermission
+	for rows.Next() {
+		var item models.UserStorePermission
+		if err := rows.Scan(&item.PermissionID, &item.PermissionName, &item.StoreID, &item.StoreName, &item.Denied); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
+
+// GetStorePermissionGrants mengambil permission per store yang berlaku untuk user:
+// nama permission -> id store. Permission yang ditolak langsung (deny) tidak ikut.
+func (r *PermissionRepository) GetStorePermissionGrants(userID int) (map[string][]int, error) {
+	rows, err := r.DB.Query(`
+		SELECT p.name, mhsp.store_id
+		FROM model_has_store_permissions mhsp
+		JOIN permissions p ON p.id = mhsp.permission_id
+		WHERE mhsp.model_id = ? AND mhsp.model_type = ?
+			AND NOT EXISTS (
+				SELECT 1 FROM model_has_permissions mhp
+				WHERE mhp.permission_id = mhsp.permission_id AND mhp.model_id = mhsp.model_id
+					AND mhp.model_type = mhsp.model_type AND mhp.is_denied = 1
+			)
+		ORDER BY p.name, mhsp.store_id
+	`, userID, userModelType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make(map[string][]int)
+	for rows.Next() {
+		var (
+			name    string
+			storeID int
+		)
+		if err := rows.Scan(&name, &storeID); err != nil {
+			return nil, err
+		}
+		grants[name] = append(grants[name], storeID)
+	}
+
+	return grants, rows.Err()
+}
+
+// AddStorePermission memberikan permission ke user khusus untuk satu store. Dicatat sebagai user.update.
+func (r *PermissionRepository) AddStorePermission(userID, storeID int, permissionID int64, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT IGNORE INTO model_has_store_permissions (permission_id, model_type, model_id, store_id)
+		VALUES (?, ?, ?, ?)
+	`, permissionID, userModelType, userID, storeID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "user.update", "user", int64(userID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteStorePermission mencabut permission per store milik user. Mengembalikan sql.ErrNoRows jika
+// entry tersebut tidak ada.
+func (r *PermissionRepository) DeleteStorePermission(userID, storeID int, permissionID int64, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	res, err := tx.Exec(`
+		DELETE FROM model_has_store_permissions
+		WHERE permission_id = ? AND model_id = ? AND model_type = ? AND store_id = ?
+	`, permissionID, userID, userModelType, storeID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		tx.Rollback()
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+
+	after, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "user.update", "user", int64(userID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"database/sql"
-	"encoding/json"
 	"gobase-app/models"
 	"strconv"
 	"strings"
@@ -23,6 +22,8 @@ type UserCreateParams struct {
 	Email          string
 	Status         string
 	StoreIDs       []int
+
+	MustChangePassword bool
 }
 
 type UserUpdateParams struct {
@@ -34,10 +35,48 @@ type UserUpdateParams struct {
 	Email          string
 	Status         string
 	StoreIDs       []int
+
+	MustChangePassword bool
+}
+
+// GetAll mengambil data user yang memiliki minimal satu store di filter, beserta store dan format tanggal.
+func (r *UserRepository) GetAll(filter models.StoreFilter) ([]models.User, error) {
+	where, args := userStoreFilterClause(filter)
+	return r.queryUsers("WHERE "+where, "", args...)
+}
+
+// GetPage mengambil satu halaman data user dalam filter store (urutan sama dengan GetAll) beserta
+// total user dalam filter.
+func (r *UserRepository) GetPage(filter models.StoreFilter, limit, offset int) ([]models.User, int, error) {
+	where, args := userStoreFilterClause(filter)
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM users u WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	users, err := r.queryUsers("WHERE "+where, "LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// GetByID mengambil satu user. Mengembalikan sql.ErrNoRows jika user tidak ada.
+func (r *UserRepository) GetByID(id int) (*models.User, error) {
+	users, err := r.queryUsers("WHERE u.id = ?", "", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &users[0], nil
 }
 
-// GetAll mengambil seluruh data user beserta parsing store_id JSON dan format tanggal.
-func (r *UserRepository) GetAll() ([]models.User, error) {
+// queryUsers menjalankan query daftar user dengan kondisi where dan limit opsional.
+// args berisi nilai placeholder where diikuti placeholder limit.
+func (r *UserRepository) queryUsers(where, limit string, args ...interface{}) ([]models.User, error) {
 	rows, err := r.DB.Query(`
 		SELECT 
 			u.id, 
@@ -46,16 +85,30 @@ func (r *UserRepository) GetAll() ([]models.User, error) {
 			u.name, 
 			u.email, 
 			u.status, 
-			u.store_id, 
 			u.created_at,
-			COALESCE(GROUP_CONCAT(r2.name ORDER BY r2.name SEPARATOR ', '), '') AS role_display
+			u.must_change_password,
+			u.email_verified_at IS NOT NULL AS email_verified,
+			COALESCE(GROUP_CONCAT(r2.name ORDER BY r2.name SEPARATOR ', '), '') AS role_display,
+			COALESCE((
+				SELECT GROUP_CONCAT(us.store_id ORDER BY us.store_id)
+				FROM user_stores us
+				WHERE us.user_id = u.id
+			), '') AS store_ids,
+			COALESCE((
+				SELECT GROUP_CONCAT(s.store_name ORDER BY s.store_name SEPARATOR ', ')
+				FROM user_stores us
+				JOIN stores s ON s.store_id = us.store_id
+				WHERE us.user_id = u.id
+			), '') AS store_display
 		FROM users u
 		LEFT JOIN model_has_roles mhr ON mhr.model_id = u.id AND mhr.model_type = ?
 		LEFT JOIN roles r2 ON r2.id = mhr.role_id
+		`+where+`
 		GROUP BY 
-			u.id, u.nip, u.username, u.name, u.email, u.status, u.store_id, u.created_at
-		ORDER BY u.created_at DESC
-	`, userModelType)
+			u.id, u.nip, u.username, u.name, u.email, u.status, u.created_at, u.must_change_password, u.email_verified_at
+		ORDER BY u.created_at DESC, u.id DESC
+		`+limit+`
+	`, append([]interface{}{userModelType}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +119,7 @@ func (r *UserRepository) GetAll() ([]models.User, error) {
 	for rows.Next() {
 		var (
 			u         models.User
-			storeJSON string
+			storeIDs  string
 			createdAt time.Time
 		)
 
@@ -77,9 +130,12 @@ func (r *UserRepository) GetAll() ([]models.User, error) {
 			&u.Name,
 			&u.Email,
 			&u.Status,
-			&storeJSON,
 			&createdAt,
+			&u.MustChangePassword,
+			&u.EmailVerified,
 			&u.RoleDisplay,
+			&storeIDs,
+			&u.StoreDisplay,
 		); err != nil {
 			return nil, err
 		}
@@ -87,24 +143,18 @@ func (r *UserRepository) GetAll() ([]models.User, error) {
 		u.CreatedAt = createdAt.Format("2006-01-02 15:04:05")
 		u.CreatedAtDisplay = createdAt.Format("02 Jan 2006 15:04:05")
 
-		if u.Status == "active" {
+		switch u.Status {
+		case "active":
 			u.StatusLabel = "Aktif"
-		} else {
+		case "pending":
+			u.StatusLabel = "Menunggu Persetujuan"
+		default:
 			u.StatusLabel = "Non Aktif"
 		}
 
-		if storeJSON != "" {
-			var storeIDs []int
-			if err := json.Unmarshal([]byte(storeJSON), &storeIDs); err == nil {
-				u.StoreIDs = storeIDs
-				storeNames, err := r.getStoreNames(storeIDs)
-				if err == nil && len(storeNames) > 0 {
-					u.StoreDisplay = strings.Join(storeNames, ", ")
-				} else {
-					u.StoreDisplay = joinIntSlice(storeIDs)
-				}
-			} else {
-				u.StoreDisplay = storeJSON
+		for _, val := range splitAndTrimCSV(storeIDs) {
+			if id, err := strconv.Atoi(val); err == nil {
+				u.StoreIDs = append(u.StoreIDs, id)
 			}
 		}
 
@@ -120,22 +170,16 @@ func (r *UserRepository) GetAll() ([]models.User, error) {
 		users = append(users, u)
 	}
 
-	return users, nil
+	return users, rows.Err()
 }
 
-// CreateUserWithRoles menyimpan data user baru beserta assignment rolenya dalam satu transaksi.
-func (r *UserRepository) CreateUserWithRoles(params UserCreateParams, roleIDs []int64) (int64, error) {
+// CreateUserWithRoles menyimpan data user baru beserta assignment rolenya dan audit log dalam satu transaksi.
+func (r *UserRepository) CreateUserWithRoles(params UserCreateParams, roleIDs []int64, actor models.AuditActor) (int64, error) {
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return 0, err
 	}
 
-	storeJSON, err := json.Marshal(params.StoreIDs)
-	if err != nil {
-		tx.Rollback()
-		return 0, err
-	}
-
 	var emailVal interface{}
 	if strings.TrimSpace(params.Email) == "" {
 		emailVal = nil // simpan NULL jika email kosong
@@ -144,9 +188,9 @@ func (r *UserRepository) CreateUserWithRoles(params UserCreateParams, roleIDs []
 	}
 
 	res, err := tx.Exec(`
-		INSERT INTO users (nip, username, password, name, email, status, store_id)
+		INSERT INTO users (nip, username, password, name, email, status, must_change_password)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, params.NIP, params.Username, params.HashedPassword, params.Name, emailVal, params.Status, string(storeJSON))
+	`, params.NIP, params.Username, params.HashedPassword, params.Name, emailVal, params.Status, params.MustChangePassword)
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -158,6 +202,16 @@ func (r *UserRepository) CreateUserWithRoles(params UserCreateParams, roleIDs []
 		return 0, err
 	}
 
+	if err := insertPasswordHistory(tx, userID, params.HashedPassword); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := replaceUserStores(tx, userID, params.StoreIDs); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	if len(roleIDs) > 0 {
 		stmt, err := tx.Prepare(`
 			INSERT INTO model_has_roles (role_id, model_type, model_id)
@@ -177,6 +231,16 @@ func (r *UserRepository) CreateUserWithRoles(params UserCreateParams, roleIDs []
 		}
 	}
 
+	after, err := userAuditSnapshot(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := insertAuditLog(tx, actor, "user.create", "user", userID, nil, after); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
 		return 0, err
@@ -185,14 +249,14 @@ func (r *UserRepository) CreateUserWithRoles(params UserCreateParams, roleIDs []
 	return userID, nil
 }
 
-// UpdateUserWithRoles memperbarui data user beserta role assignments dalam satu transaksi.
-func (r *UserRepository) UpdateUserWithRoles(params UserUpdateParams, roleIDs []int64) error {
+// UpdateUserWithRoles memperbarui data user beserta role assignments dan audit log dalam satu transaksi.
+func (r *UserRepository) UpdateUserWithRoles(params UserUpdateParams, roleIDs []int64, actor models.AuditActor) error {
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
 	}
 
-	storeJSON, err := json.Marshal(params.StoreIDs)
+	before, err := userAuditSnapshot(tx, int64(params.ID))
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -208,23 +272,33 @@ func (r *UserRepository) UpdateUserWithRoles(params UserUpdateParams, roleIDs []
 	if params.HashedPassword != "" {
 		if _, err := tx.Exec(`
 			UPDATE users
-			SET nip = ?, username = ?, password = ?, name = ?, email = ?, status = ?, store_id = ?
+			SET nip = ?, username = ?, password = ?, name = ?, email = ?, status = ?, must_change_password = ?
 			WHERE id = ?
-		`, params.NIP, params.Username, params.HashedPassword, params.Name, emailVal, params.Status, string(storeJSON), params.ID); err != nil {
+		`, params.NIP, params.Username, params.HashedPassword, params.Name, emailVal, params.Status, params.MustChangePassword, params.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := insertPasswordHistory(tx, int64(params.ID), params.HashedPassword); err != nil {
 			tx.Rollback()
 			return err
 		}
 	} else {
 		if _, err := tx.Exec(`
 			UPDATE users
-			SET nip = ?, username = ?, name = ?, email = ?, status = ?, store_id = ?
+			SET nip = ?, username = ?, name = ?, email = ?, status = ?, must_change_password = ?
 			WHERE id = ?
-		`, params.NIP, params.Username, params.Name, emailVal, params.Status, string(storeJSON), params.ID); err != nil {
+		`, params.NIP, params.Username, params.Name, emailVal, params.Status, params.MustChangePassword, params.ID); err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 
+	if err := replaceUserStores(tx, int64(params.ID), params.StoreIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM model_has_roles WHERE model_id = ? AND model_type = ?`, params.ID, userModelType); err != nil {
 		tx.Rollback()
 		return err
@@ -249,9 +323,90 @@ func (r *UserRepository) UpdateUserWithRoles(params UserUpdateParams, roleIDs []
 		}
 	}
 
+	after, err := userAuditSnapshot(tx, int64(params.ID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "user.update", "user", int64(params.ID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// GetStatus mengambil status user berdasarkan ID.
+func (r *UserRepository) GetStatus(id int) (string, error) {
+	var status string
+	err := r.DB.QueryRow(`SELECT status FROM users WHERE id = ?`, id).Scan(&status)
+	return status, err
+}
+
+// GetStoreIDs mengambil id store yang ditugaskan ke user. Mengembalikan sql.ErrNoRows jika user tidak ada.
+func (r *UserRepository) GetStoreIDs(id int) ([]int, error) {
+	var exists bool
+	if err := r.DB.QueryRow(`SELECT TRUE FROM users WHERE id = ?`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	return userStoreIDs(r.DB, int64(id))
+}
+
+// GetIDsByStore mengambil id seluruh user yang ditugaskan ke store storeID.
+func (r *UserRepository) GetIDsByStore(storeID int) ([]int, error) {
+	rows, err := r.DB.Query(`SELECT user_id FROM user_stores WHERE store_id = ? ORDER BY user_id`, storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// userStoreIDs membaca id store milik user dari user_stores, terurut.
+func userStoreIDs(q auditQueryer, userID int64) ([]int, error) {
+	rows, err := q.Query(`SELECT store_id FROM user_stores WHERE user_id = ? ORDER BY store_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	storeIDs := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		storeIDs = append(storeIDs, id)
+	}
+	return storeIDs, rows.Err()
+}
+
+// replaceUserStores mengganti seluruh store milik user dengan storeIDs.
+func replaceUserStores(tx *sql.Tx, userID int64, storeIDs []int) error {
+	if _, err := tx.Exec(`DELETE FROM user_stores WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	for _, storeID := range storeIDs {
+		if _, err := tx.Exec(`
+			INSERT IGNORE INTO user_stores (user_id, store_id)
+			VALUES (?, ?)
+		`, userID, storeID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ExistsByUsername mengecek apakah username sudah digunakan.
 func (r *UserRepository) ExistsByUsername(username string) (bool, error) {
 	var count int
@@ -302,6 +457,95 @@ func (r *UserRepository) ExistsByEmailExceptID(email string, id int) (bool, erro
 	return count > 0, err
 }
 
+// FindIDByEmail mengambil id user berdasarkan email; sql.ErrNoRows jika tidak ada.
+func (r *UserRepository) FindIDByEmail(email string) (int, error) {
+	var id int
+	err := r.DB.QueryRow(`SELECT id FROM users WHERE email = ? ORDER BY id LIMIT 1`, email).Scan(&id)
+	return id, err
+}
+
+// FindIDByNIP mengambil id user berdasarkan NIP; sql.ErrNoRows jika tidak ada.
+func (r *UserRepository) FindIDByNIP(nip int) (int, error) {
+	var id int
+	err := r.DB.QueryRow(`SELECT id FROM users WHERE nip = ? ORDER BY id LIMIT 1`, nip).Scan(&id)
+	return id, err
+}
+
+// UpdateProfile memperbarui nama dan email user (mis. hasil sinkronisasi direktori LDAP),
+// dicatat di audit log. Email kosong tidak mengubah email yang tersimpan.
+func (r *UserRepository) UpdateProfile(userID int, name, email string, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users SET name = ?, email = COALESCE(NULLIF(?, ''), email) WHERE id = ?
+	`, name, strings.TrimSpace(email), userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "user.update", "user", int64(userID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRoles mengganti seluruh role user tanpa menyentuh data lain, dicatat di audit log.
+func (r *UserRepository) ReplaceRoles(userID int, roleIDs []int64, actor models.AuditActor) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM model_has_roles WHERE model_id = ? AND model_type = ?`, userID, userModelType); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, roleID := range roleIDs {
+		if _, err := tx.Exec(`
+			INSERT INTO model_has_roles (role_id, model_type, model_id)
+			VALUES (?, ?, ?)
+		`, roleID, userModelType, userID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	after, err := userAuditSnapshot(tx, int64(userID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertAuditLog(tx, actor, "user.update", "user", int64(userID), before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // GetRoleIDsByNames mengambil role_id berdasarkan nama role yang diberikan.
 func (r *UserRepository) GetRoleIDsByNames(names []string) (map[string]int64, error) {
 	result := make(map[string]int64)
@@ -344,56 +588,6 @@ func (r *UserRepository) GetRoleIDsByNames(names []string) (map[string]int64, er
 	return result, rows.Err()
 }
 
-func (r *UserRepository) getStoreNames(ids []int) ([]string, error) {
-	if len(ids) == 0 {
-		return nil, nil
-	}
-
-	placeholders := make([]string, len(ids))
-	args := make([]interface{}, len(ids))
-
-	for i, id := range ids {
-		placeholders[i] = "?"
-		args[i] = id
-	}
-
-	query := "SELECT store_name FROM stores WHERE store_id IN (" + strings.Join(placeholders, ",") + ") ORDER BY store_name"
-
-	rows, err := r.DB.Query(query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var names []string
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			return nil, err
-		}
-		names = append(names, name)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return names, nil
-}
-
-func joinIntSlice(values []int) string {
-	if len(values) == 0 {
-		return ""
-	}
-
-	parts := make([]string, len(values))
-	for i, v := range values {
-		parts[i] = strconv.Itoa(v)
-	}
-
-	return strings.Join(parts, ", ")
-}
-
 func splitAndTrimCSV(val string) []string {
 	val = strings.TrimSpace(val)
 	if val == "" || val == "-" {
@@ -411,13 +605,20 @@ func splitAndTrimCSV(val string) []string {
 	return result
 }
 
-// DeleteUser removes a user and related role/permission mappings in a single transaction.
-func (r *UserRepository) DeleteUser(id int) error {
+// DeleteUser removes a user and related role/permission mappings in a single transaction,
+// recording the removed data in the audit log.
+func (r *UserRepository) DeleteUser(id int, actor models.AuditActor) error {
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
 	}
 
+	before, err := userAuditSnapshot(tx, int64(id))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM model_has_roles WHERE model_id = ? AND model_type = ?`, id, userModelType); err != nil {
 		tx.Rollback()
 		return err
@@ -428,11 +629,36 @@ func (r *UserRepository) DeleteUser(id int) error {
 		return err
 	}
 
+	if _, err := tx.Exec(`DELETE FROM model_has_store_permissions WHERE model_id = ? AND model_type = ?`, id, userModelType); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_stores WHERE user_id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM personal_access_tokens WHERE tokenable_id = ? AND tokenable_type = ?`, id, userModelType); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_identities WHERE user_id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM users WHERE id = ?`, id); err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	if err := insertAuditLog(tx, actor, "user.delete", "user", int64(id), before, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"gobase-app/controllers"
+	"gobase-app/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAPIRoutes mendaftarkan REST API JSON versi 1. Klien non-browser memakai personal access
+// token (header Authorization: Bearer); dengan cookie session, request yang mengubah data wajib
+// membawa header X-CSRF-Token. Pengecekan permission sama dengan halaman HTML.
+// Dipanggil setelah RegisterWebRoutes karena memakai middleware session, Bearer token & CSRF global.
+func RegisterAPIRoutes(r *gin.Engine) {
+	v1 := r.Group("/api/v1")
+	v1.Use(middleware.AuthRequired(), middleware.TwoFactorEnrollment(), middleware.PasswordChangeRequired(), middleware.StoreScope())
+	{
+		v1.GET("/users", middleware.RequirePermission("user_management_access"), controllers.APIUserIndex)
+		v1.GET("/users/:id", middleware.RequirePermission("user_management_access"), controllers.APIUserShow)
+		v1.POST("/users", middleware.RequirePermission("user_create"), controllers.APIUserStore)
+		v1.PUT("/users/:id", middleware.RequirePermission("user_edit"), controllers.APIUserUpdate)
+		v1.DELETE("/users/:id", middleware.RequirePermission("user_delete"), controllers.APIUserDestroy)
+
+		v1.GET("/roles", middleware.RequirePermission("role_view"), controllers.APIRoleIndex)
+		v1.GET("/roles/:id", middleware.RequirePermission("role_view"), controllers.APIRoleShow)
+		v1.POST("/roles", middleware.RequirePermission("role_create"), controllers.APIRoleStore)
+		v1.PUT("/roles/:id", middleware.RequirePermission("role_edit"), controllers.APIRoleUpdate)
+		v1.DELETE("/roles/:id", middleware.RequirePermission("role_delete"), controllers.APIRoleDestroy)
+
+		v1.GET("/permissions", middleware.RequirePermission("permission_view"), controllers.APIPermissionIndex)
+		v1.PUT("/permissions/:id", middleware.RequirePermission("permission_management_access"), controllers.APIPermissionUpdate)
+		v1.POST("/permissions/groups/rename", middleware.RequirePermission("permission_management_access"), controllers.APIPermissionGroupRename)
+		v1.DELETE("/permissions/:id", middleware.RequirePermission("permission_management_access"), controllers.APIPermissionDestroy)
+
+		v1.GET("/stores", middleware.RequirePermission("store_view"), controllers.APIStoreIndex)
+		v1.GET("/stores/:id", middleware.RequirePermission("store_view"), controllers.APIStoreShow)
+		v1.POST("/stores", middleware.RequirePermission("store_create"), controllers.APIStoreStore)
+		v1.PUT("/stores/:id", middleware.RequirePermission("store_edit"), controllers.APIStoreUpdate)
+		v1.DELETE("/stores/:id", middleware.RequirePermission("store_delete"), controllers.APIStoreDestroy)
+		v1.POST("/stores/:id/activate", middleware.RequirePermission("store_delete"), controllers.APIStoreActivate)
+	}
+}
@@ -0,0 +1,49 @@
+package routes
+
+import "gobase-app/permissions"
+
+// Permission yang dipakai route, template, dan API. Permission baru cukup ditambahkan di sini;
+// baris di tabel permissions dibuat otomatis saat aplikasi start (services.SyncPermissions).
+func init() {
+	permissions.Register(
+		permissions.Definition{Name: "permission_management_access", Group: "permission", Description: "Membuka halaman kelola permission"},
+		permissions.Definition{Name: "permission_view", Group: "permission", Description: "Melihat daftar permission lewat API"},
+		permissions.Definition{Name: "permission_assign", Group: "permission", Description: "Memberikan permission langsung ke user"},
+		permissions.Definition{Name: "permission_revoke", Group: "permission", Description: "Menolak permission untuk user tertentu"},
+
+		permissions.Definition{Name: "role_management_access", Group: "role", Description: "Menampilkan menu role"},
+		permissions.Definition{Name: "role_view", Group: "role", Description: "Melihat daftar dan detail role"},
+		permissions.Definition{Name: "role_create", Group: "role", Description: "Membuat role baru"},
+		permissions.Definition{Name: "role_edit", Group: "role", Description: "Mengubah role dan permission-nya"},
+		permissions.Definition{Name: "role_delete", Group: "role", Description: "Menghapus role"},
+
+		permissions.Definition{Name: "user_management_access", Group: "user", Description: "Membuka halaman kelola user"},
+		permissions.Definition{Name: "user_view", Group: "user", Description: "Melihat detail user"},
+		permissions.Definition{Name: "user_create", Group: "user", Description: "Membuat user dan menyetujui registrasi"},
+		permissions.Definition{Name: "user_edit", Group: "user", Description: "Mengubah data user"},
+		permissions.Definition{Name: "user_delete", Group: "user", Description: "Menghapus user"},
+		permissions.Definition{Name: "user_unlock", Group: "user", Description: "Membuka kunci akun yang terkunci"},
+		permissions.Definition{Name: "user_session_access", Group: "user", Description: "Melihat dan mengakhiri session user lain"},
+
+		permissions.Definition{Name: "store_view", Group: "store", Description: "Membuka halaman kelola store"},
+		permissions.Definition{Name: "store_create", Group: "store", Description: "Membuat store baru"},
+		permissions.Definition{Name: "store_edit", Group: "store", Description: "Mengubah kode, nama, dan alamat store"},
+		permissions.Definition{Name: "store_delete", Group: "store", Description: "Menonaktifkan dan mengaktifkan kembali store"},
+
+		permissions.Definition{Name: "item_view", Group: "item", Description: "Membuka katalog hadiah"},
+		permissions.Definition{Name: "item_create", Group: "item", Description: "Menambah item hadiah"},
+		permissions.Definition{Name: "item_edit", Group: "item", Description: "Mengubah data dan gambar item hadiah"},
+		permissions.Definition{Name: "item_delete", Group: "item", Description: "Menonaktifkan dan mengaktifkan kembali item hadiah"},
+
+		permissions.Definition{Name: "stock_view", Group: "stock", Description: "Melihat saldo dan ledger stok di store milik user"},
+		permissions.Definition{Name: "stock_receive", Group: "stock", Description: "Mencatat penerimaan stok"},
+		permissions.Definition{Name: "stock_issue", Group: "stock", Description: "Mencatat pengeluaran hadiah"},
+		permissions.Definition{Name: "stock_adjust", Group: "stock", Description: "Menyesuaikan stok dengan hasil hitung fisik"},
+		permissions.Definition{Name: "stock_transfer", Group: "stock", Description: "Mentransfer stok ke store lain"},
+
+		permissions.Definition{Name: "audit_log_access", Group: "audit", Description: "Melihat dan mengekspor audit log"},
+
+		permissions.Definition{Name: "system_settings_access", Group: "system_settings", Description: "Membuka pengaturan sistem"},
+		permissions.Definition{Name: "app_settings_manage", Group: "app_settings", Description: "Mengubah pengaturan aplikasi"},
+	)
+}
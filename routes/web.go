@@ -8,29 +8,90 @@ import (
 )
 
 func RegisterWebRoutes(r *gin.Engine) {
-	r.Use(middleware.UserMiddleware())
+	r.Use(middleware.UserMiddleware(), middleware.BearerToken(), middleware.CSRF())
 
 	r.GET("/", controllers.LoginPage)
 	r.GET("/login", controllers.LoginPage)
 	r.POST("/login", controllers.LoginPost)
-	r.POST("/register", controllers.CreateUser)
-	r.GET("/logout", controllers.Logout)
+	r.GET("/login/2fa", controllers.TwoFactorChallengePage)
+	r.POST("/login/2fa", controllers.TwoFactorChallengePost)
+	r.GET("/login/oidc", middleware.OIDCEnabled(), controllers.OIDCLogin)
+	r.GET("/login/oidc/callback", middleware.OIDCEnabled(), controllers.OIDCCallback)
+	r.GET("/register", middleware.RegistrationEnabled(), controllers.RegisterPage)
+	r.POST("/register", middleware.RegistrationEnabled(), controllers.RegisterPost)
+	r.GET("/register/verify", middleware.RegistrationEnabled(), controllers.RegisterVerify)
+	r.GET("/password/forgot", controllers.ForgotPasswordPage)
+	r.POST("/password/forgot", controllers.ForgotPasswordPost)
+	r.GET("/password/reset", controllers.ResetPasswordPage)
+	r.POST("/password/reset", controllers.ResetPasswordPost)
+	r.POST("/logout", controllers.Logout)
 
 	auth := r.Group("/")
-	auth.Use(middleware.AuthRequired(), middleware.PermissionContext())
+	auth.Use(middleware.AuthRequired(), middleware.TwoFactorEnrollment(), middleware.PasswordChangeRequired(), middleware.PermissionContext(), middleware.StoreScope())
 	{
 		auth.GET("/dashboard", controllers.DashboardIndex)
 
+		auth.GET("/profile", controllers.ProfileIndex)
+		auth.POST("/profile/sessions/revoke", controllers.ProfileSessionRevoke)
+		auth.POST("/profile/sessions/revoke-others", controllers.ProfileSessionRevokeOthers)
+
+		auth.GET("/profile/tokens", controllers.ProfileTokenIndex)
+		auth.POST("/profile/tokens", controllers.ProfileTokenStore)
+		auth.POST("/profile/tokens/:id/revoke", controllers.ProfileTokenRevoke)
+
+		auth.GET("/profile/password", controllers.ProfilePasswordPage)
+		auth.POST("/profile/password", controllers.ProfilePasswordUpdate)
+
+		auth.GET("/profile/2fa", controllers.TwoFactorSetupPage)
+		auth.POST("/profile/2fa/enable", controllers.TwoFactorEnable)
+		auth.POST("/profile/2fa/recovery-codes", controllers.TwoFactorRecoveryCodes)
+		auth.POST("/profile/2fa/disable", controllers.TwoFactorDisable)
+
 		auth.GET("/users", middleware.RequirePermission("user_management_access"), controllers.UserIndex)
 		auth.POST("/users", middleware.RequirePermission("user_create"), controllers.UserStore)
 		auth.POST("/users/update", middleware.RequirePermission("user_edit"), controllers.UserUpdate)
-		auth.GET("/users/delete/:id", middleware.RequirePermission("user_delete"), controllers.UserDelete)
+		auth.POST("/users/delete/:id", middleware.RequirePermission("user_delete"), controllers.UserDelete)
+		auth.POST("/users/approve/:id", middleware.RequirePermission("user_create"), controllers.UserApprove)
+		auth.GET("/users/:id/sessions", middleware.RequirePermission("user_session_access"), controllers.UserSessionIndex)
+		auth.POST("/users/:id/sessions/revoke", middleware.RequirePermission("user_session_access"), controllers.UserSessionRevoke)
+		auth.POST("/users/:id/sessions/revoke-others", middleware.RequirePermission("user_session_access"), controllers.UserSessionRevokeOthers)
+		auth.GET("/users/:id/permissions", middleware.RequireAnyPermission("permission_assign", "permission_revoke"), controllers.UserPermissionIndex)
+		auth.POST("/users/:id/permissions/grant", middleware.RequirePermission("permission_assign"), controllers.UserPermissionGrant)
+		auth.POST("/users/:id/permissions/grant/remove", middleware.RequirePermission("permission_assign"), controllers.UserPermissionRemoveGrant)
+		auth.POST("/users/:id/permissions/deny", middleware.RequirePermission("permission_revoke"), controllers.UserPermissionDeny)
+		auth.POST("/users/:id/permissions/deny/remove", middleware.RequirePermission("permission_revoke"), controllers.UserPermissionRemoveDeny)
+		auth.POST("/users/:id/permissions/store", middleware.RequirePermission("permission_assign"), controllers.UserPermissionStoreGrant)
+		auth.POST("/users/:id/permissions/store/remove", middleware.RequirePermission("permission_revoke"), controllers.UserPermissionStoreRevoke)
+		auth.GET("/users/locked", middleware.RequirePermission("user_unlock"), controllers.UserLockedIndex)
+		auth.POST("/users/unlock", middleware.RequirePermission("user_unlock"), controllers.UserUnlock)
+		auth.GET("/stores", middleware.RequirePermission("store_view"), controllers.StoreIndex)
+		auth.POST("/stores", middleware.RequirePermission("store_create"), controllers.StoreStore)
+		auth.POST("/stores/update", middleware.RequirePermission("store_edit"), controllers.StoreUpdate)
+		auth.POST("/stores/:id/deactivate", middleware.RequirePermission("store_delete"), controllers.StoreDeactivate)
+		auth.POST("/stores/:id/activate", middleware.RequirePermission("store_delete"), controllers.StoreActivate)
+		auth.GET("/items", middleware.RequirePermission("item_view"), controllers.ItemIndex)
+		auth.POST("/items", middleware.RequirePermission("item_create"), controllers.ItemStore)
+		auth.POST("/items/update", middleware.RequirePermission("item_edit"), controllers.ItemUpdate)
+		auth.POST("/items/:id/deactivate", middleware.RequirePermission("item_delete"), controllers.ItemDeactivate)
+		auth.POST("/items/:id/activate", middleware.RequirePermission("item_delete"), controllers.ItemActivate)
+		auth.GET("/stock", middleware.RequireStorePermissionAny("stock_view"), controllers.StockIndex)
+		auth.GET("/stock/movements", middleware.RequireStorePermissionAny("stock_view"), controllers.StockMovementIndex)
+		auth.POST("/stock/receipt", middleware.RequireStorePermission("stock_receive"), controllers.StockReceive)
+		auth.POST("/stock/issue", middleware.RequireStorePermission("stock_issue"), controllers.StockIssue)
+		auth.POST("/stock/adjustment", middleware.RequireStorePermission("stock_adjust"), controllers.StockAdjust)
+		auth.POST("/stock/transfer", middleware.RequireStorePermission("stock_transfer"), controllers.StockTransfer)
+		auth.GET("/audit", middleware.RequirePermission("audit_log_access"), controllers.AuditIndex)
+		auth.GET("/audit/export", middleware.RequirePermission("audit_log_access"), controllers.AuditExport)
+		auth.GET("/permissions", middleware.RequirePermission("permission_management_access"), controllers.PermissionIndex)
+		auth.POST("/permissions/update", middleware.RequirePermission("permission_management_access"), controllers.PermissionUpdate)
+		auth.POST("/permissions/groups/rename", middleware.RequirePermission("permission_management_access"), controllers.PermissionGroupRename)
+		auth.POST("/permissions/delete/:id", middleware.RequirePermission("permission_management_access"), controllers.PermissionDelete)
 		auth.GET("/role", controllers.RoleIndex)
 		auth.GET("/roleForm", controllers.RoleFormIndex)
 		auth.GET("/role/:id/edit", middleware.RequirePermission("role_edit"), controllers.RoleEdit)
 		auth.POST("/role", middleware.RequirePermission("role_create"), controllers.RoleStore)
 		auth.POST("/role/update", middleware.RequirePermission("role_edit"), controllers.RoleUpdate)
-		auth.GET("/role/delete/:id", middleware.RequirePermission("role_delete"), controllers.RoleDelete)
+		auth.POST("/role/delete/:id", middleware.RequirePermission("role_delete"), controllers.RoleDelete)
 	}
 }
 
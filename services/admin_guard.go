@@ -0,0 +1,68 @@
+package services
+
+import (
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+)
+
+// IsAdminUser mengecek apakah user memiliki role is_admin. Admin lolos semua pengecekan
+// permission dan satu-satunya yang boleh mengelola role admin maupun user admin.
+func IsAdminUser(userID int) (bool, error) {
+	if userID <= 0 {
+		return false, nil
+	}
+	repo := &repositories.RoleRepository{DB: config.DB}
+	return repo.UserIsAdmin(userID)
+}
+
+// requireAdminActor menolak perubahan yang menyangkut role/user admin jika principal bukan admin.
+// Admin yang memakai token API hanya dianggap admin jika ability token mencakup seluruh permission.
+func requireAdminActor(roles *repositories.RoleRepository, principal models.Principal, what string) error {
+	if principal.UserID > 0 {
+		isAdmin, err := roles.UserIsAdmin(principal.UserID)
+		if err != nil {
+			return err
+		}
+		if isAdmin && principal.Token {
+			all, err := (&repositories.PermissionRepository{DB: roles.DB}).GetAll()
+			if err != nil {
+				return err
+			}
+			for _, perm := range all {
+				if !principal.Allows(perm.Name) {
+					return forbiddenf("token API ini tidak boleh %s karena ability-nya tidak mencakup seluruh permission", what)
+				}
+			}
+		}
+		if isAdmin {
+			return nil
+		}
+	}
+	return forbiddenf("hanya admin yang boleh %s", what)
+}
+
+// ensureAdminRemains memastikan masih ada user aktif dengan role admin setelah role excludeRoleID
+// tidak lagi admin atau user excludeUserID tidak lagi admin (0 = tidak ada yang dikecualikan).
+func ensureAdminRemains(roles *repositories.RoleRepository, excludeRoleID, excludeUserID int) error {
+	count, err := roles.CountActiveAdminUsers(excludeRoleID, excludeUserID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return conflictf("perubahan ditolak karena tidak akan ada lagi user admin yang aktif")
+	}
+	return nil
+}
+
+// ensureOtherAdminRole menolak penghapusan atau penurunan role admin terakhir.
+func ensureOtherAdminRole(roles *repositories.RoleRepository, roleID int) error {
+	count, err := roles.CountAdminRoles(roleID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return conflictf("role admin terakhir tidak boleh dihapus atau dijadikan role biasa")
+	}
+	return ensureAdminRemains(roles, roleID, 0)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditPerPage adalah jumlah baris audit log per halaman.
+const AuditPerPage = 50
+
+type AuditService struct {
+	Repo *repositories.AuditRepository
+}
+
+// Search mengambil satu halaman audit log sesuai filter beserta total baris.
+func (s *AuditService) Search(filter models.AuditFilter) ([]models.AuditLog, int, error) {
+	filter, err := normalizeAuditFilter(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	filter.PerPage = AuditPerPage
+
+	return s.Repo.Search(filter)
+}
+
+// GetActions mengambil daftar action untuk pilihan filter.
+func (s *AuditService) GetActions() ([]string, error) {
+	return s.Repo.GetActions()
+}
+
+// ExportCSV menulis seluruh audit log yang cocok dengan filter dalam format CSV.
+func (s *AuditService) ExportCSV(filter models.AuditFilter, w io.Writer) error {
+	filter, err := normalizeAuditFilter(filter)
+	if err != nil {
+		return err
+	}
+	filter.PerPage = 0
+
+	logs, _, err := s.Repo.Search(filter)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"id", "created_at", "actor_id", "actor_name", "action", "entity_type", "entity_id",
+		"old_values", "new_values", "ip_address", "user_agent",
+	}); err != nil {
+		return err
+	}
+
+	for _, l := range logs {
+		if err := writer.Write([]string{
+			strconv.FormatInt(l.ID, 10),
+			l.CreatedAt,
+			strconv.Itoa(l.ActorID),
+			csvSafe(l.ActorName),
+			l.Action,
+			l.EntityType,
+			strconv.FormatInt(l.EntityID, 10),
+			l.OldValues,
+			l.NewValues,
+			l.IPAddress,
+			csvSafe(l.UserAgent),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func normalizeAuditFilter(filter models.AuditFilter) (models.AuditFilter, error) {
+	filter.Actor = strings.TrimSpace(filter.Actor)
+	filter.Action = strings.TrimSpace(filter.Action)
+	filter.EntityType = strings.TrimSpace(filter.EntityType)
+
+	for _, date := range []string{filter.DateFrom, filter.DateTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return filter, errors.New("format tanggal tidak valid, gunakan YYYY-MM-DD")
+		}
+	}
+
+	return filter, nil
+}
+
+// csvSafe mencegah formula injection saat CSV dibuka di aplikasi spreadsheet.
+func csvSafe(val string) string {
+	if val != "" && strings.ContainsAny(val[:1], "=+-@") {
+		return "'" + val
+	}
+	return val
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var auditColumns = []string{"id", "actor_id", "actor_name", "action", "entity_type", "entity_id",
+	"old_values", "new_values", "ip_address", "user_agent", "created_at"}
+
+func TestAuditExportCSV(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2026, 3, 2, 8, 15, 0, 0, time.UTC)
+	// export tidak dipaginasi, jadi query tidak memakai LIMIT
+	mock.ExpectQuery(`SELECT COUNT\(1\) FROM audit_logs WHERE actor_name LIKE \? AND created_at >= \?`).
+		WithArgs("%alice%", "2026-03-01 00:00:00").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`FROM audit_logs WHERE actor_name LIKE \? AND created_at >= \?\s+ORDER BY id DESC$`).
+		WithArgs("%alice%", "2026-03-01 00:00:00").
+		WillReturnRows(sqlmock.NewRows(auditColumns).
+			AddRow(12, 3, "alice", "item.update", "item", 11, `{"name":"Mug"}`, `{"name":"Mug, Kampus"}`, "10.0.0.2", "=HYPERLINK(\"x\")", createdAt).
+			AddRow(11, 0, "@alice", "user.create", "user", 7, "", `{"username":"alice"}`, "10.0.0.3", "Mozilla/5.0", createdAt))
+
+	svc := &AuditService{Repo: &repositories.AuditRepository{DB: db}}
+	var out bytes.Buffer
+	if err := svc.ExportCSV(models.AuditFilter{Actor: " alice ", DateFrom: "2026-03-01", Page: 3}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"id,created_at,actor_id,actor_name,action,entity_type,entity_id,old_values,new_values,ip_address,user_agent",
+		`12,2026-03-02 08:15:00,3,alice,item.update,item,11,"{""name"":""Mug""}","{""name"":""Mug, Kampus""}",10.0.0.2,"'=HYPERLINK(""x"")"`,
+		`11,2026-03-02 08:15:00,0,'@alice,user.create,user,7,,"{""username"":""alice""}",10.0.0.3,Mozilla/5.0`,
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", out.String(), want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAuditSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    models.AuditFilter
+		wantWhere []driver.Value
+		wantLimit []driver.Value
+		wantErr   bool
+	}{
+		{
+			name:      "halaman pertama",
+			filter:    models.AuditFilter{Action: " user.update "},
+			wantWhere: []driver.Value{"user.update"},
+			wantLimit: []driver.Value{AuditPerPage, 0},
+		},
+		{
+			name:      "halaman berikutnya sampai akhir hari",
+			filter:    models.AuditFilter{DateTo: "2026-03-02", Page: 3},
+			wantWhere: []driver.Value{"2026-03-02 23:59:59"},
+			wantLimit: []driver.Value{AuditPerPage, 2 * AuditPerPage},
+		},
+		{name: "tanggal tidak valid", filter: models.AuditFilter{DateFrom: "02/03/2026"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if !tt.wantErr {
+				mock.ExpectQuery(`SELECT COUNT\(1\) FROM audit_logs WHERE`).WithArgs(tt.wantWhere...).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(`ORDER BY id DESC LIMIT \? OFFSET \?`).
+					WithArgs(append(append([]driver.Value{}, tt.wantWhere...), tt.wantLimit...)...).
+					WillReturnRows(sqlmock.NewRows(auditColumns))
+			}
+
+			svc := &AuditService{Repo: &repositories.AuditRepository{DB: db}}
+			_, _, err = svc.Search(tt.filter)
+			switch {
+			case tt.wantErr && (err == nil || !strings.Contains(err.Error(), "YYYY-MM-DD")):
+				t.Fatalf("err = %v, want format tanggal ditolak", err)
+			case !tt.wantErr && err != nil:
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := map[string]string{
+		"alice":         "alice",
+		"":              "",
+		"=1+1":          "'=1+1",
+		"+62812":        "'+62812",
+		"-2":            "'-2",
+		"@SUM(A1)":      "'@SUM(A1)",
+		"Mozilla=5.0 +": "Mozilla=5.0 +",
+	}
+	for in, want := range tests {
+		if got := csvSafe(in); got != want {
+			t.Errorf("csvSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/sessionstore"
+	"time"
+)
+
+// AuthEventHistoryLimit adalah jumlah event login terakhir yang ditampilkan di halaman profil.
+const AuthEventHistoryLimit = 20
+
+// ErrSessionNotFound dikembalikan ketika session yang ingin diakhiri tidak ada atau bukan milik user.
+var ErrSessionNotFound = errors.New("session tidak ditemukan atau sudah berakhir")
+
+var authEventLabels = map[string]string{
+	models.AuthEventLoginSuccess:   "Login berhasil",
+	models.AuthEventLoginFailed:    "Login gagal",
+	models.AuthEventLogout:         "Logout",
+	models.AuthEventSessionExpired: "Session kedaluwarsa",
+	models.AuthEventSessionRevoked: "Session diakhiri",
+}
+
+// AuthEventService mencatat event autentikasi (login, logout, session berakhir) dan
+// mengelola daftar session aktif milik user.
+type AuthEventService struct {
+	Repo *repositories.AuthEventRepository
+}
+
+// Record menyimpan satu event autentikasi.
+func (s *AuthEventService) Record(e models.AuthEvent) error {
+	if _, ok := authEventLabels[e.Event]; !ok {
+		return errors.New("jenis event autentikasi tidak dikenal")
+	}
+	return s.Repo.Insert(e)
+}
+
+// RecordSessionEnded mencatat berakhirnya session (kedaluwarsa / diakhiri) dengan IP dan
+// user agent perangkat pemilik session tersebut.
+func (s *AuthEventService) RecordSessionEnded(event string, sess models.Session, at time.Time) error {
+	return s.Record(models.AuthEvent{
+		UserID:    sess.UserID,
+		Event:     event,
+		IPAddress: sess.IPAddress,
+		UserAgent: sess.UserAgent,
+		CreatedAt: at,
+	})
+}
+
+// RecentEvents mengambil event login terbaru milik user lengkap dengan label tampilan.
+func (s *AuthEventService) RecentEvents(userID int) ([]models.AuthEvent, error) {
+	if userID <= 0 {
+		return nil, errors.New("user tidak valid")
+	}
+
+	events, err := s.Repo.RecentByUserID(userID, AuthEventHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		events[i].EventLabel = authEventLabels[events[i].Event]
+		events[i].CreatedAtDisplay = events[i].CreatedAt.Format("02 Jan 2006 15:04:05")
+	}
+	return events, nil
+}
+
+// ActiveSessions mengambil session aktif milik user. currentID menandai session yang sedang dipakai.
+func (s *AuthEventService) ActiveSessions(userID int, currentID string) ([]models.ActiveSession, error) {
+	if userID <= 0 {
+		return nil, errors.New("user tidak valid")
+	}
+
+	sessions, err := sessionstore.UserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.ActiveSession, 0, len(sessions))
+	for _, sess := range sessions {
+		result = append(result, models.ActiveSession{
+			Key:                 sessionstore.SessionKey(sess.ID),
+			IPAddress:           sess.IPAddress,
+			UserAgent:           sess.UserAgent,
+			LastActivityDisplay: sess.LastActivity.Format("02 Jan 2006 15:04:05"),
+			ExpiresAtDisplay:    sess.ExpiresAt.Format("02 Jan 2006 15:04:05"),
+			Current:             currentID != "" && sess.ID == currentID,
+		})
+	}
+	return result, nil
+}
+
+// GuardUserSessions memastikan session user admin hanya bisa diakhiri oleh admin, sama seperti
+// perubahan lain terhadap user admin.
+func (s *AuthEventService) GuardUserSessions(userID int, principal models.Principal) error {
+	roles := &repositories.RoleRepository{DB: s.Repo.DB}
+	isAdmin, err := roles.UserIsAdmin(userID)
+	if err != nil || !isAdmin {
+		return err
+	}
+	return requireAdminActor(roles, principal, "mengakhiri session user admin")
+}
+
+// EndSession mengakhiri satu session milik user berdasarkan key dari halaman profil.
+// Session yang sedang dipakai (currentID) tidak boleh diakhiri lewat jalur ini; gunakan logout.
+func (s *AuthEventService) EndSession(userID int, key, currentID string) error {
+	if userID <= 0 || key == "" {
+		return ErrSessionNotFound
+	}
+	if currentID != "" && key == sessionstore.SessionKey(currentID) {
+		return errors.New("gunakan logout untuk mengakhiri session yang sedang dipakai")
+	}
+
+	sess, err := sessionstore.RevokeSession(userID, key)
+	if err != nil {
+		return err
+	}
+	if sess == nil {
+		return ErrSessionNotFound
+	}
+	return s.RecordSessionEnded(models.AuthEventSessionRevoked, *sess, time.Now())
+}
+
+// EndOtherSessions mengakhiri seluruh session user kecuali currentID dan mengembalikan jumlahnya.
+// currentID kosong berarti seluruh session user diakhiri.
+func (s *AuthEventService) EndOtherSessions(userID int, currentID string) (int, error) {
+	if userID <= 0 {
+		return 0, errors.New("user tidak valid")
+	}
+
+	revoked, err := sessionstore.RevokeOtherSessions(userID, currentID)
+	now := time.Now()
+	for _, sess := range revoked {
+		if recErr := s.RecordSessionEnded(models.AuthEventSessionRevoked, sess, now); recErr != nil && err == nil {
+			err = recErr
+		}
+	}
+	return len(revoked), err
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"log"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials dikembalikan authenticator jika username atau password salah, atau user
+// tidak aktif. Pesannya sengaja sama agar tidak membocorkan username mana yang terdaftar.
+var ErrInvalidCredentials = errors.New("username atau password salah")
+
+// ErrAuthUnavailable membungkus gangguan di sisi server autentikasi (mis. LDAP tidak bisa
+// di-dial, TLS gagal, atau bind akun layanan ditolak). Gangguan ini bukan kesalahan user sehingga
+// tidak dihitung sebagai percobaan login gagal.
+var ErrAuthUnavailable = errors.New("layanan autentikasi tidak bisa dihubungi")
+
+// Authenticator memverifikasi username dan password lalu mengembalikan id user lokal.
+// actor dipakai untuk audit log jika authenticator ikut menyinkronkan data user.
+type Authenticator interface {
+	Name() string
+	Authenticate(username, password string, actor models.AuditActor) (int, error)
+}
+
+// LoginAuthenticators menyusun authenticator berdasarkan AUTH_DRIVERS (dipisah koma, dicoba
+// berurutan), mis. "ldap,local" agar admin lokal tetap bisa login saat LDAP bermasalah.
+// Default "local".
+func LoginAuthenticators() []Authenticator {
+	userRepo := &repositories.UserRepository{DB: config.DB}
+
+	var result []Authenticator
+	for _, driver := range config.EnvList("AUTH_DRIVERS") {
+		switch strings.ToLower(driver) {
+		case "local":
+			result = append(result, &LocalAuthenticator{Repo: userRepo})
+		case "ldap":
+			result = append(result, &LDAPAuthenticator{Repo: userRepo, Config: LoadLDAPConfig()})
+		default:
+			log.Printf("unknown AUTH_DRIVERS entry %q ignored", driver)
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, &LocalAuthenticator{Repo: userRepo})
+	}
+	return result
+}
+
+// Authenticate mencoba setiap authenticator berurutan dan mengembalikan user pertama yang berhasil.
+// Gangguan pada satu authenticator (mis. server LDAP mati) tidak menghentikan authenticator
+// berikutnya; error tersebut baru dikembalikan jika tidak ada yang berhasil. Error ErrAuthUnavailable
+// hanya dikembalikan jika tidak ada authenticator lain yang menolak kredensial, agar tebakan
+// password ke authenticator yang masih hidup (mis. local) tetap dihitung oleh throttle login.
+func Authenticate(authenticators []Authenticator, username, password string, actor models.AuditActor) (int, error) {
+	var (
+		lastErr     error
+		unavailable error
+		rejected    bool
+	)
+	for _, auth := range authenticators {
+		userID, err := auth.Authenticate(username, password, actor)
+		switch {
+		case err == nil:
+			return userID, nil
+		case errors.Is(err, ErrInvalidCredentials):
+			rejected = true
+		case errors.Is(err, ErrAuthUnavailable):
+			log.Printf("authenticator %s: %v", auth.Name(), err)
+			unavailable = err
+		default:
+			log.Printf("authenticator %s: %v", auth.Name(), err)
+			lastErr = err
+		}
+	}
+
+	if lastErr != nil {
+		return 0, lastErr
+	}
+	if unavailable != nil && !rejected {
+		return 0, unavailable
+	}
+	return 0, ErrInvalidCredentials
+}
+
+// dummyPasswordHash dipakai saat username tidak ditemukan agar waktu respon tetap sama
+// dan tidak membocorkan username mana yang terdaftar.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// LocalAuthenticator memverifikasi password terhadap hash bcrypt di tabel users.
+type LocalAuthenticator struct {
+	Repo *repositories.UserRepository
+}
+
+func (a *LocalAuthenticator) Name() string {
+	return "local"
+}
+
+// Authenticate tetap menjalankan bcrypt walau user tidak ada agar waktu respon seragam.
+func (a *LocalAuthenticator) Authenticate(username, password string, actor models.AuditActor) (int, error) {
+	userID, hash, err := a.Repo.FindActiveCredentials(username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	userFound := err == nil
+	if !userFound {
+		hash = string(dummyPasswordHash)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil || !userFound {
+		return 0, ErrInvalidCredentials
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"gobase-app/models"
+	"testing"
+)
+
+type stubAuthenticator struct {
+	name   string
+	userID int
+	err    error
+	calls  int
+}
+
+func (a *stubAuthenticator) Name() string {
+	return a.name
+}
+
+func (a *stubAuthenticator) Authenticate(username, password string, actor models.AuditActor) (int, error) {
+	a.calls++
+	return a.userID, a.err
+}
+
+func TestAuthenticateChain(t *testing.T) {
+	ldapDown := fmt.Errorf("%w: gagal terhubung ke server LDAP", ErrAuthUnavailable)
+	dbDown := errors.New("koneksi database terputus")
+
+	tests := []struct {
+		name      string
+		chain     []*stubAuthenticator
+		wantID    int
+		wantErr   error
+		wantCalls []int
+	}{
+		{
+			name:      "authenticator pertama berhasil, sisanya tidak dicoba",
+			chain:     []*stubAuthenticator{{name: "ldap", userID: 7}, {name: "local", userID: 9}},
+			wantID:    7,
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:      "ldap mati, local berhasil",
+			chain:     []*stubAuthenticator{{name: "ldap", err: ldapDown}, {name: "local", userID: 9}},
+			wantID:    9,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "ldap menolak, local berhasil",
+			chain:     []*stubAuthenticator{{name: "ldap", err: ErrInvalidCredentials}, {name: "local", userID: 9}},
+			wantID:    9,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "hanya ldap dan ldap mati",
+			chain:     []*stubAuthenticator{{name: "ldap", err: ldapDown}},
+			wantErr:   ErrAuthUnavailable,
+			wantCalls: []int{1},
+		},
+		{
+			name:      "ldap mati dan local menolak tetap dihitung kredensial salah",
+			chain:     []*stubAuthenticator{{name: "ldap", err: ldapDown}, {name: "local", err: ErrInvalidCredentials}},
+			wantErr:   ErrInvalidCredentials,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "semua menolak",
+			chain:     []*stubAuthenticator{{name: "ldap", err: ErrInvalidCredentials}, {name: "local", err: ErrInvalidCredentials}},
+			wantErr:   ErrInvalidCredentials,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "error sistem dikembalikan apa adanya",
+			chain:     []*stubAuthenticator{{name: "ldap", err: ldapDown}, {name: "local", err: dbDown}},
+			wantErr:   dbDown,
+			wantCalls: []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chain []Authenticator
+			for _, auth := range tt.chain {
+				chain = append(chain, auth)
+			}
+
+			userID, err := Authenticate(chain, "alice", "rahasia", models.AuditActor{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if userID != tt.wantID {
+				t.Errorf("userID = %d, want %d", userID, tt.wantID)
+			}
+			if tt.wantErr == ErrInvalidCredentials && errors.Is(err, ErrAuthUnavailable) {
+				t.Errorf("err = %v, kredensial salah tidak boleh dianggap layanan mati", err)
+			}
+			for i, auth := range tt.chain {
+				if auth.calls != tt.wantCalls[i] {
+					t.Errorf("%s dipanggil %d kali, want %d", auth.name, auth.calls, tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// delegationGuard mencegah eskalasi hak akses: actor hanya boleh memberikan role atau permission
+// yang seluruh permission-nya juga ia miliki, hanya boleh mengelola user yang store-nya beririsan
+// dengan store actor, dan hanya boleh menugaskan store milik actor sendiri. Actor admin lolos
+// pengecekan store; pengecekan permission juga dilewati kecuali actor memakai token API, karena
+// permission actor lewat token dibatasi ability token tersebut.
+type delegationGuard struct {
+	roles   *repositories.RoleRepository
+	perms   *repositories.PermissionRepository
+	isAdmin bool
+	// allPermissions bernilai true untuk admin yang login biasa (tanpa batasan ability token)
+	allPermissions bool
+	granted        map[string]bool // permission efektif actor, sudah dibatasi ability token
+	stores         map[int]bool    // store milik actor
+	// permission actor yang hanya berlaku di store tertentu: nama permission -> store
+	storeGrants map[string][]int
+}
+
+func newDelegationGuard(db *sql.DB, principal models.Principal) (*delegationGuard, error) {
+	g := &delegationGuard{
+		roles:   &repositories.RoleRepository{DB: db},
+		perms:   &repositories.PermissionRepository{DB: db},
+		granted: map[string]bool{},
+		stores:  map[int]bool{},
+	}
+	if principal.UserID <= 0 {
+		return g, nil
+	}
+
+	isAdmin, err := g.roles.UserIsAdmin(principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	g.isAdmin = isAdmin
+	g.allPermissions = isAdmin && !principal.Token
+	if g.allPermissions {
+		return g, nil
+	}
+
+	if g.granted, err = GetUserPermissions(principal.UserID); err != nil {
+		return nil, err
+	}
+	for name := range g.granted {
+		if !principal.Allows(name) {
+			delete(g.granted, name)
+		}
+	}
+	if isAdmin {
+		return g, nil
+	}
+
+	storeIDs, err := (&repositories.UserRepository{DB: db}).GetStoreIDs(principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range storeIDs {
+		g.stores[id] = true
+	}
+
+	if g.storeGrants, err = g.perms.GetStorePermissionGrants(principal.UserID); err != nil {
+		return nil, err
+	}
+	for name := range g.storeGrants {
+		if !principal.Allows(name) {
+			delete(g.storeGrants, name)
+		}
+	}
+	return g, nil
+}
+
+// ensurePermissions menolak permIDs yang tidak dimiliki actor.
+func (g *delegationGuard) ensurePermissions(permIDs []int64) error {
+	missing, err := g.missingPermissions(permIDs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return invalidf("tidak bisa memberikan permission yang tidak Anda miliki: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ensureStorePermission menolak pemberian permission permID khusus store storeID jika actor tidak
+// memegang store itu atau tidak memiliki permission tersebut di sana (global maupun per store).
+func (g *delegationGuard) ensureStorePermission(permID int64, storeID int) error {
+	if g.allPermissions {
+		return nil
+	}
+	if !g.isAdmin && !g.stores[storeID] {
+		return invalidf("Anda hanya bisa memberikan permission di store milik Anda sendiri")
+	}
+
+	perm, err := g.perms.GetByID(permID)
+	if err != nil {
+		return err
+	}
+	if g.granted[perm.Name] {
+		return nil
+	}
+	for _, id := range g.storeGrants[perm.Name] {
+		if id == storeID {
+			return nil
+		}
+	}
+	return invalidf("tidak bisa memberikan permission yang tidak Anda miliki di store tersebut: %s", perm.Name)
+}
+
+// ensureRolePermissions menolak perubahan role yang menambah permission efektif (milik sendiri
+// maupun warisan parent) di luar permission actor. Permission yang sudah dimiliki role sebelumnya
+// (roleID, 0 untuk role baru) tidak diperiksa ulang agar role tetap bisa diubah hal lainnya.
+func (g *delegationGuard) ensureRolePermissions(roleID int, permIDs []int64, parentIDs []int) error {
+	if g.allPermissions {
+		return nil
+	}
+
+	after, err := g.rolePermissionSet(parentIDs)
+	if err != nil {
+		return err
+	}
+	for _, id := range permIDs {
+		after[id] = true
+	}
+
+	before := map[int64]bool{}
+	if roleID > 0 {
+		if before, err = g.rolePermissionSet([]int{roleID}); err != nil {
+			return err
+		}
+	}
+
+	var added []int64
+	for id := range after {
+		if !before[id] {
+			added = append(added, id)
+		}
+	}
+	return g.ensurePermissions(added)
+}
+
+// ensureRoles menolak pemberian role baru (yang belum dimiliki user, currentRoleIDs) jika role itu
+// beserta leluhurnya berisi permission yang tidak dimiliki actor.
+func (g *delegationGuard) ensureRoles(roleIDs []int64, currentRoleIDs []int) error {
+	if g.allPermissions {
+		return nil
+	}
+
+	current := make(map[int]bool, len(currentRoleIDs))
+	for _, id := range currentRoleIDs {
+		current[id] = true
+	}
+
+	names, err := g.roles.GetNames()
+	if err != nil {
+		return err
+	}
+
+	for _, roleID := range roleIDs {
+		if current[int(roleID)] {
+			continue
+		}
+
+		set, err := g.rolePermissionSet([]int{int(roleID)})
+		if err != nil {
+			return err
+		}
+		permIDs := make([]int64, 0, len(set))
+		for id := range set {
+			permIDs = append(permIDs, id)
+		}
+
+		missing, err := g.missingPermissions(permIDs)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return invalidf("tidak bisa memberikan role %s karena berisi permission yang tidak Anda miliki: %s",
+				names[int(roleID)], strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// ensureManageUser memastikan store user userID saat ini beririsan dengan store actor.
+func (g *delegationGuard) ensureManageUser(users *repositories.UserRepository, userID int) error {
+	if g.isAdmin {
+		return nil
+	}
+	storeIDs, err := users.GetStoreIDs(userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range storeIDs {
+		if g.stores[id] {
+			return nil
+		}
+	}
+	return invalidf("Anda hanya bisa mengelola user yang berada di store yang sama dengan Anda")
+}
+
+// ensureAssignStores menolak penugasan store baru (yang belum dimiliki user, currentStoreIDs) di
+// luar store milik actor.
+func (g *delegationGuard) ensureAssignStores(storeIDs, currentStoreIDs []int) error {
+	if g.isAdmin {
+		return nil
+	}
+
+	current := make(map[int]bool, len(currentStoreIDs))
+	for _, id := range currentStoreIDs {
+		current[id] = true
+	}
+
+	for _, id := range storeIDs {
+		if current[id] || g.stores[id] {
+			continue
+		}
+		name := strconv.Itoa(id)
+		store, err := (&repositories.StoreRepository{DB: g.roles.DB}).GetByID(id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil {
+			name = store.StoreName
+		}
+		return invalidf("tidak bisa menugaskan user ke store %s karena store tersebut bukan milik Anda", name)
+	}
+	return nil
+}
+
+// rolePermissionSet mengembalikan gabungan permission roleIDs beserta seluruh leluhurnya. Role
+// is_admin di roleIDs dihitung memiliki seluruh permission; seperti GetUserPermissionNames, status
+// admin tidak ikut diwariskan ke role turunan.
+func (g *delegationGuard) rolePermissionSet(roleIDs []int) (map[int64]bool, error) {
+	set := map[int64]bool{}
+	if len(roleIDs) == 0 {
+		return set, nil
+	}
+
+	parents, err := g.roles.GetParentMap()
+	if err != nil {
+		return nil, err
+	}
+	expanded := expandRoleIDs(parents, roleIDs)
+
+	ids := make([]int64, len(roleIDs))
+	for i, id := range roleIDs {
+		ids[i] = int64(id)
+	}
+	hasAdmin, err := g.roles.ContainsAdminRole(ids)
+	if err != nil {
+		return nil, err
+	}
+	if hasAdmin {
+		all, err := g.perms.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, perm := range all {
+			set[perm.ID] = true
+		}
+		return set, nil
+	}
+
+	permsByRole, err := g.roles.GetPermissionIDsByRoles(expanded)
+	if err != nil {
+		return nil, err
+	}
+	for _, permIDs := range permsByRole {
+		for _, id := range permIDs {
+			set[id] = true
+		}
+	}
+	return set, nil
+}
+
+// missingPermissions mengembalikan nama permission dari permIDs yang tidak dimiliki actor, terurut.
+func (g *delegationGuard) missingPermissions(permIDs []int64) ([]string, error) {
+	if g.allPermissions || len(permIDs) == 0 {
+		return nil, nil
+	}
+
+	all, err := g.perms.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[int64]bool, len(permIDs))
+	for _, id := range permIDs {
+		wanted[id] = true
+	}
+
+	var missing []string
+	for _, perm := range all {
+		if wanted[perm.ID] && !g.granted[perm.Name] {
+			missing = append(missing, perm.Name)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	delegatingUser  = 61 // bukan admin: store 1 dan 2, stock.issue hanya di store 2
+	delegatingAdmin = 62
+)
+
+func expectPermissionCatalog(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`FROM permissions\s+ORDER BY group_name, name`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "group_name", "guard_name", "description"}).
+			AddRow(1, "items.view", "items", "web", "").
+			AddRow(2, "stock.issue", "stock", "web", "").
+			AddRow(3, "users.delete", "users", "web", ""))
+}
+
+func expectPermissionByID(mock sqlmock.Sqlmock, id int64, name string) {
+	mock.ExpectQuery(`FROM permissions\s+WHERE id = \?`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "group_name", "guard_name", "description"}).
+			AddRow(id, name, "", "web", ""))
+}
+
+func expectUserStores(mock sqlmock.Sqlmock, userID int, storeIDs ...int) {
+	mock.ExpectQuery(`SELECT TRUE FROM users WHERE id = \?`).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	rows := sqlmock.NewRows([]string{"store_id"})
+	for _, id := range storeIDs {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(`SELECT store_id FROM user_stores`).WithArgs(int64(userID)).WillReturnRows(rows)
+}
+
+// expectRolePermissions mengharapkan rolePermissionSet untuk role 4 (items.view, mewarisi role 3
+// dengan users.delete) atau role 5 (stock.issue).
+func expectRolePermissions(mock sqlmock.Sqlmock, roleID int) {
+	mock.ExpectQuery(`SELECT role_id, parent_id FROM role_parents`).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id", "parent_id"}).AddRow(4, 3))
+	mock.ExpectQuery(`SELECT COUNT\(1\) FROM roles WHERE is_admin = 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT role_id, permission_id\s+FROM role_has_permissions`).
+		WillReturnRows(map[int]*sqlmock.Rows{
+			4: sqlmock.NewRows([]string{"role_id", "permission_id"}).AddRow(3, 3).AddRow(4, 1),
+			5: sqlmock.NewRows([]string{"role_id", "permission_id"}).AddRow(5, 2),
+		}[roleID])
+}
+
+// expectAdminCheck mengharapkan pengecekan role admin (RoleRepository.UserIsAdmin) untuk userID.
+func expectAdminCheck(mock sqlmock.Sqlmock, userID int, isAdmin bool) {
+	count := 0
+	if isAdmin {
+		count = 1
+	}
+	mock.ExpectQuery(`SELECT COUNT\(1\)\s+FROM model_has_roles mhr\s+JOIN roles r`).
+		WithArgs(userID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+func TestDelegationGuard(t *testing.T) {
+	users := func(g *delegationGuard) *repositories.UserRepository {
+		return &repositories.UserRepository{DB: g.roles.DB}
+	}
+	roleNames := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT id, name FROM roles`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "auditor").AddRow(4, "supervisor").AddRow(5, "gudang"))
+	}
+
+	tests := []struct {
+		name      string
+		principal models.Principal
+		setup     func(sqlmock.Sqlmock)
+		check     func(*delegationGuard) error
+		wantError bool
+	}{
+		{
+			name:      "permission global yang dimiliki boleh diberikan",
+			principal: models.Principal{UserID: delegatingUser},
+			setup:     expectPermissionCatalog,
+			check:     func(g *delegationGuard) error { return g.ensurePermissions([]int64{1, 3}) },
+		},
+		{
+			name:      "permission yang hanya dimiliki per store tidak boleh diberikan global",
+			principal: models.Principal{UserID: delegatingUser},
+			setup:     expectPermissionCatalog,
+			check:     func(g *delegationGuard) error { return g.ensurePermissions([]int64{1, 2}) },
+			wantError: true,
+		},
+		{
+			name:      "token hanya bisa memberikan permission dalam ability-nya",
+			principal: models.Principal{UserID: delegatingUser, Token: true, Abilities: map[string]bool{"items.view": true}},
+			setup:     expectPermissionCatalog,
+			check:     func(g *delegationGuard) error { return g.ensurePermissions([]int64{3}) },
+			wantError: true,
+		},
+		{
+			name:      "permission per store di store tempat actor memilikinya",
+			principal: models.Principal{UserID: delegatingUser},
+			setup:     func(mock sqlmock.Sqlmock) { expectPermissionByID(mock, 2, "stock.issue") },
+			check:     func(g *delegationGuard) error { return g.ensureStorePermission(2, 2) },
+		},
+		{
+			name:      "permission per store di store lain milik actor",
+			principal: models.Principal{UserID: delegatingUser},
+			setup:     func(mock sqlmock.Sqlmock) { expectPermissionByID(mock, 2, "stock.issue") },
+			check:     func(g *delegationGuard) error { return g.ensureStorePermission(2, 1) },
+			wantError: true,
+		},
+		{
+			name:      "permission per store di store yang bukan milik actor",
+			principal: models.Principal{UserID: delegatingUser},
+			check:     func(g *delegationGuard) error { return g.ensureStorePermission(1, 3) },
+			wantError: true,
+		},
+		{
+			name:      "user dengan store beririsan boleh dikelola",
+			principal: models.Principal{UserID: delegatingUser},
+			setup:     func(mock sqlmock.Sqlmock) { expectUserStores(mock, 9, 2, 5) },
+			check:     func(g *delegationGuard) error { return g.ensureManageUser(users(g), 9) },
+		},
+		{
+			name:      "user di store lain tidak boleh dikelola",
+			principal: models.Principal{UserID: delegatingUser},
+			setup:     func(mock sqlmock.Sqlmock) { expectUserStores(mock, 9, 5) },
+			check:     func(g *delegationGuard) error { return g.ensureManageUser(users(g), 9) },
+			wantError: true,
+		},
+		{
+			name:      "store yang sudah dimiliki user tetap boleh dipertahankan",
+			principal: models.Principal{UserID: delegatingUser},
+			check:     func(g *delegationGuard) error { return g.ensureAssignStores([]int{1, 5}, []int{5}) },
+		},
+		{
+			name:      "store baru di luar store actor",
+			principal: models.Principal{UserID: delegatingUser},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM stores\s+WHERE store_id IN`).WithArgs(6).
+					WillReturnRows(sqlmock.NewRows([]string{"store_id", "store_code", "store_name", "store_address", "is_active"}).
+						AddRow(6, "BG1", "Bogor", "", true))
+			},
+			check:     func(g *delegationGuard) error { return g.ensureAssignStores([]int{1, 6}, nil) },
+			wantError: true,
+		},
+		{
+			name:      "role beserta warisannya berisi permission actor",
+			principal: models.Principal{UserID: delegatingUser},
+			setup: func(mock sqlmock.Sqlmock) {
+				roleNames(mock)
+				expectRolePermissions(mock, 4)
+				expectPermissionCatalog(mock)
+			},
+			check: func(g *delegationGuard) error { return g.ensureRoles([]int64{4}, nil) },
+		},
+		{
+			name:      "role berisi permission yang tidak dimiliki actor",
+			principal: models.Principal{UserID: delegatingUser},
+			setup: func(mock sqlmock.Sqlmock) {
+				roleNames(mock)
+				expectRolePermissions(mock, 5)
+				expectPermissionCatalog(mock)
+			},
+			check:     func(g *delegationGuard) error { return g.ensureRoles([]int64{5}, nil) },
+			wantError: true,
+		},
+		{
+			name:      "role yang sudah dimiliki user tidak diperiksa ulang",
+			principal: models.Principal{UserID: delegatingUser},
+			setup:     roleNames,
+			check:     func(g *delegationGuard) error { return g.ensureRoles([]int64{5}, []int{5}) },
+		},
+		{
+			name:      "admin tanpa token lolos seluruh pengecekan",
+			principal: models.Principal{UserID: delegatingAdmin},
+			check: func(g *delegationGuard) error {
+				if err := g.ensurePermissions([]int64{2, 3}); err != nil {
+					return err
+				}
+				if err := g.ensureStorePermission(2, 9); err != nil {
+					return err
+				}
+				return g.ensureRoles([]int64{5}, nil)
+			},
+		},
+		{
+			name:      "admin lewat token tetap bebas store",
+			principal: models.Principal{UserID: delegatingAdmin, Token: true, Abilities: map[string]bool{"items.view": true}},
+			check:     func(g *delegationGuard) error { return g.ensureAssignStores([]int{6}, nil) },
+		},
+		{
+			name:      "admin lewat token dibatasi ability",
+			principal: models.Principal{UserID: delegatingAdmin, Token: true, Abilities: map[string]bool{"items.view": true}},
+			setup:     expectPermissionCatalog,
+			check:     func(g *delegationGuard) error { return g.ensurePermissions([]int64{3}) },
+			wantError: true,
+		},
+		{
+			// token yang ability-nya tidak terbaca tidak boleh dianggap login biasa
+			name:      "admin lewat token tanpa ability tidak memegang permission apa pun",
+			principal: models.Principal{UserID: delegatingAdmin, Token: true},
+			setup:     expectPermissionCatalog,
+			check:     func(g *delegationGuard) error { return g.ensurePermissions([]int64{1}) },
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			seedPermissions(t, delegatingUser, "items.view", "users.delete")
+			seedPermissions(t, delegatingAdmin, "items.view", "stock.issue", "users.delete")
+
+			isAdmin := tt.principal.UserID == delegatingAdmin
+			expectAdminCheck(mock, tt.principal.UserID, isAdmin)
+			if !isAdmin {
+				expectUserStores(mock, delegatingUser, 1, 2)
+				mock.ExpectQuery(`FROM model_has_store_permissions mhsp`).
+					WillReturnRows(sqlmock.NewRows([]string{"name", "store_id"}).AddRow("stock.issue", 2))
+			}
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+
+			guard, err := newDelegationGuard(db, tt.principal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tt.check(guard)
+			if tt.wantError && !IsClientError(err) {
+				t.Fatalf("err = %v, want ditolak", err)
+			}
+			if !tt.wantError && err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Jenis error service. Pesan error tetap spesifik (dan aman ditampilkan ke user), tetapi
+// bisa dicocokkan dengan errors.Is sehingga controller API dapat memilih status HTTP yang tepat.
+// Error yang tidak cocok dengan salah satu jenis ini dianggap kegagalan sistem.
+var (
+	ErrInvalidInput = errors.New("input tidak valid")
+	ErrNotFound     = errors.New("data tidak ditemukan")
+	ErrConflict     = errors.New("data sudah digunakan")
+	ErrForbidden    = errors.New("aksi tidak diizinkan")
+)
+
+// IsClientError mengecek apakah err termasuk salah satu jenis error di atas sehingga pesannya
+// boleh ditampilkan ke user.
+func IsClientError(err error) bool {
+	return errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrNotFound) ||
+		errors.Is(err, ErrConflict) || errors.Is(err, ErrForbidden)
+}
+
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+func invalidf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrInvalidInput, msg: fmt.Sprintf(format, args...)}
+}
+
+func notFoundf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+func conflictf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrConflict, msg: fmt.Sprintf(format, args...)}
+}
+
+func forbiddenf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrForbidden, msg: fmt.Sprintf(format, args...)}
+}
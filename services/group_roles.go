@@ -0,0 +1,114 @@
+package services
+
+import (
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"log"
+	"strings"
+)
+
+// parseGroupRoles membaca pemetaan group direktori/SSO ke role dari env berformat
+// "group=role,group=role". Satu group boleh muncul beberapa kali untuk beberapa role.
+func parseGroupRoles(key string) map[string][]string {
+	mapping := map[string][]string{}
+	for _, pair := range config.EnvList(key) {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if ok && group != "" && role != "" {
+			mapping[group] = append(mapping[group], role)
+		}
+	}
+	return mapping
+}
+
+// mappedRoles mengembalikan nama role hasil pemetaan group user.
+func mappedRoles(mapping map[string][]string, groups []string) []string {
+	var roles []string
+	for _, group := range groups {
+		roles = append(roles, mapping[group]...)
+	}
+	return uniqueStrings(roles)
+}
+
+// syncGroupRoles menyesuaikan role user yang muncul di pemetaan dengan group user saat ini.
+// Role yang tidak ada di pemetaan tetap dipertahankan sehingga bisa diatur manual oleh admin,
+// dan nama role yang tidak ada di database diabaikan.
+func syncGroupRoles(repo *repositories.UserRepository, userID int, mapping map[string][]string, groups []string, actor models.AuditActor) error {
+	if len(mapping) == 0 {
+		return nil
+	}
+
+	user, err := repo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	managed := make(map[string]bool)
+	for _, roles := range mapping {
+		for _, roleName := range roles {
+			managed[roleName] = true
+		}
+	}
+
+	var desired []string
+	for _, roleName := range user.RoleNames {
+		if !managed[roleName] {
+			desired = append(desired, roleName)
+		}
+	}
+	desired = append(desired, mappedRoles(mapping, groups)...)
+
+	roleIDs, err := repo.GetRoleIDsByNames(uniqueStrings(desired))
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]bool)
+	for _, roleName := range user.RoleNames {
+		current[roleName] = true
+	}
+	changed := len(current) != len(roleIDs)
+	for roleName := range roleIDs {
+		if !current[roleName] {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	ids := roleIDValues(roleIDs)
+
+	// role admin user aktif terakhir tidak ikut dicabut agar aplikasi tidak kehilangan admin
+	if user.Status == "active" {
+		roles := &repositories.RoleRepository{DB: repo.DB}
+		demoted, err := losesAdminRole(roles, userID, ids)
+		if err != nil {
+			return err
+		}
+		if demoted {
+			if err := ensureAdminRemains(roles, 0, userID); err != nil {
+				log.Printf("group role sync for user %d skipped: %v", userID, err)
+				return nil
+			}
+		}
+	}
+
+	if err := repo.ReplaceRoles(userID, ids, actor); err != nil {
+		return err
+	}
+
+	InvalidateUserPermissions(userID)
+	return nil
+}
+
+// losesAdminRole mengecek apakah user saat ini admin dan tidak lagi admin dengan role roleIDs.
+func losesAdminRole(roles *repositories.RoleRepository, userID int, roleIDs []int64) (bool, error) {
+	wasAdmin, err := roles.UserIsAdmin(userID)
+	if err != nil || !wasAdmin {
+		return false, err
+	}
+	willAdmin, err := roles.ContainsAdminRole(roleIDs)
+	return !willAdmin, err
+}
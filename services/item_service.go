@@ -0,0 +1,255 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ItemPerPage adalah jumlah item per halaman di daftar hadiah.
+const ItemPerPage = 25
+
+// ItemImageMaxSize adalah ukuran maksimal gambar item yang diunggah.
+const ItemImageMaxSize = 2 << 20
+
+// itemImageTypes memetakan content type gambar yang diterima ke ekstensi file-nya.
+var itemImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// itemSKUPattern membatasi SKU ke huruf besar, angka, tanda minus, titik, dan garis bawah.
+var itemSKUPattern = regexp.MustCompile(`^[A-Z0-9._-]+$`)
+
+type ItemService struct {
+	Repo *repositories.ItemRepository
+}
+
+// SearchItems mengambil satu halaman item sesuai filter beserta total item yang cocok.
+func (s *ItemService) SearchItems(filter models.ItemFilter) ([]models.Item, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	filter.PerPage = ItemPerPage
+	return s.Repo.Search(filter)
+}
+
+// GetCategories mengambil kategori item yang sudah dipakai.
+func (s *ItemService) GetCategories() ([]string, error) {
+	return s.Repo.GetCategories()
+}
+
+// GetItem mengambil satu item berdasarkan id.
+func (s *ItemService) GetItem(id int64) (*models.Item, error) {
+	if id <= 0 {
+		return nil, invalidf("item tidak valid")
+	}
+
+	item, err := s.Repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundf("item dengan id %d tidak ditemukan", id)
+	}
+	return item, err
+}
+
+// CreateItem memvalidasi lalu menyimpan item baru beserta gambarnya (image boleh nil).
+func (s *ItemService) CreateItem(input models.ItemInput, image *multipart.FileHeader, actor models.AuditActor) (int64, error) {
+	input.ID = 0
+	input, err := s.validateItem(input)
+	if err != nil {
+		return 0, err
+	}
+
+	imagePath, err := saveItemImage(image)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.Repo.Create(input, imagePath, actor)
+	if err != nil {
+		removeItemImage(imagePath)
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateItem memvalidasi lalu mengubah item. Gambar baru menggantikan gambar lama; tanpa gambar
+// baru, gambar lama dipertahankan kecuali input.RemoveImage.
+func (s *ItemService) UpdateItem(input models.ItemInput, image *multipart.FileHeader, actor models.AuditActor) error {
+	current, err := s.GetItem(input.ID)
+	if err != nil {
+		return err
+	}
+
+	input, err = s.validateItem(input)
+	if err != nil {
+		return err
+	}
+
+	imagePath := current.ImagePath
+	if image != nil {
+		if imagePath, err = saveItemImage(image); err != nil {
+			return err
+		}
+	} else if input.RemoveImage {
+		imagePath = ""
+	}
+
+	if err := s.Repo.Update(input, imagePath, actor); err != nil {
+		if imagePath != current.ImagePath {
+			removeItemImage(imagePath)
+		}
+		return err
+	}
+
+	if imagePath != current.ImagePath {
+		removeItemImage(current.ImagePath)
+	}
+	return nil
+}
+
+// SetItemActive mengaktifkan atau menonaktifkan item. Item nonaktif tetap tersimpan agar riwayat
+// yang merujuknya tidak rusak.
+func (s *ItemService) SetItemActive(id int64, active bool, actor models.AuditActor) error {
+	item, err := s.GetItem(id)
+	if err != nil {
+		return err
+	}
+	if item.IsActive == active {
+		if active {
+			return invalidf("item %s sudah aktif", item.Name)
+		}
+		return invalidf("item %s sudah nonaktif", item.Name)
+	}
+	return s.Repo.SetActive(id, active, actor)
+}
+
+func (s *ItemService) validateItem(input models.ItemInput) (models.ItemInput, error) {
+	input.SKU = strings.ToUpper(strings.TrimSpace(input.SKU))
+	input.Name = strings.TrimSpace(input.Name)
+	input.Category = strings.TrimSpace(input.Category)
+	input.Unit = strings.TrimSpace(input.Unit)
+
+	if input.SKU == "" {
+		return input, invalidf("kode/SKU wajib diisi")
+	}
+	if len(input.SKU) > 50 || !itemSKUPattern.MatchString(input.SKU) {
+		return input, invalidf("kode/SKU hanya boleh huruf, angka, titik, tanda minus, dan garis bawah (maksimal 50 karakter)")
+	}
+	if input.Name == "" {
+		return input, invalidf("nama item wajib diisi")
+	}
+	if utf8.RuneCountInString(input.Name) > 255 {
+		return input, invalidf("nama item maksimal 255 karakter")
+	}
+	if input.Category == "" {
+		return input, invalidf("kategori wajib diisi")
+	}
+	if utf8.RuneCountInString(input.Category) > 100 {
+		return input, invalidf("kategori maksimal 100 karakter")
+	}
+	if input.Unit == "" {
+		return input, invalidf("satuan wajib diisi")
+	}
+	if utf8.RuneCountInString(input.Unit) > 30 {
+		return input, invalidf("satuan maksimal 30 karakter")
+	}
+	if input.ValueType != "point" && input.ValueType != "voucher" {
+		return input, invalidf("jenis nilai harus poin atau voucher")
+	}
+	if input.Value < 0 {
+		return input, invalidf("nilai tidak boleh negatif")
+	}
+
+	exists, err := s.Repo.ExistsBySKUExceptID(input.SKU, input.ID)
+	if err != nil {
+		return input, err
+	}
+	if exists {
+		return input, conflictf("kode/SKU %s sudah digunakan", input.SKU)
+	}
+	return input, nil
+}
+
+// saveItemImage memvalidasi lalu menyimpan gambar item di UPLOAD_DIR/items dengan nama acak dan
+// mengembalikan path URL-nya. image nil mengembalikan path kosong.
+func saveItemImage(image *multipart.FileHeader) (string, error) {
+	if image == nil {
+		return "", nil
+	}
+	if image.Size > ItemImageMaxSize {
+		return "", invalidf("ukuran gambar maksimal %d MB", ItemImageMaxSize>>20)
+	}
+
+	src, err := image.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", invalidf("gambar tidak bisa dibaca")
+	}
+	ext, ok := itemImageTypes[http.DetectContentType(head[:n])]
+	if !ok {
+		return "", invalidf("gambar harus berformat JPG, PNG, GIF, atau WEBP")
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(b) + ext
+
+	dir := filepath.Join(config.UploadDir(), "items")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	dst, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+
+	return path.Join(config.UploadURLPrefix, "items", name), nil
+}
+
+// removeItemImage menghapus file gambar item yang disimpan saveItemImage. Kegagalan diabaikan
+// karena file yang tertinggal tidak memengaruhi data.
+func removeItemImage(imagePath string) {
+	prefix := config.UploadURLPrefix + "/items/"
+	if !strings.HasPrefix(imagePath, prefix) {
+		return
+	}
+	name := path.Base(imagePath)
+	os.Remove(filepath.Join(config.UploadDir(), "items", name))
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestItemValidateItem(t *testing.T) {
+	valid := models.ItemInput{SKU: " mug-01 ", Name: " Mug Kampus ", Category: "Souvenir", Unit: "pcs", ValueType: "point", Value: 150}
+	with := func(change func(*models.ItemInput)) models.ItemInput {
+		input := valid
+		change(&input)
+		return input
+	}
+
+	tests := []struct {
+		name      string
+		input     models.ItemInput
+		skuTaken  bool
+		wantErr   error
+		wantInput models.ItemInput
+	}{
+		{
+			name:      "valid dan dirapikan",
+			input:     valid,
+			wantInput: models.ItemInput{SKU: "MUG-01", Name: "Mug Kampus", Category: "Souvenir", Unit: "pcs", ValueType: "point", Value: 150},
+		},
+		{name: "SKU kosong", input: with(func(i *models.ItemInput) { i.SKU = "  " }), wantErr: ErrInvalidInput},
+		{name: "SKU berisi spasi", input: with(func(i *models.ItemInput) { i.SKU = "MUG 01" }), wantErr: ErrInvalidInput},
+		{name: "SKU terlalu panjang", input: with(func(i *models.ItemInput) { i.SKU = strings.Repeat("A", 51) }), wantErr: ErrInvalidInput},
+		{name: "nama kosong", input: with(func(i *models.ItemInput) { i.Name = "" }), wantErr: ErrInvalidInput},
+		{name: "nama terlalu panjang", input: with(func(i *models.ItemInput) { i.Name = strings.Repeat("é", 256) }), wantErr: ErrInvalidInput},
+		{name: "kategori kosong", input: with(func(i *models.ItemInput) { i.Category = "" }), wantErr: ErrInvalidInput},
+		{name: "satuan terlalu panjang", input: with(func(i *models.ItemInput) { i.Unit = strings.Repeat("x", 31) }), wantErr: ErrInvalidInput},
+		{name: "jenis nilai tidak dikenal", input: with(func(i *models.ItemInput) { i.ValueType = "cash" }), wantErr: ErrInvalidInput},
+		{name: "nilai negatif", input: with(func(i *models.ItemInput) { i.Value = -1 }), wantErr: ErrInvalidInput},
+		{name: "SKU sudah dipakai", input: valid, skuTaken: true, wantErr: ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if tt.wantErr == nil || tt.skuTaken {
+				count := 0
+				if tt.skuTaken {
+					count = 1
+				}
+				mock.ExpectQuery(`SELECT COUNT\(1\) FROM items WHERE sku = \? AND id <> \?`).WithArgs("MUG-01", int64(0)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+			}
+
+			svc := &ItemService{Repo: &repositories.ItemRepository{DB: db}}
+			got, err := svc.validateItem(tt.input)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && err != nil:
+				t.Fatal(err)
+			case tt.wantErr == nil && got != tt.wantInput:
+				t.Errorf("input = %+v, want %+v", got, tt.wantInput)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// newImageHeader membuat file upload multipart seperti yang diterima controller dari form.
+func newImageHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("image", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["image"][0]
+}
+
+func TestSaveItemImage(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	gif := []byte("GIF89a\x01\x00\x01\x00")
+
+	tests := []struct {
+		name    string
+		file    string
+		content []byte
+		wantExt string
+		wantErr bool
+	}{
+		{name: "png", file: "foto.png", content: png, wantExt: ".png"},
+		// ekstensi file diabaikan; jenis gambar dibaca dari isinya
+		{name: "gif bernama jpg", file: "foto.jpg", content: gif, wantExt: ".gif"},
+		{name: "html bernama png", file: "foto.png", content: []byte("<html><script>alert(1)</script></html>"), wantErr: true},
+		{name: "pdf", file: "katalog.pdf", content: []byte("%PDF-1.4\n"), wantErr: true},
+		{name: "file kosong", file: "kosong.png", content: nil, wantErr: true},
+		{name: "lebih dari batas ukuran", file: "besar.png", content: append(png, make([]byte, ItemImageMaxSize)...), wantErr: true},
+		{name: "tepat di batas ukuran", file: "pas.png", content: append(png, make([]byte, ItemImageMaxSize-len(png))...), wantExt: ".png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("UPLOAD_DIR", dir)
+
+			imagePath, err := saveItemImage(newImageHeader(t, tt.file, tt.content))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("err = %v, want ErrInvalidInput", err)
+				}
+				if files, _ := os.ReadDir(filepath.Join(dir, "items")); len(files) != 0 {
+					t.Errorf("%d file tersimpan, want 0", len(files))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.HasPrefix(imagePath, "/uploads/items/") || filepath.Ext(imagePath) != tt.wantExt {
+				t.Errorf("path = %q, want /uploads/items/*%s", imagePath, tt.wantExt)
+			}
+			saved, err := os.ReadFile(filepath.Join(dir, "items", filepath.Base(imagePath)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(saved, tt.content) {
+				t.Errorf("isi file tersimpan %d byte, want %d byte yang sama dengan upload", len(saved), len(tt.content))
+			}
+
+			removeItemImage(imagePath)
+			if _, err := os.Stat(filepath.Join(dir, "items", filepath.Base(imagePath))); !os.IsNotExist(err) {
+				t.Errorf("gambar tidak terhapus: %v", err)
+			}
+		})
+	}
+}
+
+func TestItemSearchItems(t *testing.T) {
+	columns := []string{"id", "sku", "name", "category", "unit", "image_path", "value_type", "value", "is_active", "updated_at"}
+
+	tests := []struct {
+		name      string
+		filter    models.ItemFilter
+		wantWhere string
+		wantArgs  []driver.Value
+		wantLimit []driver.Value
+	}{
+		{
+			name:      "tanpa filter",
+			filter:    models.ItemFilter{},
+			wantWhere: `FROM items ORDER BY`,
+			wantLimit: []driver.Value{ItemPerPage, 0},
+		},
+		{
+			name:      "kata kunci di SKU atau nama",
+			filter:    models.ItemFilter{Query: " mug ", Page: 2},
+			wantWhere: `FROM items WHERE \(sku LIKE \? OR name LIKE \?\) ORDER BY`,
+			wantArgs:  []driver.Value{"%mug%", "%mug%"},
+			wantLimit: []driver.Value{ItemPerPage, ItemPerPage},
+		},
+		{
+			name:      "kategori dan status",
+			filter:    models.ItemFilter{Category: "Souvenir", Status: "inactive"},
+			wantWhere: `FROM items WHERE category = \? AND is_active = 0 ORDER BY`,
+			wantArgs:  []driver.Value{"Souvenir"},
+			wantLimit: []driver.Value{ItemPerPage, 0},
+		},
+		{
+			name:      "status tidak dikenal diabaikan",
+			filter:    models.ItemFilter{Status: "semua", Page: -3},
+			wantWhere: `FROM items ORDER BY`,
+			wantLimit: []driver.Value{ItemPerPage, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT COUNT\(1\) `+strings.TrimSuffix(tt.wantWhere, ` ORDER BY`)+`$`).WithArgs(tt.wantArgs...).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectQuery(tt.wantWhere+` is_active DESC, name ASC, id ASC LIMIT \? OFFSET \?`).
+				WithArgs(append(append([]driver.Value{}, tt.wantArgs...), tt.wantLimit...)...).
+				WillReturnRows(sqlmock.NewRows(columns))
+
+			svc := &ItemService{Repo: &repositories.ItemRepository{DB: db}}
+			if _, _, err := svc.SearchItems(tt.filter); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
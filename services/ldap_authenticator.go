@@ -0,0 +1,234 @@
+package services
+
+import (
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPConfig berisi pengaturan koneksi LDAP/Active Directory yang dibaca dari environment:
+//   - LDAP_URL, mis. "ldaps://ad.kampus.local:636" atau "ldap://127.0.0.1:389"
+//   - LDAP_START_TLS (default false) – upgrade koneksi ldap:// ke TLS
+//   - LDAP_BIND_DN, LDAP_BIND_PASSWORD – akun layanan untuk mencari user (kosong = anonymous)
+//   - LDAP_BASE_DN – base pencarian user
+//   - LDAP_USER_FILTER (default "(uid=%s)"; Active Directory: "(sAMAccountName=%s)")
+//   - LDAP_NAME_ATTR (default "cn"), LDAP_EMAIL_ATTR (default "mail"), LDAP_GROUP_ATTR (default "memberOf")
+//   - LDAP_GROUP_ROLES – pemetaan CN group ke role, mis. "it-admin=admin,gudang=staff"
+type LDAPConfig struct {
+	URL          string
+	StartTLS     bool
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	UserFilter   string
+	NameAttr     string
+	EmailAttr    string
+	GroupAttr    string
+	GroupRoles   map[string][]string
+	Timeout      time.Duration
+}
+
+// LoadLDAPConfig membaca pengaturan LDAP dari environment.
+func LoadLDAPConfig() LDAPConfig {
+	envDefault := func(key, fallback string) string {
+		if val := strings.TrimSpace(os.Getenv(key)); val != "" {
+			return val
+		}
+		return fallback
+	}
+
+	return LDAPConfig{
+		URL:          strings.TrimSpace(os.Getenv("LDAP_URL")),
+		StartTLS:     config.EnvBool("LDAP_START_TLS"),
+		BindDN:       strings.TrimSpace(os.Getenv("LDAP_BIND_DN")),
+		BindPassword: os.Getenv("LDAP_BIND_PASSWORD"),
+		BaseDN:       strings.TrimSpace(os.Getenv("LDAP_BASE_DN")),
+		UserFilter:   envDefault("LDAP_USER_FILTER", "(uid=%s)"),
+		NameAttr:     envDefault("LDAP_NAME_ATTR", "cn"),
+		EmailAttr:    envDefault("LDAP_EMAIL_ATTR", "mail"),
+		GroupAttr:    envDefault("LDAP_GROUP_ATTR", "memberOf"),
+		GroupRoles:   parseGroupRoles("LDAP_GROUP_ROLES"),
+		Timeout:      10 * time.Second,
+	}
+}
+
+// LDAPAuthenticator memverifikasi password dengan bind ke server LDAP memakai DN user hasil
+// pencarian. User harus sudah ada dan aktif di tabel users (dicocokkan lewat username);
+// nama, email, dan role hasil pemetaan group disinkronkan setiap login berhasil.
+type LDAPAuthenticator struct {
+	Repo   *repositories.UserRepository
+	Config LDAPConfig
+}
+
+func (a *LDAPAuthenticator) Name() string {
+	return "ldap"
+}
+
+// Authenticate mencari entry user di direktori, bind sebagai user tersebut, lalu menyinkronkan
+// data user lokal. Gangguan koneksi, TLS, bind akun layanan, dan pencarian dikembalikan sebagai
+// ErrAuthUnavailable.
+func (a *LDAPAuthenticator) Authenticate(username, password string, actor models.AuditActor) (int, error) {
+	username = strings.TrimSpace(username)
+	// password kosong akan dianggap "unauthenticated bind" yang selalu sukses oleh banyak server
+	if username == "" || password == "" {
+		return 0, ErrInvalidCredentials
+	}
+	if a.Config.URL == "" || a.Config.BaseDN == "" {
+		return 0, fmt.Errorf("%w: LDAP_URL dan LDAP_BASE_DN wajib diisi", ErrAuthUnavailable)
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	entry, err := a.findEntry(conn, username)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return 0, ErrInvalidCredentials
+		}
+		return 0, fmt.Errorf("%w: bind LDAP gagal: %w", ErrAuthUnavailable, err)
+	}
+
+	userID, _, err := a.Repo.FindActiveCredentials(username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidCredentials
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// perubahan hasil sinkronisasi dicatat atas nama user yang login
+	actor.UserID = userID
+	actor.Username = username
+
+	if err := a.syncProfile(userID, entry, actor); err != nil {
+		return 0, err
+	}
+	if err := syncGroupRoles(a.Repo, userID, a.Config.GroupRoles, ldapGroupNames(entry.GetAttributeValues(a.Config.GroupAttr)), actor); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+func (a *LDAPAuthenticator) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(a.Config.URL, ldap.DialWithDialer(&net.Dialer{Timeout: a.Config.Timeout}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: gagal terhubung ke server LDAP: %w", ErrAuthUnavailable, err)
+	}
+	conn.SetTimeout(a.Config.Timeout)
+
+	if a.Config.StartTLS {
+		host := a.Config.URL
+		if u, err := url.Parse(a.Config.URL); err == nil {
+			host = u.Hostname()
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: StartTLS LDAP gagal: %w", ErrAuthUnavailable, err)
+		}
+	}
+
+	if a.Config.BindDN != "" {
+		if err := conn.Bind(a.Config.BindDN, a.Config.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: bind akun layanan LDAP gagal: %w", ErrAuthUnavailable, err)
+		}
+	}
+
+	return conn, nil
+}
+
+// findEntry mencari tepat satu entry untuk username; nol atau lebih dari satu hasil dianggap
+// kredensial salah.
+func (a *LDAPAuthenticator) findEntry(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	filter := strings.ReplaceAll(a.Config.UserFilter, "%s", ldap.EscapeFilter(username))
+	req := ldap.NewSearchRequest(
+		a.Config.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(a.Config.Timeout.Seconds()), false,
+		filter,
+		[]string{"dn", a.Config.NameAttr, a.Config.EmailAttr, a.Config.GroupAttr},
+		nil,
+	)
+
+	res, err := conn.Search(req)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: pencarian user LDAP gagal: %w", ErrAuthUnavailable, err)
+	}
+	if len(res.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	return res.Entries[0], nil
+}
+
+// syncProfile menyalin nama dan email dari direktori ke tabel users jika berbeda.
+// Email yang sudah dipakai user lain tidak ikut disalin.
+func (a *LDAPAuthenticator) syncProfile(userID int, entry *ldap.Entry, actor models.AuditActor) error {
+	user, err := a.Repo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(entry.GetAttributeValue(a.Config.NameAttr))
+	if name == "" {
+		name = user.Name
+	}
+
+	email := strings.TrimSpace(entry.GetAttributeValue(a.Config.EmailAttr))
+	if email != "" && !strings.EqualFold(email, user.Email) {
+		taken, err := a.Repo.ExistsByEmailExceptID(email, userID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			log.Printf("ldap: email %s for user %d already used by another user, not synced", email, userID)
+			email = ""
+		}
+	} else {
+		email = ""
+	}
+
+	if name == user.Name && email == "" {
+		return nil
+	}
+
+	return a.Repo.UpdateProfile(userID, name, email, actor)
+}
+
+// ldapGroupNames mengambil CN dari setiap DN group, mis. "cn=gudang,ou=groups,dc=kampus" menjadi "gudang".
+// Nilai yang bukan DN dipakai apa adanya.
+func ldapGroupNames(values []string) []string {
+	var names []string
+	for _, value := range values {
+		dn, err := ldap.ParseDN(value)
+		if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+			names = append(names, strings.TrimSpace(value))
+			continue
+		}
+		names = append(names, dn.RDNs[0].Attributes[0].Value)
+	}
+	return names
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// testLDAPEntry adalah satu entry di direktori testLDAPServer. Entry tanpa atribut uid hanya bisa
+// dipakai untuk bind (mis. akun layanan).
+type testLDAPEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// testLDAPServer adalah server LDAP in-process yang cukup untuk LDAPAuthenticator: simple bind,
+// search dengan filter (uid=...), dan unbind.
+type testLDAPServer struct {
+	listener net.Listener
+	entries  []testLDAPEntry
+}
+
+func startTestLDAP(t *testing.T, entries ...testLDAPEntry) *testLDAPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &testLDAPServer{listener: listener, entries: entries}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+func (s *testLDAPServer) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *testLDAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		msgID, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn, _ := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			code := ldap.LDAPResultInvalidCredentials
+			for _, entry := range s.entries {
+				if entry.dn == dn && entry.password == password {
+					code = ldap.LDAPResultSuccess
+				}
+			}
+			conn.Write(ldapMessage(msgID, ldapResult(ldap.ApplicationBindResponse, code)))
+		case ldap.ApplicationSearchRequest:
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for _, entry := range s.entries {
+				if uid := entry.attrs["uid"]; len(uid) > 0 && filter == "(uid="+uid[0]+")" {
+					conn.Write(ldapMessage(msgID, ldapSearchEntry(entry)))
+				}
+			}
+			conn.Write(ldapMessage(msgID, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)))
+		default:
+			return
+		}
+	}
+}
+
+func ldapMessage(msgID int64, op *ber.Packet) []byte {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, msgID, "Message ID"))
+	packet.AppendChild(op)
+	return packet.Bytes()
+}
+
+func ldapResult(tag ber.Tag, code int) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return op
+}
+
+func ldapSearchEntry(entry testLDAPEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attr.AppendChild(set)
+		attrs.AppendChild(attr)
+	}
+	op.AppendChild(attrs)
+	return op
+}
+
+const (
+	testLDAPServiceDN  = "cn=svc,dc=kampus,dc=local"
+	testLDAPServicePwd = "svc-secret"
+)
+
+func testLDAPDirectory(t *testing.T) *testLDAPServer {
+	return startTestLDAP(t,
+		testLDAPEntry{dn: testLDAPServiceDN, password: testLDAPServicePwd},
+		testLDAPEntry{
+			dn:       "uid=alice,ou=people,dc=kampus,dc=local",
+			password: "rahasia",
+			attrs: map[string][]string{
+				"uid":      {"alice"},
+				"cn":       {"Alice Baru"},
+				"mail":     {"alice@kampus.local"},
+				"memberOf": {"cn=gudang,ou=groups,dc=kampus,dc=local"},
+			},
+		},
+	)
+}
+
+func testLDAPConfig(url string) LDAPConfig {
+	return LDAPConfig{
+		URL:          url,
+		BindDN:       testLDAPServiceDN,
+		BindPassword: testLDAPServicePwd,
+		BaseDN:       "dc=kampus,dc=local",
+		UserFilter:   "(uid=%s)",
+		NameAttr:     "cn",
+		EmailAttr:    "mail",
+		GroupAttr:    "memberOf",
+		GroupRoles:   map[string][]string{"gudang": {"staff"}, "supervisor": {"supervisor"}},
+		Timeout:      2 * time.Second,
+	}
+}
+
+// closedLDAPURL mengembalikan alamat yang tidak sedang didengarkan server mana pun.
+func closedLDAPURL(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return "ldap://" + addr
+}
+
+func TestLDAPAuthenticatorFailures(t *testing.T) {
+	srv := testLDAPDirectory(t)
+
+	tests := []struct {
+		name     string
+		config   func(*LDAPConfig)
+		username string
+		password string
+		setupDB  func(sqlmock.Sqlmock)
+		wantErr  error
+	}{
+		{
+			name:     "password salah",
+			username: "alice",
+			password: "salah",
+			wantErr:  ErrInvalidCredentials,
+		},
+		{
+			name:     "password kosong tidak dikirim ke server",
+			username: "alice",
+			wantErr:  ErrInvalidCredentials,
+		},
+		{
+			name:     "user tidak dikenal direktori",
+			username: "bob",
+			password: "rahasia",
+			wantErr:  ErrInvalidCredentials,
+		},
+		{
+			name:     "user direktori tanpa user lokal aktif",
+			username: "alice",
+			password: "rahasia",
+			setupDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM users\s+WHERE username = \? AND status = 'active'`).
+					WithArgs("alice").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:     "server mati",
+			config:   func(cfg *LDAPConfig) { cfg.URL = closedLDAPURL(t) },
+			username: "alice",
+			password: "rahasia",
+			wantErr:  ErrAuthUnavailable,
+		},
+		{
+			name:     "bind akun layanan ditolak",
+			config:   func(cfg *LDAPConfig) { cfg.BindPassword = "kedaluwarsa" },
+			username: "alice",
+			password: "rahasia",
+			wantErr:  ErrAuthUnavailable,
+		},
+		{
+			name:     "LDAP_URL kosong",
+			config:   func(cfg *LDAPConfig) { cfg.URL = "" },
+			username: "alice",
+			password: "rahasia",
+			wantErr:  ErrAuthUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if tt.setupDB != nil {
+				tt.setupDB(mock)
+			}
+
+			cfg := testLDAPConfig(srv.URL())
+			if tt.config != nil {
+				tt.config(&cfg)
+			}
+			auth := &LDAPAuthenticator{Repo: &repositories.UserRepository{DB: db}, Config: cfg}
+
+			_, err = auth.Authenticate(tt.username, tt.password, models.AuditActor{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == ErrInvalidCredentials && errors.Is(err, ErrAuthUnavailable) {
+				t.Errorf("err = %v, kredensial salah tidak boleh dianggap layanan mati", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// expectUserRow mengharapkan query UserRepository.GetByID untuk user 7 dengan role roles
+// (dipisah koma seperti hasil GROUP_CONCAT).
+func expectUserRow(mock sqlmock.Sqlmock, name, email, roles string) {
+	mock.ExpectQuery(`FROM users u\s+LEFT JOIN model_has_roles`).
+		WithArgs(sqlmock.AnyArg(), 7).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "nip", "username", "name", "email", "status", "created_at", "must_change_password",
+			"email_verified", "role_display", "store_ids", "store_display",
+		}).AddRow(7, 1001, "alice", name, email, "active", time.Now(), false, true, roles, "1", "MK1"))
+}
+
+// expectUserSnapshot mengharapkan query snapshot audit user 7 yang aktif di dalam transaksi repository.
+func expectUserSnapshot(mock sqlmock.Sqlmock, name, email string, roles ...string) {
+	expectUserStatusSnapshot(mock, "active", name, email, roles...)
+}
+
+func expectUserStatusSnapshot(mock sqlmock.Sqlmock, status, name, email string, roles ...string) {
+	mock.ExpectQuery(`SELECT nip, username, name, email, status, must_change_password`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"nip", "username", "name", "email", "status", "must_change_password", "email_verified", "password"}).
+			AddRow(1001, "alice", name, email, status, false, true, "hash"))
+	mock.ExpectQuery(`SELECT store_id FROM user_stores`).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(1))
+	roleRows := sqlmock.NewRows([]string{"name"})
+	for _, role := range roles {
+		roleRows.AddRow(role)
+	}
+	mock.ExpectQuery(`FROM model_has_roles mhr\s+JOIN roles r`).WillReturnRows(roleRows)
+	mock.ExpectQuery(`FROM model_has_permissions mhp`).WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	mock.ExpectQuery(`FROM model_has_store_permissions mhsp`).WillReturnRows(sqlmock.NewRows([]string{"name"}))
+}
+
+func TestLDAPAuthenticatorSyncsProfileAndGroupRoles(t *testing.T) {
+	srv := testLDAPDirectory(t)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM users\s+WHERE username = \? AND status = 'active'`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password"}).AddRow(7, "hash"))
+
+	// nama dan email dari direktori disalin ke user lokal
+	expectUserRow(mock, "Alice Lama", "alice@lama.local", "auditor, supervisor")
+	mock.ExpectQuery(`SELECT COUNT\(1\) FROM users WHERE email = \? AND id <> \?`).
+		WithArgs("alice@kampus.local", 7).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	expectUserSnapshot(mock, "Alice Lama", "alice@lama.local", "auditor", "supervisor")
+	mock.ExpectExec(`UPDATE users SET name = \?`).
+		WithArgs("Alice Baru", "alice@kampus.local", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectUserSnapshot(mock, "Alice Baru", "alice@kampus.local", "auditor", "supervisor")
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(7, "alice", "user.update", "user", int64(7), sqlmock.AnyArg(), sqlmock.AnyArg(), "10.0.0.1", "test").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// group gudang memberi role staff; supervisor dicabut karena user tidak lagi di group
+	// supervisor, sedangkan auditor tidak ada di pemetaan sehingga dipertahankan
+	expectUserRow(mock, "Alice Baru", "alice@kampus.local", "auditor, supervisor")
+	mock.ExpectQuery(`SELECT id, name\s+FROM roles\s+WHERE name IN \(\?,\?\)`).
+		WithArgs("auditor", "staff").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "auditor").AddRow(5, "staff"))
+	mock.ExpectQuery(`r\.is_admin = 1`).
+		WithArgs(7, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	expectUserSnapshot(mock, "Alice Baru", "alice@kampus.local", "auditor", "supervisor")
+	mock.ExpectExec(`DELETE FROM model_has_roles`).
+		WithArgs(7, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO model_has_roles`).
+		WithArgs(int64(3), sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO model_has_roles`).
+		WithArgs(int64(5), sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectUserSnapshot(mock, "Alice Baru", "alice@kampus.local", "auditor", "staff")
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(7, "alice", "user.update", "user", int64(7), sqlmock.AnyArg(), sqlmock.AnyArg(), "10.0.0.1", "test").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	auth := &LDAPAuthenticator{Repo: &repositories.UserRepository{DB: db}, Config: testLDAPConfig(srv.URL())}
+	userID, err := auth.Authenticate("alice", "rahasia", models.AuditActor{IPAddress: "10.0.0.1", UserAgent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != 7 {
+		t.Errorf("userID = %d, want 7", userID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLDAPAuthenticatorSkipsEmailUsedByAnotherUser(t *testing.T) {
+	srv := testLDAPDirectory(t)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM users\s+WHERE username = \? AND status = 'active'`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password"}).AddRow(7, "hash"))
+	// nama sudah sama dan email direktori dipakai user lain, jadi profil tidak diubah
+	expectUserRow(mock, "Alice Baru", "alice@lama.local", "auditor")
+	mock.ExpectQuery(`SELECT COUNT\(1\) FROM users WHERE email = \? AND id <> \?`).
+		WithArgs("alice@kampus.local", 7).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	cfg := testLDAPConfig(srv.URL())
+	cfg.GroupRoles = nil
+	auth := &LDAPAuthenticator{Repo: &repositories.UserRepository{DB: db}, Config: cfg}
+	if _, err := auth.Authenticate("alice", "rahasia", models.AuditActor{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLDAPGroupNames(t *testing.T) {
+	got := ldapGroupNames([]string{
+		"cn=gudang,ou=groups,dc=kampus,dc=local",
+		"CN=IT Admin,OU=Groups,DC=kampus,DC=local",
+		"bukan-dn",
+	})
+	want := []string{"gudang", "IT Admin", "bukan-dn"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ldapGroupNames = %v, want %v", got, want)
+	}
+}
+
+func TestMappedRoles(t *testing.T) {
+	mapping := map[string][]string{
+		"gudang":   {"staff"},
+		"it-admin": {"admin", "staff"},
+	}
+	got := mappedRoles(mapping, []string{"gudang", "it-admin", "tidak-dipetakan"})
+	want := []string{"staff", "admin"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mappedRoles = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	throttleUserPrefix       = "user:"
+	throttleIPPrefix         = "ip:"
+	throttleResetEmailPrefix = "reset:"
+	throttleResetIPPrefix    = "reset-ip:"
+	throttleRegisterIPPrefix = "register-ip:"
+
+	// backoff mulai berlaku setelah kegagalan ke-3, lalu berlipat dua sampai batas maksimum.
+	throttleBackoffAfter = 3
+	throttleMaxBackoff   = 5 * time.Minute
+)
+
+// LoginThrottleService membatasi percobaan login per username dan per IP.
+// Counter disimpan di database sehingga semua instance aplikasi melihat state yang sama.
+//
+// Konfigurasi (env): LOGIN_MAX_ATTEMPTS (default 5) kegagalan per username sebelum akun dikunci,
+// LOGIN_MAX_ATTEMPTS_IP (default 20) untuk per IP, LOGIN_LOCKOUT_MINUTES (default 15) lama kunci
+// sekaligus jendela waktu reset counter. Permintaan lupa password dibatasi dengan mekanisme yang
+// sama per email (PASSWORD_RESET_MAX_ATTEMPTS, default 3) dan per IP (PASSWORD_RESET_MAX_ATTEMPTS_IP,
+// default 10), begitu pula registrasi mandiri per IP (REGISTRATION_MAX_ATTEMPTS_IP, default 5),
+// masing-masing memakai counter terpisah.
+type LoginThrottleService struct {
+	Repo *repositories.LoginThrottleRepository
+}
+
+func (s *LoginThrottleService) maxUserAttempts() int {
+	return config.EnvInt("LOGIN_MAX_ATTEMPTS", 5)
+}
+
+func (s *LoginThrottleService) maxIPAttempts() int {
+	return config.EnvInt("LOGIN_MAX_ATTEMPTS_IP", 20)
+}
+
+func (s *LoginThrottleService) lockoutDuration() time.Duration {
+	return time.Duration(config.EnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute
+}
+
+// Check mengembalikan lama waktu tunggu jika username atau IP masih diblokir.
+func (s *LoginThrottleService) Check(username, ip string) (time.Duration, error) {
+	return s.checkKeys(userThrottleKey(username), ipThrottleKey(ip))
+}
+
+// CheckPasswordReset mengembalikan lama waktu tunggu jika permintaan lupa password untuk email atau
+// dari IP tersebut masih diblokir.
+func (s *LoginThrottleService) CheckPasswordReset(email, ip string) (time.Duration, error) {
+	return s.checkKeys(resetEmailThrottleKey(email), resetIPThrottleKey(ip))
+}
+
+// RegisterPasswordReset mencatat satu permintaan lupa password untuk email dan IP. Setiap
+// permintaan dihitung, terlepas dari email terdaftar atau tidak.
+func (s *LoginThrottleService) RegisterPasswordReset(email, ip string) error {
+	now := time.Now()
+	resetBefore := now.Add(-s.lockoutDuration())
+
+	if err := s.registerKeyFailure(resetEmailThrottleKey(email), "", config.EnvInt("PASSWORD_RESET_MAX_ATTEMPTS", 3), now, resetBefore); err != nil {
+		return err
+	}
+	return s.registerKeyFailure(resetIPThrottleKey(ip), "", config.EnvInt("PASSWORD_RESET_MAX_ATTEMPTS_IP", 10), now, resetBefore)
+}
+
+// CheckRegistration mengembalikan lama waktu tunggu jika registrasi dari IP tersebut masih diblokir.
+func (s *LoginThrottleService) CheckRegistration(ip string) (time.Duration, error) {
+	return s.checkKeys(registerIPThrottleKey(ip))
+}
+
+// RegisterRegistration mencatat satu percobaan registrasi dari IP, berhasil maupun gagal, agar
+// form registrasi tidak bisa dipakai untuk menebak username, NIP, atau email yang terdaftar.
+func (s *LoginThrottleService) RegisterRegistration(ip string) error {
+	now := time.Now()
+	return s.registerKeyFailure(registerIPThrottleKey(ip), "", config.EnvInt("REGISTRATION_MAX_ATTEMPTS_IP", 5), now, now.Add(-s.lockoutDuration()))
+}
+
+func (s *LoginThrottleService) checkKeys(keys ...string) (time.Duration, error) {
+	throttles, err := s.Repo.GetByKeys(keys...)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, t := range throttles {
+		if remaining := t.LockedUntil.Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+
+	return wait, nil
+}
+
+// RegisterFailure mencatat login gagal untuk username dan IP lalu menerapkan backoff / lockout.
+// Hanya dipanggil untuk kredensial yang salah; error server (database, LDAP) tidak dihitung.
+func (s *LoginThrottleService) RegisterFailure(username, ip string) error {
+	now := time.Now()
+	resetBefore := now.Add(-s.lockoutDuration())
+
+	if err := s.registerKeyFailure(userThrottleKey(username), ip, s.maxUserAttempts(), now, resetBefore); err != nil {
+		return err
+	}
+	return s.registerKeyFailure(ipThrottleKey(ip), "", s.maxIPAttempts(), now, resetBefore)
+}
+
+// RegisterSuccess mereset counter username setelah login berhasil.
+// Counter IP sengaja tidak direset agar satu akun valid tidak bisa dipakai untuk menghapus jejak brute-force.
+func (s *LoginThrottleService) RegisterSuccess(username string) error {
+	return s.Repo.Clear(userThrottleKey(username))
+}
+
+// GetLockedAccounts mengambil daftar username yang sedang terkunci.
+func (s *LoginThrottleService) GetLockedAccounts() ([]models.LockedAccount, error) {
+	throttles, err := s.Repo.GetLocked(throttleUserPrefix, s.maxUserAttempts(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]models.LockedAccount, 0, len(throttles))
+	for _, t := range throttles {
+		accounts = append(accounts, models.LockedAccount{
+			Username:           strings.TrimPrefix(t.Key, throttleUserPrefix),
+			Failures:           t.Failures,
+			LastFailedDisplay:  t.LastFailedAt.Format("02 Jan 2006 15:04:05"),
+			LockedUntilDisplay: t.LockedUntil.Format("02 Jan 2006 15:04:05"),
+		})
+	}
+
+	return accounts, nil
+}
+
+// Unlock membuka kunci akun berdasarkan username, sekaligus kunci IP terakhir yang gagal login
+// ke akun tersebut agar user bisa langsung mencoba lagi dari perangkatnya.
+func (s *LoginThrottleService) Unlock(username string) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return errors.New("username wajib diisi")
+	}
+
+	key := userThrottleKey(username)
+	throttles, err := s.Repo.GetByKeys(key)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{key}
+	for _, t := range throttles {
+		if t.LastIP != "" {
+			keys = append(keys, ipThrottleKey(t.LastIP))
+		}
+	}
+	return s.Repo.Clear(keys...)
+}
+
+func (s *LoginThrottleService) registerKeyFailure(key, ip string, maxAttempts int, now, resetBefore time.Time) error {
+	failures, err := s.Repo.RegisterFailure(key, ip, now, resetBefore)
+	if err != nil {
+		return err
+	}
+
+	var wait time.Duration
+	switch {
+	case failures >= maxAttempts:
+		wait = s.lockoutDuration()
+	case failures >= throttleBackoffAfter:
+		wait = time.Duration(math.Pow(2, float64(failures-throttleBackoffAfter))) * time.Second
+		if wait > throttleMaxBackoff {
+			wait = throttleMaxBackoff
+		}
+	default:
+		return nil
+	}
+
+	return s.Repo.SetLockedUntil(key, now.Add(wait))
+}
+
+// FormatWait mengubah durasi tunggu menjadi pesan yang ramah dibaca.
+func FormatWait(wait time.Duration) string {
+	if wait < time.Minute {
+		return fmt.Sprintf("%d detik", int(math.Ceil(wait.Seconds())))
+	}
+	return fmt.Sprintf("%d menit", int(math.Ceil(wait.Minutes())))
+}
+
+func userThrottleKey(username string) string {
+	return throttleUserPrefix + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipThrottleKey(ip string) string {
+	return throttleIPPrefix + ip
+}
+
+func resetEmailThrottleKey(email string) string {
+	return throttleResetEmailPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+func resetIPThrottleKey(ip string) string {
+	return throttleResetIPPrefix + ip
+}
+
+func registerIPThrottleKey(ip string) string {
+	return throttleRegisterIPPrefix + ip
+}
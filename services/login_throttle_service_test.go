@@ -0,0 +1,51 @@
+package services
+
+import (
+	"database/sql/driver"
+	"gobase-app/repositories"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoginThrottleUnlock(t *testing.T) {
+	tests := []struct {
+		name   string
+		lastIP string
+		// keys yang dihapus dari login_throttles
+		want []driver.Value
+	}{
+		{name: "kunci username dan IP terakhir", lastIP: "10.0.0.7", want: []driver.Value{"user:alice", "ip:10.0.0.7"}},
+		{name: "tanpa IP tercatat", want: []driver.Value{"user:alice"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			var lastIP interface{}
+			if tt.lastIP != "" {
+				lastIP = tt.lastIP
+			}
+			mock.ExpectQuery(`FROM login_throttles\s+WHERE throttle_key IN`).WithArgs("user:alice").
+				WillReturnRows(sqlmock.NewRows([]string{"throttle_key", "failures", "last_failed_at", "locked_until", "last_ip"}).
+					AddRow("user:alice", 5, time.Now(), time.Now().Add(time.Hour), lastIP))
+			mock.ExpectExec(`DELETE FROM login_throttles WHERE throttle_key IN`).
+				WithArgs(tt.want...).
+				WillReturnResult(sqlmock.NewResult(0, int64(len(tt.want))))
+
+			svc := &LoginThrottleService{Repo: &repositories.LoginThrottleRepository{DB: db}}
+			if err := svc.Unlock(" Alice "); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bufio"
+	_ "embed"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]bool
+)
+
+// PasswordPolicy berisi aturan password yang dibaca dari environment:
+//   - PASSWORD_MIN_LENGTH (default 8)
+//   - PASSWORD_REQUIRE_UPPER, PASSWORD_REQUIRE_LOWER, PASSWORD_REQUIRE_DIGIT (default true)
+//   - PASSWORD_REQUIRE_SYMBOL (default false)
+//   - PASSWORD_HISTORY (default 5) – jumlah password terakhir yang tidak boleh dipakai ulang
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	HistorySize   int
+}
+
+// LoadPasswordPolicy membaca kebijakan password dari environment.
+func LoadPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:     config.EnvInt("PASSWORD_MIN_LENGTH", 8),
+		RequireUpper:  config.EnvBoolDefault("PASSWORD_REQUIRE_UPPER", true),
+		RequireLower:  config.EnvBoolDefault("PASSWORD_REQUIRE_LOWER", true),
+		RequireDigit:  config.EnvBoolDefault("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol: config.EnvBoolDefault("PASSWORD_REQUIRE_SYMBOL", false),
+		HistorySize:   config.EnvInt("PASSWORD_HISTORY", 5),
+	}
+}
+
+// Validate memeriksa password terhadap panjang minimal, jenis karakter, data identitas user,
+// dan daftar password umum. Semua pelanggaran digabung dalam satu pesan error.
+func (p PasswordPolicy) Validate(password string, owner models.PasswordOwner) error {
+	var (
+		rules    []string
+		problems []string
+	)
+
+	if len([]rune(password)) < p.MinLength {
+		rules = append(rules, "minimal "+strconv.Itoa(p.MinLength)+" karakter")
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		rules = append(rules, "mengandung huruf besar")
+	}
+	if p.RequireLower && !hasLower {
+		rules = append(rules, "mengandung huruf kecil")
+	}
+	if p.RequireDigit && !hasDigit {
+		rules = append(rules, "mengandung angka")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		rules = append(rules, "mengandung simbol")
+	}
+	if len(rules) > 0 {
+		problems = append(problems, "password harus "+strings.Join(rules, ", "))
+	}
+
+	if containsIdentity(password, owner) {
+		problems = append(problems, "password tidak boleh mengandung username, NIP, atau nama")
+	}
+	if isCommonPassword(password) {
+		problems = append(problems, "password terlalu umum dan mudah ditebak")
+	}
+
+	if len(problems) > 0 {
+		return invalidf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Summary meringkas aturan password untuk ditampilkan sebagai petunjuk di form.
+func (p PasswordPolicy) Summary() string {
+	rules := []string{"minimal " + strconv.Itoa(p.MinLength) + " karakter"}
+	if p.RequireUpper {
+		rules = append(rules, "huruf besar")
+	}
+	if p.RequireLower {
+		rules = append(rules, "huruf kecil")
+	}
+	if p.RequireDigit {
+		rules = append(rules, "angka")
+	}
+	if p.RequireSymbol {
+		rules = append(rules, "simbol")
+	}
+	return "Password " + strings.Join(rules, ", ") + "; tidak boleh memuat username/NIP/nama."
+}
+
+// CheckReuse menolak password yang sama dengan salah satu hash riwayat.
+func (p PasswordPolicy) CheckReuse(password string, hashes []string) error {
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return invalidf("password tidak boleh sama dengan %d password terakhir", p.HistorySize)
+		}
+	}
+	return nil
+}
+
+// checkNewPassword menjalankan kebijakan password dan cek riwayat untuk user yang sudah ada.
+func checkNewPassword(users *repositories.UserRepository, owner models.PasswordOwner, password string) error {
+	policy := LoadPasswordPolicy()
+	if err := policy.Validate(password, owner); err != nil {
+		return err
+	}
+
+	hashes, err := users.RecentPasswordHashes(owner.ID, policy.HistorySize)
+	if err != nil {
+		return err
+	}
+	return policy.CheckReuse(password, hashes)
+}
+
+// containsIdentity mengecek apakah password memuat username, NIP, atau salah satu kata dari nama user.
+func containsIdentity(password string, owner models.PasswordOwner) bool {
+	lower := strings.ToLower(password)
+
+	candidates := strings.Fields(strings.ToLower(owner.Name))
+	candidates = append(candidates, strings.ToLower(strings.TrimSpace(owner.Username)))
+	if owner.NIP > 0 {
+		candidates = append(candidates, strconv.Itoa(owner.NIP))
+	}
+
+	for _, candidate := range candidates {
+		// potongan yang terlalu pendek (mis. inisial) akan terlalu banyak menolak password
+		if len([]rune(candidate)) >= 3 && strings.Contains(lower, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]bool)
+		scanner := bufio.NewScanner(strings.NewReader(commonPasswordsFile))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			commonPasswords[strings.ToLower(line)] = true
+		}
+	})
+
+	return commonPasswords[strings.ToLower(strings.TrimSpace(password))]
+}
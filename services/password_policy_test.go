@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	defaults := PasswordPolicy{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true}
+	owner := models.PasswordOwner{ID: 7, NIP: 19870412, Username: "alice.w", Name: "Alice Wulandari"}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		wantErr  []string
+	}{
+		{name: "memenuhi semua aturan", policy: defaults, password: "Kopi-Susu42"},
+		{name: "tepat panjang minimal", policy: defaults, password: "Kopisu42"},
+		{name: "kurang satu karakter", policy: defaults, password: "Kopisu4", wantErr: []string{"minimal 8 karakter"}},
+		// panjang dihitung per karakter, bukan per byte
+		{name: "karakter multibyte", policy: defaults, password: "Ké4é", wantErr: []string{"minimal 8 karakter"}},
+		{name: "tanpa huruf besar", policy: defaults, password: "kopisusu42", wantErr: []string{"mengandung huruf besar"}},
+		{name: "tanpa huruf kecil", policy: defaults, password: "KOPISUSU42", wantErr: []string{"mengandung huruf kecil"}},
+		{name: "tanpa angka", policy: defaults, password: "KopiSusuGula", wantErr: []string{"mengandung angka"}},
+		{
+			name:     "simbol diwajibkan",
+			policy:   PasswordPolicy{MinLength: 8, RequireSymbol: true},
+			password: "KopiSusu42",
+			wantErr:  []string{"mengandung simbol"},
+		},
+		{name: "spasi dihitung simbol", policy: PasswordPolicy{MinLength: 8, RequireSymbol: true}, password: "kopi susu"},
+		{
+			name:     "semua pelanggaran digabung",
+			policy:   defaults,
+			password: "kopi",
+			wantErr:  []string{"minimal 8 karakter", "mengandung huruf besar", "mengandung angka"},
+		},
+		{name: "tanpa aturan jenis karakter", policy: PasswordPolicy{MinLength: 4}, password: "zzzzzz"},
+		{name: "memuat username", policy: defaults, password: "Xalice.W99", wantErr: []string{"username, NIP, atau nama"}},
+		{name: "memuat NIP", policy: defaults, password: "Kopi19870412", wantErr: []string{"username, NIP, atau nama"}},
+		{name: "memuat kata dari nama", policy: defaults, password: "WULANdari2025", wantErr: []string{"username, NIP, atau nama"}},
+		{name: "password umum", policy: defaults, password: "Password1", wantErr: []string{"terlalu umum"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password, owner)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("err = %v, want ErrInvalidInput", err)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("err = %q, want mengandung %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestContainsIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		owner    models.PasswordOwner
+		password string
+		want     bool
+	}{
+		{name: "username tanpa membedakan huruf besar", owner: models.PasswordOwner{Username: "Budi"}, password: "xxBUDIxx", want: true},
+		{name: "username dengan spasi di tepi", owner: models.PasswordOwner{Username: " budi "}, password: "budi2025", want: true},
+		{name: "kata kedua dari nama", owner: models.PasswordOwner{Name: "Siti Rahayu"}, password: "Rahayu#1", want: true},
+		// inisial dan kata pendek tidak dipakai agar tidak terlalu banyak password yang ditolak
+		{name: "kata nama kurang dari tiga huruf", owner: models.PasswordOwner{Name: "Al Bo"}, password: "albo-Albo", want: false},
+		{name: "NIP", owner: models.PasswordOwner{NIP: 12345}, password: "a12345b", want: true},
+		{name: "NIP kosong tidak dicek", owner: models.PasswordOwner{Username: "budi"}, password: "0x0", want: false},
+		{name: "tidak memuat identitas", owner: models.PasswordOwner{NIP: 1001, Username: "budi", Name: "Budi Santoso"}, password: "KopiSusu42", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsIdentity(tt.password, tt.owner); got != tt.want {
+				t.Errorf("containsIdentity(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckNewPasswordHistoryWindow(t *testing.T) {
+	t.Setenv("PASSWORD_HISTORY", "3")
+
+	hash := func(password string) string {
+		h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(h)
+	}
+	// riwayat dari yang terbaru; Lama4Kopi sudah di luar jendela 3 password terakhir
+	current := "Sekarang1Kopi"
+	history := []string{"Lama1Kopi", "Lama2Kopi", "Lama3Kopi", "Lama4Kopi"}
+	hashes := []string{hash(current)}
+	for _, password := range history {
+		hashes = append(hashes, hash(password))
+	}
+
+	tests := []struct {
+		password string
+		wantErr  bool
+	}{
+		{password: current, wantErr: true},
+		{password: "Lama1Kopi", wantErr: true},
+		{password: "Lama3Kopi", wantErr: true},
+		{password: "Lama4Kopi"},
+		{password: "Baru5Kopi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			// database membatasi riwayat lewat LIMIT; hash password saat ini selalu ikut
+			rows := sqlmock.NewRows([]string{"password"})
+			for _, h := range hashes[:1+3] {
+				rows.AddRow(h)
+			}
+			mock.ExpectQuery(`SELECT password FROM users WHERE id = \?\)\s+UNION ALL`).WithArgs(7, 7, 3).WillReturnRows(rows)
+
+			owner := models.PasswordOwner{ID: 7, Username: "budi", Name: "Budi"}
+			err = checkNewPassword(&repositories.UserRepository{DB: db}, owner, tt.password)
+			switch {
+			case tt.wantErr && !errors.Is(err, ErrInvalidInput):
+				t.Fatalf("err = %v, want password ditolak", err)
+			case tt.wantErr && !strings.Contains(err.Error(), "3 password terakhir"):
+				t.Errorf("err = %q, want menyebut jumlah riwayat", err)
+			case !tt.wantErr && err != nil:
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLoadPasswordPolicy(t *testing.T) {
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("PASSWORD_REQUIRE_UPPER", "false")
+	t.Setenv("PASSWORD_REQUIRE_SYMBOL", "yes")
+	t.Setenv("PASSWORD_HISTORY", "-1")
+
+	want := PasswordPolicy{MinLength: 12, RequireLower: true, RequireDigit: true, RequireSymbol: true, HistorySize: 5}
+	if got := LoadPasswordPolicy(); got != want {
+		t.Errorf("LoadPasswordPolicy() = %+v, want %+v", got, want)
+	}
+}
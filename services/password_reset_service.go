@@ -0,0 +1,140 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gobase-app/config"
+	"gobase-app/mailer"
+	"gobase-app/repositories"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidResetToken dikembalikan untuk token yang tidak ada, sudah dipakai, atau kedaluwarsa.
+var ErrInvalidResetToken = errors.New("link reset password tidak valid atau sudah kedaluwarsa")
+
+// PasswordResetService menangani lupa password: token sekali pakai dengan masa berlaku
+// (PASSWORD_RESET_TTL_MINUTES, default 60) yang disimpan dalam bentuk hash.
+type PasswordResetService struct {
+	Repo   *repositories.PasswordResetRepository
+	Users  *repositories.UserRepository
+	Mailer mailer.Mailer
+}
+
+// RequestReset membuat token dan mengirim link reset ke email user.
+// Email yang tidak terdaftar tidak menghasilkan error agar tidak membocorkan data user.
+func (s *PasswordResetService) RequestReset(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email wajib diisi")
+	}
+
+	userID, name, userEmail, err := s.Repo.FindActiveUserByEmail(email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Duration(config.EnvInt("PASSWORD_RESET_TTL_MINUTES", 60)) * time.Minute
+	if err := s.Repo.CreateToken(userID, hashResetToken(token), time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	link := strings.TrimRight(os.Getenv("BASE_URL"), "/") + "/password/reset?token=" + url.QueryEscape(token)
+
+	return s.Mailer.Send(mailer.Message{
+		To:      userEmail,
+		Subject: "Reset password akun Anda",
+		Body: fmt.Sprintf("Halo %s,\n\n"+
+			"Kami menerima permintaan reset password untuk akun Anda. Buka link berikut untuk membuat password baru:\n\n"+
+			"%s\n\n"+
+			"Link ini hanya bisa dipakai sekali dan berlaku selama %d menit. "+
+			"Abaikan email ini jika Anda tidak meminta reset password.\n", name, link, int(ttl.Minutes())),
+	})
+}
+
+// ValidateToken memastikan token masih bisa dipakai.
+func (s *PasswordResetService) ValidateToken(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return ErrInvalidResetToken
+	}
+
+	_, _, err := s.Repo.FindValidToken(hashResetToken(token), time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidResetToken
+	}
+	return err
+}
+
+// ResetPassword mengganti password memakai token sesuai kebijakan password,
+// lalu mengakhiri seluruh session user.
+func (s *PasswordResetService) ResetPassword(token, password, confirmation string) error {
+	if strings.TrimSpace(password) == "" {
+		return errors.New("password wajib diisi")
+	}
+	if password != confirmation {
+		return errors.New("konfirmasi password tidak sama")
+	}
+
+	tokenID, userID, err := s.Repo.FindValidToken(hashResetToken(token), time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.Users.GetPasswordOwner(userID)
+	if err != nil {
+		return err
+	}
+	if err := checkNewPassword(s.Users, owner, password); err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Repo.ResetPassword(tokenID, userID, string(hashed)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	// session yang berakhir karena reset password ikut tercatat di riwayat login user
+	events := &AuthEventService{Repo: &repositories.AuthEventRepository{DB: s.Users.DB}}
+	_, err = events.EndOtherSessions(userID, "")
+	return err
+}
+
+func generateResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"gobase-app/mailer"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/sessionstore"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// recordingMailer menyimpan email yang dikirim tanpa mengirimkannya.
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func newTestPasswordResetService(t *testing.T) (*PasswordResetService, sqlmock.Sqlmock, *recordingMailer) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mail := &recordingMailer{}
+	return &PasswordResetService{
+		Repo:   &repositories.PasswordResetRepository{DB: db},
+		Users:  &repositories.UserRepository{DB: db},
+		Mailer: mail,
+	}, mock, mail
+}
+
+func TestPasswordResetRequestReset(t *testing.T) {
+	t.Setenv("BASE_URL", "https://gobase.local/")
+
+	t.Run("email tidak terdaftar", func(t *testing.T) {
+		svc, mock, mail := newTestPasswordResetService(t)
+		mock.ExpectQuery(`FROM users\s+WHERE email = \? AND status = 'active'`).WithArgs("bob@kampus.local").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+
+		if err := svc.RequestReset(" bob@kampus.local "); err != nil {
+			t.Fatalf("err = %v, want nil agar email tidak terdaftar tidak terbongkar", err)
+		}
+		if len(mail.sent) != 0 {
+			t.Errorf("%d email terkirim, want 0", len(mail.sent))
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("email terdaftar", func(t *testing.T) {
+		svc, mock, mail := newTestPasswordResetService(t)
+		mock.ExpectQuery(`FROM users\s+WHERE email = \? AND status = 'active'`).WithArgs("alice@kampus.local").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(7, "Alice", "alice@kampus.local"))
+		stored := &captureArg{}
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM password_reset_tokens WHERE user_id = \? AND used_at IS NULL`).WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO password_reset_tokens`).WithArgs(7, stored, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		if err := svc.RequestReset("alice@kampus.local"); err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+		if len(mail.sent) != 1 || mail.sent[0].To != "alice@kampus.local" {
+			t.Fatalf("email terkirim = %+v, want satu email ke alice", mail.sent)
+		}
+
+		const prefix = "https://gobase.local/password/reset?token="
+		body := mail.sent[0].Body
+		start := strings.Index(body, prefix)
+		if start < 0 {
+			t.Fatalf("body email tanpa link reset:\n%s", body)
+		}
+		token, err := url.QueryUnescape(strings.Fields(body[start+len(prefix):])[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.value == token || stored.value != hashResetToken(token) {
+			t.Errorf("token disimpan sebagai %v, want hash dari token di email", stored.value)
+		}
+	})
+}
+
+func TestPasswordResetResetPassword(t *testing.T) {
+	const newPassword = "Rahasia2025"
+	expectValidToken := func(mock sqlmock.Sqlmock, token string) {
+		mock.ExpectQuery(`FROM password_reset_tokens t`).WithArgs(hashResetToken(token), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(3, 7))
+	}
+	expectPasswordCheck := func(mock sqlmock.Sqlmock) {
+		old, err := bcrypt.GenerateFromPassword([]byte("PasswordLama1"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mock.ExpectQuery(`SELECT nip, username, name, password\s+FROM users`).WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"nip", "username", "name", "password"}).AddRow(1001, "alice", "Alice", string(old)))
+		mock.ExpectQuery(`SELECT password FROM users WHERE id = \?`).
+			WillReturnRows(sqlmock.NewRows([]string{"password"}).AddRow(string(old)))
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		confirmation string
+		setup        func(sqlmock.Sqlmock)
+		wantErr      error
+		wantRevoked  bool
+	}{
+		{
+			name:         "token valid",
+			token:        "token-valid",
+			confirmation: newPassword,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectValidToken(mock, "token-valid")
+				expectPasswordCheck(mock)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT used_at FROM password_reset_tokens WHERE id = \? FOR UPDATE`).WithArgs(int64(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"used_at"}).AddRow(nil))
+				mock.ExpectExec(`UPDATE users SET password = \?, must_change_password = 0`).WithArgs(sqlmock.AnyArg(), 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO password_histories`).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE password_reset_tokens SET used_at = NOW\(\)`).WithArgs(7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec(`INSERT INTO auth_events`).
+					WithArgs(7, "", "", 7, models.AuthEventSessionRevoked, "10.0.0.2", "", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantRevoked: true,
+		},
+		{
+			name:         "token tidak dikenal atau kedaluwarsa",
+			token:        "token-lama",
+			confirmation: newPassword,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM password_reset_tokens t`).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}))
+			},
+			wantErr: ErrInvalidResetToken,
+		},
+		{
+			// token yang sama dipakai request lain setelah dicek tetapi sebelum password disimpan
+			name:         "token terpakai bersamaan",
+			token:        "token-valid",
+			confirmation: newPassword,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectValidToken(mock, "token-valid")
+				expectPasswordCheck(mock)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT used_at FROM password_reset_tokens WHERE id = \? FOR UPDATE`).
+					WillReturnRows(sqlmock.NewRows([]string{"used_at"}).AddRow(time.Now()))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrInvalidResetToken,
+		},
+		{
+			name:         "konfirmasi berbeda",
+			token:        "token-valid",
+			confirmation: "Rahasia2026",
+			setup:        func(sqlmock.Sqlmock) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mock, _ := newTestPasswordResetService(t)
+			tt.setup(mock)
+
+			backend := sessionstore.NewMemoryBackend()
+			sessionstore.SetDefault(backend)
+			t.Cleanup(func() { sessionstore.SetDefault(nil) })
+			backend.Save(models.Session{ID: "alice-laptop", UserID: 7, IPAddress: "10.0.0.2", ExpiresAt: time.Now().Add(time.Hour)})
+
+			err := svc.ResetPassword(tt.token, newPassword, tt.confirmation)
+			switch {
+			case tt.wantRevoked && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			case !tt.wantRevoked && err == nil:
+				t.Fatal("err = nil, want ditolak")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+
+			remaining, _ := backend.FindByUserID(7)
+			if tt.wantRevoked != (len(remaining) == 0) {
+				t.Errorf("%d session user 7 aktif, want dicabut = %v", len(remaining), tt.wantRevoked)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"gobase-app/config"
+	"gobase-app/repositories"
+	"sync"
+	"time"
+)
+
+// permissionCache menyimpan permission per user di memori proses agar setiap request tidak
+// perlu query ulang. Entry dibuang saat role/permission berubah lewat aplikasi, dan tetap
+// kedaluwarsa setelah PERMISSION_CACHE_TTL_SECONDS (default 60) untuk perubahan dari luar
+// proses ini (mis. instance lain atau edit langsung di database).
+var permissionCache = struct {
+	sync.Mutex
+	entries map[int]permissionCacheEntry
+	// generation naik setiap invalidasi; hasil query yang dimulai sebelum invalidasi tidak disimpan
+	generation uint64
+}{entries: make(map[int]permissionCacheEntry)}
+
+type permissionCacheEntry struct {
+	perms     map[string]bool
+	expiresAt time.Time
+}
+
+// GetUserPermissions mengembalikan salinan map permission user (lewat role beserta role yang
+// diwarisinya, dan permission langsung),
+// memakai cache jika masih berlaku.
+func GetUserPermissions(userID int) (map[string]bool, error) {
+	now := time.Now()
+
+	permissionCache.Lock()
+	entry, ok := permissionCache.entries[userID]
+	generation := permissionCache.generation
+	permissionCache.Unlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return copyPermissions(entry.perms), nil
+	}
+
+	roleIDs, err := effectiveUserRoleIDs(&repositories.RoleRepository{DB: config.DB}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &repositories.PermissionRepository{DB: config.DB}
+	perms, err := repo.GetUserPermissionNames(userID, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(config.EnvInt("PERMISSION_CACHE_TTL_SECONDS", 60)) * time.Second
+	permissionCache.Lock()
+	if permissionCache.generation == generation {
+		permissionCache.entries[userID] = permissionCacheEntry{perms: perms, expiresAt: now.Add(ttl)}
+	}
+	permissionCache.Unlock()
+
+	return copyPermissions(perms), nil
+}
+
+// UserHasPermission mengecek satu permission user lewat cache permission.
+func UserHasPermission(userID int, perm string) (bool, error) {
+	perms, err := GetUserPermissions(userID)
+	if err != nil {
+		return false, err
+	}
+	return perms[perm], nil
+}
+
+// InvalidateUserPermissions membuang cache permission user tertentu, dipanggil setelah role user berubah.
+func InvalidateUserPermissions(userIDs ...int) {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+
+	for _, id := range userIDs {
+		delete(permissionCache.entries, id)
+	}
+	permissionCache.generation++
+}
+
+// InvalidateAllPermissions mengosongkan seluruh cache, dipanggil setelah permission sebuah role
+// berubah atau role dihapus karena bisa memengaruhi banyak user sekaligus.
+func InvalidateAllPermissions() {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+
+	permissionCache.entries = make(map[int]permissionCacheEntry)
+	permissionCache.generation++
+}
+
+func copyPermissions(perms map[string]bool) map[string]bool {
+	result := make(map[string]bool, len(perms))
+	for name, ok := range perms {
+		result[name] = ok
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"database/sql/driver"
+	"gobase-app/config"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// invalidateDuringQuery menjalankan invalidate saat query sedang dieksekusi, meniru perubahan role
+// dari request lain ketika permission user sedang dimuat.
+type invalidateDuringQuery struct {
+	invalidate func()
+}
+
+func (a invalidateDuringQuery) Match(driver.Value) bool {
+	a.invalidate()
+	return true
+}
+
+func useMockDB(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = previous
+		db.Close()
+	})
+	return mock
+}
+
+// seedPermissions mengisi cache permission user agar test tidak perlu query permission.
+func seedPermissions(t *testing.T, userID int, perms ...string) {
+	t.Helper()
+	set := map[string]bool{}
+	for _, perm := range perms {
+		set[perm] = true
+	}
+	permissionCache.Lock()
+	permissionCache.entries[userID] = permissionCacheEntry{perms: set, expiresAt: time.Now().Add(time.Hour)}
+	permissionCache.Unlock()
+	t.Cleanup(func() { InvalidateUserPermissions(userID) })
+}
+
+func expectPermissionLoad(mock sqlmock.Sqlmock, userID int, arg sqlmock.Argument, perms ...string) {
+	mock.ExpectQuery(`SELECT role_id\s+FROM model_has_roles`).
+		WithArgs(userID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id"}))
+	rows := sqlmock.NewRows([]string{"name"})
+	for _, perm := range perms {
+		rows.AddRow(perm)
+	}
+	mock.ExpectQuery(`SELECT p.name\s+FROM permissions p`).
+		WithArgs(userID, arg, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(rows)
+}
+
+func TestPermissionCache(t *testing.T) {
+	const userID = 51
+	invalidate := invalidateDuringQuery{invalidate: func() { InvalidateUserPermissions(userID) }}
+
+	tests := []struct {
+		name string
+		// setup mengharapkan query untuk dua kali GetUserPermissions; between dijalankan di antaranya
+		setup   func(sqlmock.Sqlmock)
+		between func()
+	}{
+		{
+			name:  "hasil di-cache",
+			setup: func(mock sqlmock.Sqlmock) { expectPermissionLoad(mock, userID, sqlmock.AnyArg(), "items.view") },
+		},
+		{
+			name: "invalidasi user memuat ulang",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectPermissionLoad(mock, userID, sqlmock.AnyArg(), "items.view")
+				expectPermissionLoad(mock, userID, sqlmock.AnyArg(), "items.view")
+			},
+			between: func() { InvalidateUserPermissions(userID) },
+		},
+		{
+			name: "invalidasi user lain tidak memuat ulang",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectPermissionLoad(mock, userID, sqlmock.AnyArg(), "items.view")
+			},
+			between: func() { InvalidateUserPermissions(userID + 1) },
+		},
+		{
+			name: "invalidasi seluruh cache memuat ulang",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectPermissionLoad(mock, userID, sqlmock.AnyArg(), "items.view")
+				expectPermissionLoad(mock, userID, sqlmock.AnyArg(), "items.view")
+			},
+			between: InvalidateAllPermissions,
+		},
+		{
+			// hasil query yang dimulai sebelum invalidasi sudah basi dan tidak boleh disimpan
+			name: "invalidasi saat query berjalan tidak menyimpan hasil lama",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectPermissionLoad(mock, userID, invalidate, "items.view")
+				expectPermissionLoad(mock, userID, sqlmock.AnyArg(), "items.view")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := useMockDB(t)
+			InvalidateUserPermissions(userID)
+			t.Cleanup(func() { InvalidateUserPermissions(userID) })
+			tt.setup(mock)
+
+			perms, err := GetUserPermissions(userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !perms["items.view"] {
+				t.Fatalf("perms = %v, want items.view", perms)
+			}
+			// map yang dikembalikan salinan; mengubahnya tidak mengubah cache
+			perms["users.delete"] = true
+
+			if tt.between != nil {
+				tt.between()
+			}
+			perms, err = GetUserPermissions(userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perms["users.delete"] || !perms["items.view"] {
+				t.Errorf("perms = %v, want hanya items.view", perms)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -1,15 +1,173 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
 	"gobase-app/models"
+	"gobase-app/permissions"
 	"gobase-app/repositories"
+	"regexp"
+	"strings"
+	"unicode/utf8"
 )
 
 type PermissionService struct {
 	Repo *repositories.PermissionRepository
 }
 
+// permissionGroupPattern membatasi nama group agar konsisten dengan group bawaan (mis. "user", "app_settings").
+var permissionGroupPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
 func (s *PermissionService) GetGroupedPermissions() ([]models.PermissionGroup, error) {
 	return s.Repo.GetGrouped()
 }
 
+// GetManagedPermissions mengambil permission per group untuk halaman kelola permission, dengan
+// penanda Orphan untuk permission yang tidak lagi dideklarasikan di kode.
+func (s *PermissionService) GetManagedPermissions() ([]models.PermissionGroup, error) {
+	groups, err := s.Repo.GetGrouped()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		for j := range groups[i].Permissions {
+			groups[i].Permissions[j].Orphan = !permissions.Registered(groups[i].Permissions[j].Name)
+		}
+	}
+	return groups, nil
+}
+
+// Sync menyamakan tabel permissions dengan permission yang dideklarasikan di kode: permission yang
+// belum ada dibuat, deskripsi yang masih kosong diisi dari kode, dan nama permission di database
+// yang tidak lagi dideklarasikan dikembalikan sebagai orphan (tidak dihapus otomatis).
+func (s *PermissionService) Sync(actor models.AuditActor) (created, orphans []string, err error) {
+	existing, err := s.Repo.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byName := make(map[string]models.Permission, len(existing))
+	for _, perm := range existing {
+		byName[perm.Name] = perm
+	}
+
+	for _, def := range permissions.All() {
+		perm, ok := byName[def.Name]
+		if !ok {
+			if _, err := s.Repo.Create(models.Permission{
+				Name:        def.Name,
+				GroupName:   def.Group,
+				GuardName:   "web",
+				Description: def.Description,
+			}, actor); err != nil {
+				return created, nil, err
+			}
+			created = append(created, def.Name)
+			continue
+		}
+
+		if perm.Description == "" && def.Description != "" {
+			if err := s.Repo.UpdateDetails(perm.ID, perm.GroupName, def.Description, actor); err != nil {
+				return created, nil, err
+			}
+		}
+	}
+
+	for _, perm := range existing {
+		if !permissions.Registered(perm.Name) {
+			orphans = append(orphans, perm.Name)
+		}
+	}
+
+	if len(created) > 0 {
+		InvalidateAllPermissions()
+	}
+	return created, orphans, nil
+}
+
+// UpdatePermission memvalidasi lalu mengubah group dan deskripsi permission.
+func (s *PermissionService) UpdatePermission(id int64, group, description string, actor models.AuditActor) error {
+	group = strings.TrimSpace(group)
+	description = strings.TrimSpace(description)
+
+	if id <= 0 {
+		return invalidf("permission tidak valid")
+	}
+	if err := validatePermissionGroup(group); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(description) > 255 {
+		return invalidf("deskripsi maksimal 255 karakter")
+	}
+
+	if _, err := s.GetPermission(id); err != nil {
+		return err
+	}
+
+	return s.Repo.UpdateDetails(id, group, description, actor)
+}
+
+// RenameGroup memindahkan seluruh permission di group from ke group to.
+func (s *PermissionService) RenameGroup(from, to string, actor models.AuditActor) (int, error) {
+	from = strings.TrimSpace(from)
+	to = strings.TrimSpace(to)
+
+	if err := validatePermissionGroup(to); err != nil {
+		return 0, err
+	}
+	if from == to {
+		return 0, invalidf("nama group baru sama dengan nama lama")
+	}
+
+	count, err := s.Repo.RenameGroup(from, to, actor)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, notFoundf("group %s tidak ditemukan", from)
+	}
+	return count, nil
+}
+
+// DeletePermission menghapus permission orphan. Permission yang masih dideklarasikan di kode
+// tidak bisa dihapus karena akan dibuat ulang saat aplikasi start.
+func (s *PermissionService) DeletePermission(id int64, actor models.AuditActor) error {
+	perm, err := s.GetPermission(id)
+	if err != nil {
+		return err
+	}
+	if permissions.Registered(perm.Name) {
+		return conflictf("permission %s masih dipakai di kode dan tidak bisa dihapus", perm.Name)
+	}
+
+	if err := s.Repo.DeleteByID(id, actor); err != nil {
+		return err
+	}
+
+	InvalidateAllPermissions()
+	return nil
+}
+
+// GetPermission mengambil satu permission berdasarkan ID.
+func (s *PermissionService) GetPermission(id int64) (*models.Permission, error) {
+	if id <= 0 {
+		return nil, invalidf("permission tidak valid")
+	}
+
+	perm, err := s.Repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundf("permission dengan id %d tidak ditemukan", id)
+	}
+	return perm, err
+}
+
+func validatePermissionGroup(group string) error {
+	if group == "" {
+		return invalidf("nama group wajib diisi")
+	}
+	if len(group) > 255 || !permissionGroupPattern.MatchString(group) {
+		return invalidf("nama group hanya boleh huruf kecil, angka, dan garis bawah")
+	}
+	return nil
+}
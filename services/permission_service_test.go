@@ -0,0 +1,111 @@
+package services
+
+import (
+	"gobase-app/models"
+	"gobase-app/permissions"
+	"gobase-app/repositories"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectPermissionSnapshot mengharapkan snapshot audit satu permission di dalam transaksi.
+func expectPermissionSnapshot(mock sqlmock.Sqlmock, id int64, name, group, description string) {
+	mock.ExpectQuery("SELECT name, `group`, description, guard_name\\s+FROM permissions\\s+WHERE id = \\?").WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "group", "description", "guard_name"}).AddRow(name, group, description, "web"))
+}
+
+func TestPermissionServiceSync(t *testing.T) {
+	// registry diisi package routes yang tidak di-import di sini
+	for _, def := range []permissions.Definition{
+		{Name: "items.view", Group: "items", Description: "Lihat item"},
+		{Name: "items.edit", Group: "items", Description: "Ubah item"},
+		{Name: "items.delete", Group: "items", Description: "Hapus item"},
+	} {
+		if !permissions.Registered(def.Name) {
+			permissions.Register(def)
+		}
+	}
+	actor := models.AuditActor{UserID: 1, Username: "admin"}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// items.view sudah diubah admin (group dan deskripsi) sehingga tidak boleh ditimpa kode,
+	// items.delete belum punya deskripsi, items.edit belum ada, legacy.report tidak lagi dideklarasikan
+	mock.ExpectQuery(`FROM permissions\s+ORDER BY group_name, name`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "group_name", "guard_name", "description"}).
+			AddRow(1, "items.view", "barang", "web", "Lihat barang").
+			AddRow(2, "items.delete", "items", "web", "").
+			AddRow(9, "legacy.report", "report", "web", "Laporan lama"))
+
+	mock.ExpectBegin()
+	expectPermissionSnapshot(mock, 2, "items.delete", "items", "")
+	mock.ExpectExec("UPDATE permissions\\s+SET `group` = \\?, description = \\?").WithArgs("items", "Hapus item", int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectPermissionSnapshot(mock, 2, "items.delete", "items", "Hapus item")
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(1, "admin", "permission.update", "permission", int64(2), `{"description":""}`, `{"description":"Hapus item"}`, "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO permissions \\(name, `group`, description, guard_name").
+		WithArgs("items.edit", "items", "Ubah item", "web").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	expectPermissionSnapshot(mock, 3, "items.edit", "items", "Ubah item")
+	mock.ExpectExec(`INSERT INTO audit_logs`).
+		WithArgs(1, "admin", "permission.create", "permission", int64(3), nil, sqlmock.AnyArg(), "", "").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	svc := &PermissionService{Repo: &repositories.PermissionRepository{DB: db}}
+	created, orphans, err := svc.Sync(actor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"items.edit"}; !reflect.DeepEqual(created, want) {
+		t.Errorf("created = %v, want %v", created, want)
+	}
+	if want := []string{"legacy.report"}; !reflect.DeepEqual(orphans, want) {
+		t.Errorf("orphans = %v, want %v", orphans, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPermissionServiceSyncUpToDate(t *testing.T) {
+	if !permissions.Registered("items.view") {
+		permissions.Register(permissions.Definition{Name: "items.view", Group: "items", Description: "Lihat item"})
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "group_name", "guard_name", "description"})
+	for i, def := range permissions.All() {
+		rows.AddRow(i+1, def.Name, def.Group, "web", def.Description)
+	}
+	mock.ExpectQuery(`FROM permissions\s+ORDER BY group_name, name`).WillReturnRows(rows)
+
+	// semua permission sudah ada sehingga tidak ada transaksi sama sekali
+	svc := &PermissionService{Repo: &repositories.PermissionRepository{DB: db}}
+	created, orphans, err := svc.Sync(models.AuditActor{UserID: 1, Username: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 0 || len(orphans) != 0 {
+		t.Errorf("created = %v, orphans = %v, want keduanya kosong", created, orphans)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
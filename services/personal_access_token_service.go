@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccessTokenPrefix ditambahkan di depan setiap token agar mudah dikenali (mis. oleh secret scanner).
+const AccessTokenPrefix = "gbp_"
+
+// AccessTokenExpiryOptions adalah pilihan masa berlaku token dalam hari; 0 berarti tanpa kedaluwarsa.
+var AccessTokenExpiryOptions = []int{30, 90, 365, 0}
+
+// AccessTokenTouchInterval adalah jarak minimal antar pencatatan last_used_at token.
+const AccessTokenTouchInterval = time.Minute
+
+// ErrInvalidAccessToken dikembalikan ketika Bearer token tidak dikenal, kedaluwarsa, atau milik user non aktif.
+var ErrInvalidAccessToken = errors.New("token API tidak valid atau sudah kedaluwarsa")
+
+// PersonalAccessTokenService mengelola token API milik user. Token disimpan sebagai hash
+// SHA-256 dan hanya bisa memakai permission (abilities) yang dipilih saat dibuat.
+type PersonalAccessTokenService struct {
+	Repo *repositories.PersonalAccessTokenRepository
+}
+
+// CreateToken membuat token baru dan mengembalikan token asli yang hanya ditampilkan sekali.
+// abilities wajib subset dari permission user saat ini.
+func (s *PersonalAccessTokenService) CreateToken(userID int, name string, abilities []string, expiresInDays int) (string, error) {
+	name = strings.TrimSpace(name)
+	if userID <= 0 {
+		return "", invalidf("user tidak valid")
+	}
+	if name == "" {
+		return "", invalidf("nama token wajib diisi")
+	}
+	if len(name) > 255 {
+		return "", invalidf("nama token maksimal 255 karakter")
+	}
+	if !validTokenExpiry(expiresInDays) {
+		return "", invalidf("masa berlaku token tidak valid")
+	}
+
+	abilities = uniqueStrings(abilities)
+	if len(abilities) == 0 {
+		return "", invalidf("pilih minimal satu permission untuk token")
+	}
+
+	owned, err := GetUserPermissions(userID)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	for _, ability := range abilities {
+		if !owned[ability] {
+			missing = append(missing, ability)
+		}
+	}
+	if len(missing) > 0 {
+		return "", invalidf("permission tidak dimiliki: %s", strings.Join(missing, ", "))
+	}
+	sort.Strings(abilities)
+
+	random, err := generateResetToken()
+	if err != nil {
+		return "", err
+	}
+	plain := AccessTokenPrefix + random
+
+	var expiresAt *time.Time
+	if expiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, expiresInDays)
+		expiresAt = &t
+	}
+
+	if _, err := s.Repo.Create(userID, name, hashResetToken(plain), abilities, expiresAt); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// ListTokens mengambil token milik user lengkap dengan format tampilan.
+func (s *PersonalAccessTokenService) ListTokens(userID int) ([]models.PersonalAccessToken, error) {
+	tokens, err := s.Repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range tokens {
+		t := &tokens[i]
+		t.CreatedAtDisplay = t.CreatedAt.Format("02 Jan 2006 15:04")
+		t.LastUsedDisplay = "Belum pernah"
+		if t.LastUsedAt != nil {
+			t.LastUsedDisplay = t.LastUsedAt.Format("02 Jan 2006 15:04")
+		}
+		t.ExpiresAtDisplay = "Tidak pernah"
+		if t.ExpiresAt != nil {
+			t.ExpiresAtDisplay = t.ExpiresAt.Format("02 Jan 2006 15:04")
+			t.Expired = !t.ExpiresAt.After(now)
+		}
+	}
+	return tokens, nil
+}
+
+// RevokeToken menghapus token milik user sehingga tidak bisa dipakai lagi.
+func (s *PersonalAccessTokenService) RevokeToken(userID int, tokenID int64) error {
+	if tokenID <= 0 {
+		return invalidf("token tidak valid")
+	}
+
+	deleted, err := s.Repo.DeleteForUser(userID, tokenID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return notFoundf("token tidak ditemukan")
+	}
+	return nil
+}
+
+// Authenticate memvalidasi Bearer token dan mengembalikan principal-nya.
+func (s *PersonalAccessTokenService) Authenticate(plain string) (*models.TokenPrincipal, error) {
+	plain = strings.TrimSpace(plain)
+	if !strings.HasPrefix(plain, AccessTokenPrefix) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	now := time.Now()
+	principal, err := s.Repo.FindPrincipal(hashResetToken(plain), now)
+	if err != nil {
+		return nil, err
+	}
+	if principal == nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	// last_used_at cukup akurat per menit; integrasi yang memanggil API berkali-kali per detik
+	// tidak perlu menulis ke tabel token pada setiap request.
+	if principal.LastUsedAt == nil || now.Sub(*principal.LastUsedAt) >= AccessTokenTouchInterval {
+		if err := s.Repo.TouchLastUsed(principal.TokenID, now); err != nil {
+			return nil, err
+		}
+	}
+	return principal, nil
+}
+
+func validTokenExpiry(days int) bool {
+	for _, d := range AccessTokenExpiryOptions {
+		if d == days {
+			return true
+		}
+	}
+	return false
+}
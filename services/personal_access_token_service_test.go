@@ -0,0 +1,176 @@
+package services
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"gobase-app/repositories"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// captureArg mencocokkan argumen query apa pun dan menyimpan nilainya.
+type captureArg struct {
+	value driver.Value
+}
+
+func (a *captureArg) Match(v driver.Value) bool {
+	a.value = v
+	return true
+}
+
+func TestPersonalAccessTokenStoredAsHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	seedPermissions(t, 5, "items.view", "stock.view")
+
+	stored := &captureArg{}
+	mock.ExpectExec(`INSERT INTO personal_access_tokens`).
+		WithArgs(sqlmock.AnyArg(), 5, "laporan", stored, `["items.view","stock.view"]`, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	svc := &PersonalAccessTokenService{Repo: &repositories.PersonalAccessTokenRepository{DB: db}}
+	plain, err := svc.CreateToken(5, "laporan", []string{"stock.view", "items.view", "stock.view"}, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(plain, AccessTokenPrefix) {
+		t.Errorf("token = %q, want prefix %s", plain, AccessTokenPrefix)
+	}
+	sum := sha256.Sum256([]byte(plain))
+	if stored.value != hex.EncodeToString(sum[:]) {
+		t.Fatalf("token disimpan sebagai %v, want hash SHA-256 token", stored.value)
+	}
+
+	// token dicari berdasarkan hash, dan last_used_at dicatat
+	mock.ExpectQuery(`FROM personal_access_tokens t`).
+		WithArgs(stored.value, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "username", "abilities", "last_used_at"}).
+			AddRow(3, 5, "alice", `["items.view","stock.view"]`, nil))
+	mock.ExpectExec(`UPDATE personal_access_tokens SET last_used_at`).
+		WithArgs(sqlmock.AnyArg(), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	principal, err := svc.Authenticate(" " + plain + " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.UserID != 5 || !principal.Abilities["items.view"] || !principal.Abilities["stock.view"] || len(principal.Abilities) != 2 {
+		t.Errorf("principal = %+v", principal)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPersonalAccessTokenTouchesLastUsedOncePerInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		lastUsed  interface{}
+		wantTouch bool
+	}{
+		{name: "belum pernah dipakai", lastUsed: nil, wantTouch: true},
+		{name: "dipakai beberapa detik lalu", lastUsed: time.Now().Add(-10 * time.Second)},
+		{name: "dipakai lebih dari satu menit lalu", lastUsed: time.Now().Add(-2 * time.Minute), wantTouch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(`FROM personal_access_tokens t`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "username", "abilities", "last_used_at"}).
+					AddRow(3, 5, "alice", `["items.view"]`, tt.lastUsed))
+			if tt.wantTouch {
+				mock.ExpectExec(`UPDATE personal_access_tokens SET last_used_at`).
+					WithArgs(sqlmock.AnyArg(), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			svc := &PersonalAccessTokenService{Repo: &repositories.PersonalAccessTokenRepository{DB: db}}
+			if _, err := svc.Authenticate(AccessTokenPrefix + "rahasia"); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPersonalAccessTokenAbilitiesMustBeOwned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	seedPermissions(t, 5, "items.view")
+
+	svc := &PersonalAccessTokenService{Repo: &repositories.PersonalAccessTokenRepository{DB: db}}
+	_, err = svc.CreateToken(5, "laporan", []string{"items.view", "users.delete"}, 30)
+	if !IsClientError(err) || !strings.Contains(err.Error(), "users.delete") {
+		t.Fatalf("err = %v, want penolakan ability users.delete", err)
+	}
+	if _, err := svc.CreateToken(5, "laporan", nil, 30); !IsClientError(err) {
+		t.Errorf("err = %v, want token tanpa ability ditolak", err)
+	}
+	if _, err := svc.CreateToken(5, "laporan", []string{"items.view"}, 7); !IsClientError(err) {
+		t.Errorf("err = %v, want masa berlaku di luar pilihan ditolak", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPersonalAccessTokenAuthenticateRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		setup func(sqlmock.Sqlmock)
+	}{
+		{
+			// token tanpa prefix ditolak tanpa query ke database
+			name:  "tanpa prefix",
+			token: "rahasia",
+			setup: func(sqlmock.Sqlmock) {},
+		},
+		{
+			name:  "tidak dikenal, kedaluwarsa, atau user nonaktif",
+			token: AccessTokenPrefix + "tidak-ada",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM personal_access_tokens t`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "username", "abilities"}))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			tt.setup(mock)
+
+			svc := &PersonalAccessTokenService{Repo: &repositories.PersonalAccessTokenRepository{DB: db}}
+			if _, err := svc.Authenticate(tt.token); !errors.Is(err, ErrInvalidAccessToken) {
+				t.Errorf("err = %v, want ErrInvalidAccessToken", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
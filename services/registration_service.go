@@ -0,0 +1,133 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gobase-app/config"
+	"gobase-app/mailer"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrInvalidVerificationToken dikembalikan untuk link verifikasi yang tidak valid atau kedaluwarsa.
+var ErrInvalidVerificationToken = errors.New("link verifikasi email tidak valid atau sudah kedaluwarsa")
+
+// ErrVerificationMailFailed dikembalikan jika email verifikasi gagal dikirim; pendaftaran dibatalkan.
+var ErrVerificationMailFailed = errors.New("email verifikasi gagal dikirim, silakan coba lagi")
+
+// registrationConflictMessage menggantikan pesan bentrok username, NIP, atau email agar form
+// registrasi publik tidak membocorkan data mana yang sudah terdaftar.
+const registrationConflictMessage = "data pendaftaran tidak dapat digunakan, periksa kembali isian Anda atau hubungi administrator"
+
+// RegistrationService menangani registrasi mandiri yang diaktifkan lewat REGISTRATION_ENABLED.
+// User baru berstatus pending, wajib memverifikasi email, lalu menunggu approval admin.
+type RegistrationService struct {
+	Users  *UserService
+	Repo   *repositories.RegistrationRepository
+	Mailer mailer.Mailer
+}
+
+// RegistrationEnabled mengecek apakah registrasi mandiri diaktifkan.
+func RegistrationEnabled() bool {
+	return config.EnvBool("REGISTRATION_ENABLED")
+}
+
+// Register membuat user pending lewat validasi UserService lalu mengirim link verifikasi email.
+// Role tidak bisa dipilih sendiri; admin menentukannya saat approval.
+func (s *RegistrationService) Register(input models.UserCreateInput, confirmation string, actor models.AuditActor) error {
+	if input.Password != confirmation {
+		return invalidf("konfirmasi password tidak sama")
+	}
+
+	input.Status = "pending"
+	input.RoleNames = nil
+	input.MustChangePassword = false
+
+	userID, err := s.Users.createUser(input, actor)
+	if errors.Is(err, ErrConflict) {
+		return conflictf(registrationConflictMessage)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.sendVerification(userID, strings.TrimSpace(input.Name), strings.TrimSpace(input.Email)); err != nil {
+		// tanpa email verifikasi akun tidak akan pernah bisa di-approve, jadi batalkan pendaftarannya
+		if delErr := s.Users.Repo.DeleteUser(userID, actor); delErr != nil {
+			return delErr
+		}
+		return fmt.Errorf("%w: %v", ErrVerificationMailFailed, err)
+	}
+
+	return nil
+}
+
+// VerifyEmail menandai email user terverifikasi berdasarkan token dari link email.
+func (s *RegistrationService) VerifyEmail(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	err := s.Repo.VerifyEmail(hashResetToken(token), time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidVerificationToken
+	}
+	return err
+}
+
+// Approve mengaktifkan user pending yang emailnya sudah terverifikasi. Seperti mengubah user,
+// actor non-admin hanya boleh menyetujui pendaftar di store miliknya.
+func (s *RegistrationService) Approve(userID int, actor models.AuditActor, principal models.Principal) error {
+	status, verified, err := s.Repo.GetApprovalState(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundf("user tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+	if status != "pending" {
+		return invalidf("user tidak sedang menunggu persetujuan")
+	}
+	if !verified {
+		return invalidf("email user belum diverifikasi")
+	}
+
+	guard, err := newDelegationGuard(s.Users.Repo.DB, principal)
+	if err != nil {
+		return err
+	}
+	if err := guard.ensureManageUser(s.Users.Repo, userID); err != nil {
+		return err
+	}
+
+	return s.Repo.Approve(userID, actor)
+}
+
+func (s *RegistrationService) sendVerification(userID int, name, email string) error {
+	token, err := generateResetToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Duration(config.EnvInt("REGISTRATION_VERIFY_TTL_HOURS", 24)) * time.Hour
+	if err := s.Repo.CreateVerificationToken(userID, hashResetToken(token), time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	link := strings.TrimRight(os.Getenv("BASE_URL"), "/") + "/register/verify?token=" + url.QueryEscape(token)
+
+	return s.Mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verifikasi email pendaftaran akun",
+		Body: fmt.Sprintf("Halo %s,\n\n"+
+			"Terima kasih telah mendaftar. Buka link berikut untuk memverifikasi email Anda:\n\n"+
+			"%s\n\n"+
+			"Link ini berlaku selama %d jam. Setelah email terverifikasi, akun Anda akan aktif "+
+			"setelah disetujui oleh administrator.\n", name, link, int(ttl.Hours())),
+	})
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRegistrationHidesConflictingField(t *testing.T) {
+	for _, column := range []string{"username", "nip", "email"} {
+		t.Run(column, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			// createUser mengecek username, NIP, lalu email dan berhenti di data pertama yang bentrok
+			for _, c := range []string{"username", "nip", "email"} {
+				taken := c == column
+				count := 0
+				if taken {
+					count = 1
+				}
+				mock.ExpectQuery(`SELECT COUNT\(1\) FROM users WHERE ` + c + ` = \?`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+				if taken {
+					break
+				}
+			}
+
+			svc := &RegistrationService{
+				Users: &UserService{Repo: &repositories.UserRepository{DB: db}},
+				Repo:  &repositories.RegistrationRepository{DB: db},
+			}
+			err = svc.Register(models.UserCreateInput{
+				NIP:      1001,
+				Name:     "Alice",
+				Username: "alice",
+				Password: "Rahasia2025",
+				Email:    "alice@kampus.local",
+				StoreIDs: []int{1},
+			}, "Rahasia2025", models.AuditActor{Username: "alice"})
+			if err == nil || err.Error() != registrationConflictMessage {
+				t.Fatalf("err = %v, want pesan umum %q", err, registrationConflictMessage)
+			}
+			for _, leaked := range []string{"alice", "1001", "sudah digunakan"} {
+				if strings.Contains(err.Error(), leaked) {
+					t.Errorf("pesan %q membocorkan %q", err, leaked)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRegistrationApproveRequiresSharedStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	seedPermissions(t, delegatingUser, "user_create")
+
+	mock.ExpectQuery(`SELECT status, email_verified_at IS NOT NULL`).WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "verified"}).AddRow("pending", true))
+	expectAdminCheck(mock, delegatingUser, false)
+	expectUserStores(mock, delegatingUser, 1, 2)
+	mock.ExpectQuery(`FROM model_has_store_permissions mhsp`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "store_id"}))
+	// pendaftar memilih store 5 yang bukan milik actor
+	expectUserStores(mock, 9, 5)
+
+	svc := &RegistrationService{
+		Users: &UserService{Repo: &repositories.UserRepository{DB: db}},
+		Repo:  &repositories.RegistrationRepository{DB: db},
+	}
+	if err := svc.Approve(9, models.AuditActor{UserID: delegatingUser}, models.Principal{UserID: delegatingUser}); !IsClientError(err) {
+		t.Fatalf("err = %v, want approval ditolak", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"gobase-app/repositories"
+	"sort"
+	"strings"
+)
+
+// roleAncestors mengembalikan seluruh leluhur roleID (parent, parent dari parent, dst.) tanpa
+// roleID sendiri. Data yang terlanjur bersiklus tidak membuat penelusuran berputar selamanya.
+func roleAncestors(parents map[int][]int, roleID int) []int {
+	seen := map[int]bool{roleID: true}
+	queue := append([]int(nil), parents[roleID]...)
+
+	var result []int
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		queue = append(queue, parents[id]...)
+	}
+	return result
+}
+
+// expandRoleIDs mengembalikan roleIDs beserta seluruh leluhurnya tanpa duplikat, terurut.
+func expandRoleIDs(parents map[int][]int, roleIDs []int) []int {
+	seen := make(map[int]bool)
+	var result []int
+	for _, id := range roleIDs {
+		for _, roleID := range append([]int{id}, roleAncestors(parents, id)...) {
+			if !seen[roleID] {
+				seen[roleID] = true
+				result = append(result, roleID)
+			}
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+// effectiveUserRoleIDs mengembalikan role user beserta seluruh role yang diwarisinya.
+func effectiveUserRoleIDs(roles *repositories.RoleRepository, userID int) ([]int, error) {
+	roleIDs, err := roles.GetUserRoleIDs(userID)
+	if err != nil || len(roleIDs) == 0 {
+		return roleIDs, err
+	}
+
+	parents, err := roles.GetParentMap()
+	if err != nil {
+		return nil, err
+	}
+	return expandRoleIDs(parents, roleIDs), nil
+}
+
+// validateRoleParents merapikan daftar parent dan memastikan semuanya ada, bukan role admin, bukan
+// role itu sendiri, dan tidak membentuk siklus. roleID 0 berarti role baru (belum bisa menjadi
+// leluhur siapa pun). Role admin hanya berlaku jika dimiliki langsung oleh user sehingga tidak
+// boleh diwarisi.
+func validateRoleParents(roles *repositories.RoleRepository, roleID int, parentIDs []int) ([]int, error) {
+	parentIDs = uniqueInts(parentIDs)
+	if len(parentIDs) == 0 {
+		return parentIDs, nil
+	}
+
+	names, err := roles.GetNames()
+	if err != nil {
+		return nil, err
+	}
+	parents, err := roles.GetParentMap()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, parentID := range parentIDs {
+		if _, ok := names[parentID]; !ok {
+			return nil, invalidf("parent role dengan id %d tidak ditemukan", parentID)
+		}
+	}
+	ids := make([]int64, len(parentIDs))
+	for i, id := range parentIDs {
+		ids[i] = int64(id)
+	}
+	hasAdmin, err := roles.ContainsAdminRole(ids)
+	if err != nil {
+		return nil, err
+	}
+	if hasAdmin {
+		return nil, invalidf("role admin tidak bisa dijadikan parent; berikan role admin langsung ke user")
+	}
+
+	for _, parentID := range parentIDs {
+		if roleID == 0 {
+			continue
+		}
+		if parentID == roleID {
+			return nil, invalidf("role tidak bisa mewarisi dirinya sendiri")
+		}
+		if path := roleInheritancePath(parents, parentID, roleID); path != nil {
+			labels := make([]string, 0, len(path)+1)
+			labels = append(labels, names[roleID])
+			for _, id := range path {
+				labels = append(labels, names[id])
+			}
+			return nil, invalidf("role %s tidak bisa menjadi parent karena akan membentuk siklus: %s",
+				names[parentID], strings.Join(labels, " → "))
+		}
+	}
+
+	return parentIDs, nil
+}
+
+// roleHasChildren mengecek apakah ada role lain yang mewarisi roleID secara langsung.
+func roleHasChildren(parents map[int][]int, roleID int) bool {
+	for _, parentIDs := range parents {
+		for _, id := range parentIDs {
+			if id == roleID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// roleInheritancePath mencari jalur pewarisan dari role from sampai ke leluhurnya target.
+// Mengembalikan nil jika target bukan from maupun leluhur from.
+func roleInheritancePath(parents map[int][]int, from, target int) []int {
+	prev := map[int]int{from: 0}
+	queue := []int{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == target {
+			var path []int
+			for cur := id; cur != 0; cur = prev[cur] {
+				path = append([]int{cur}, path...)
+			}
+			return path
+		}
+		for _, parentID := range parents[id] {
+			if _, seen := prev[parentID]; !seen {
+				prev[parentID] = id
+				queue = append(queue, parentID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"gobase-app/repositories"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestValidateRoleParents(t *testing.T) {
+	// role 1 super-admin (admin), role 3 auditor, role 4 supervisor mewarisi auditor
+	expectRoles := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT id, name FROM roles`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "super-admin").AddRow(3, "auditor").AddRow(4, "supervisor"))
+		mock.ExpectQuery(`SELECT role_id, parent_id FROM role_parents`).
+			WillReturnRows(sqlmock.NewRows([]string{"role_id", "parent_id"}).AddRow(4, 3))
+	}
+	expectAdminParents := func(mock sqlmock.Sqlmock, count int) {
+		mock.ExpectQuery(`SELECT COUNT\(1\) FROM roles WHERE is_admin = 1`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+	}
+
+	tests := []struct {
+		name      string
+		roleID    int
+		parentIDs []int
+		setup     func(sqlmock.Sqlmock)
+		want      []int
+		wantError bool
+	}{
+		{
+			name:      "parent valid tanpa duplikat",
+			roleID:    4,
+			parentIDs: []int{3, 3},
+			setup:     func(mock sqlmock.Sqlmock) { expectRoles(mock); expectAdminParents(mock, 0) },
+			want:      []int{3},
+		},
+		{
+			name:      "role admin tidak bisa diwarisi",
+			roleID:    4,
+			parentIDs: []int{1},
+			setup:     func(mock sqlmock.Sqlmock) { expectRoles(mock); expectAdminParents(mock, 1) },
+			wantError: true,
+		},
+		{
+			name:      "parent tidak ditemukan",
+			parentIDs: []int{9},
+			setup:     expectRoles,
+			wantError: true,
+		},
+		{
+			name:      "siklus pewarisan",
+			roleID:    3,
+			parentIDs: []int{4},
+			setup:     func(mock sqlmock.Sqlmock) { expectRoles(mock); expectAdminParents(mock, 0) },
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			tt.setup(mock)
+
+			got, err := validateRoleParents(&repositories.RoleRepository{DB: db}, tt.roleID, tt.parentIDs)
+			switch {
+			case tt.wantError && !IsClientError(err):
+				t.Fatalf("err = %v, want error validasi", err)
+			case !tt.wantError && err != nil:
+				t.Fatal(err)
+			case !tt.wantError && !reflect.DeepEqual(got, tt.want):
+				t.Errorf("parent = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -3,9 +3,9 @@ package services
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 	"gobase-app/models"
 	"gobase-app/repositories"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -21,13 +21,13 @@ func (s *RoleService) GetRoles() ([]models.Role, error) {
 // GetRoleDetail mengambil detail role beserta permission yang dimilikinya.
 func (s *RoleService) GetRoleDetail(id int) (*models.RoleDetail, error) {
 	if id <= 0 {
-		return nil, errors.New("role id tidak valid")
+		return nil, invalidf("role id tidak valid")
 	}
 
 	role, err := s.Repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("role dengan id %d tidak ditemukan", id)
+			return nil, notFoundf("role dengan id %d tidak ditemukan", id)
 		}
 		return nil, err
 	}
@@ -36,8 +36,10 @@ func (s *RoleService) GetRoleDetail(id int) (*models.RoleDetail, error) {
 	return role, nil
 }
 
-// CreateRole memvalidasi input lalu menyimpan role baru beserta permission yang dipilih.
-func (s *RoleService) CreateRole(input models.RoleCreateInput) error {
+// CreateRole memvalidasi input lalu menyimpan role baru beserta permission dan parent yang dipilih.
+// Actor hanya boleh memberikan permission (termasuk warisan parent) yang ia miliki sendiri.
+// Mengembalikan id role baru.
+func (s *RoleService) CreateRole(input models.RoleCreateInput, actor models.AuditActor, principal models.Principal) (int, error) {
 	name := strings.TrimSpace(input.Name)
 	guard := strings.TrimSpace(input.GuardName)
 	if guard == "" {
@@ -45,22 +47,27 @@ func (s *RoleService) CreateRole(input models.RoleCreateInput) error {
 	}
 
 	if name == "" {
-		return errors.New("nama role wajib diisi")
+		return 0, invalidf("nama role wajib diisi")
+	}
+	if input.IsAdmin {
+		if err := requireAdminActor(s.Repo, principal, "membuat role admin"); err != nil {
+			return 0, err
+		}
 	}
 
 	exists, err := s.Repo.ExistsByNameAndGuard(name, guard)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if exists {
-		return fmt.Errorf("role '%s' sudah ada pada guard %s", name, guard)
+		return 0, conflictf("role '%s' sudah ada pada guard %s", name, guard)
 	}
 
 	permIDs := uniqueInt64(input.PermissionIDs)
 	if len(permIDs) > 0 {
 		found, err := s.Repo.FindExistingPermissionIDs(permIDs)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		var missing []int64
@@ -71,22 +78,39 @@ func (s *RoleService) CreateRole(input models.RoleCreateInput) error {
 		}
 
 		if len(missing) > 0 {
-			return fmt.Errorf("permission tidak ditemukan: %s", formatInt64Slice(missing))
+			return 0, invalidf("permission tidak ditemukan: %s", formatInt64Slice(missing))
 		}
 	}
 
-	_, err = s.Repo.CreateRoleWithPermissions(repositories.RoleCreateParams{
-		Name:          name,
-		GuardName:     guard,
-		IsAdmin:       false,
-		PermissionIDs: permIDs,
-	})
+	parentIDs, err := validateRoleParents(s.Repo, 0, input.ParentIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	delegation, err := newDelegationGuard(s.Repo.DB, principal)
+	if err != nil {
+		return 0, err
+	}
+	if err := delegation.ensureRolePermissions(0, permIDs, parentIDs); err != nil {
+		return 0, err
+	}
+
+	id, err := s.Repo.CreateRoleWithPermissions(repositories.RoleCreateParams{
+		Name:              name,
+		GuardName:         guard,
+		IsAdmin:           input.IsAdmin,
+		RequiresTwoFactor: input.RequiresTwoFactor,
+		PermissionIDs:     permIDs,
+		ParentIDs:         parentIDs,
+	}, actor)
 
-	return err
+	return int(id), err
 }
 
-// UpdateRole memvalidasi input lalu memperbarui role beserta permission yang dipilih.
-func (s *RoleService) UpdateRole(input models.RoleUpdateInput) error {
+// UpdateRole memvalidasi input lalu memperbarui role beserta permission dan parent yang dipilih.
+// Parent yang akan membentuk siklus pewarisan ditolak, begitu juga tambahan permission efektif
+// yang tidak dimiliki actor.
+func (s *RoleService) UpdateRole(input models.RoleUpdateInput, actor models.AuditActor, principal models.Principal) error {
 	name := strings.TrimSpace(input.Name)
 	guard := strings.TrimSpace(input.GuardName)
 	if guard == "" {
@@ -94,26 +118,47 @@ func (s *RoleService) UpdateRole(input models.RoleUpdateInput) error {
 	}
 
 	if input.ID <= 0 {
-		return errors.New("role tidak valid")
+		return invalidf("role tidak valid")
 	}
 	if name == "" {
-		return errors.New("nama role wajib diisi")
+		return invalidf("nama role wajib diisi")
 	}
 
 	role, err := s.Repo.GetByID(input.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("role dengan id %d tidak ditemukan", input.ID)
+			return notFoundf("role dengan id %d tidak ditemukan", input.ID)
 		}
 		return err
 	}
 
+	if role.IsAdmin || input.IsAdmin {
+		if err := requireAdminActor(s.Repo, principal, "mengubah role admin"); err != nil {
+			return err
+		}
+	}
+	if role.IsAdmin && !input.IsAdmin {
+		if err := ensureOtherAdminRole(s.Repo, input.ID); err != nil {
+			return err
+		}
+	}
+	if !role.IsAdmin && input.IsAdmin {
+		parents, err := s.Repo.GetParentMap()
+		if err != nil {
+			return err
+		}
+		// role admin tidak boleh diwarisi, jadi role yang sudah menjadi parent tidak bisa dijadikan admin
+		if roleHasChildren(parents, input.ID) {
+			return invalidf("role %s diwarisi role lain sehingga tidak bisa dijadikan role admin", role.Name)
+		}
+	}
+
 	exists, err := s.Repo.ExistsByNameAndGuardExceptID(name, guard, input.ID)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return fmt.Errorf("role '%s' sudah ada pada guard %s", name, guard)
+		return conflictf("role '%s' sudah ada pada guard %s", name, guard)
 	}
 
 	permIDs := uniqueInt64(input.PermissionIDs)
@@ -131,25 +176,161 @@ func (s *RoleService) UpdateRole(input models.RoleUpdateInput) error {
 		}
 
 		if len(missing) > 0 {
-			return fmt.Errorf("permission tidak ditemukan: %s", formatInt64Slice(missing))
+			return invalidf("permission tidak ditemukan: %s", formatInt64Slice(missing))
 		}
 	}
 
-	return s.Repo.UpdateRoleWithPermissions(repositories.RoleUpdateParams{
-		ID:            input.ID,
-		Name:          name,
-		GuardName:     guard,
-		IsAdmin:       role.IsAdmin,
-		PermissionIDs: permIDs,
-	})
+	parentIDs, err := validateRoleParents(s.Repo, input.ID, input.ParentIDs)
+	if err != nil {
+		return err
+	}
+
+	delegation, err := newDelegationGuard(s.Repo.DB, principal)
+	if err != nil {
+		return err
+	}
+	if err := delegation.ensureRolePermissions(input.ID, permIDs, parentIDs); err != nil {
+		return err
+	}
+
+	if err := s.Repo.UpdateRoleWithPermissions(repositories.RoleUpdateParams{
+		ID:                input.ID,
+		Name:              name,
+		GuardName:         guard,
+		IsAdmin:           input.IsAdmin,
+		RequiresTwoFactor: input.RequiresTwoFactor,
+		PermissionIDs:     permIDs,
+		ParentIDs:         parentIDs,
+	}, actor); err != nil {
+		return err
+	}
+
+	InvalidateAllPermissions()
+	return nil
 }
 
 // DeleteRole validates input and removes the role by ID.
-func (s *RoleService) DeleteRole(id int) error {
+// Role admin hanya bisa dihapus oleh admin dan tidak boleh role admin terakhir.
+func (s *RoleService) DeleteRole(id int, actor models.AuditActor, principal models.Principal) error {
 	if id <= 0 {
-		return errors.New("role id tidak valid")
+		return invalidf("role id tidak valid")
+	}
+
+	role, err := s.Repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundf("role dengan id %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return err
+	}
+	if role.IsAdmin {
+		if err := requireAdminActor(s.Repo, principal, "menghapus role admin"); err != nil {
+			return err
+		}
+		if err := ensureOtherAdminRole(s.Repo, id); err != nil {
+			return err
+		}
+	}
+
+	err = s.Repo.DeleteByID(id, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundf("role dengan id %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	InvalidateAllPermissions()
+	return nil
+}
+
+// InheritedPermissions memetakan id permission ke nama parent (dari parentIDs) yang mewariskannya,
+// termasuk permission yang parent itu warisi dari leluhurnya. Dipakai form role untuk memisahkan
+// permission warisan dari permission milik role sendiri.
+func (s *RoleService) InheritedPermissions(parentIDs []int) (map[int64][]string, error) {
+	result := make(map[int64][]string)
+	parentIDs = uniqueInts(parentIDs)
+	if len(parentIDs) == 0 {
+		return result, nil
+	}
+
+	parents, err := s.Repo.GetParentMap()
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.Repo.GetNames()
+	if err != nil {
+		return nil, err
+	}
+	permsByRole, err := s.Repo.GetPermissionIDsByRoles(expandRoleIDs(parents, parentIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(parentIDs, func(i, j int) bool { return names[parentIDs[i]] < names[parentIDs[j]] })
+	for _, parentID := range parentIDs {
+		for _, permID := range rolePermissionClosure(parents, permsByRole, parentID) {
+			result[permID] = append(result[permID], names[parentID])
+		}
+	}
+	return result, nil
+}
+
+// ParentOptions mengembalikan role yang bisa dipilih sebagai parent roleID beserta permission yang
+// akan diwariskannya. Role itu sendiri dan role admin (tidak bisa diwarisi) tidak ikut, dan
+// turunannya ditandai Disabled karena memilihnya akan membentuk siklus. roleID 0 untuk role baru.
+func (s *RoleService) ParentOptions(roleID int) ([]models.RoleOption, error) {
+	roles, err := s.Repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	parents, err := s.Repo.GetParentMap()
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]int, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	permsByRole, err := s.Repo.GetPermissionIDsByRoles(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]models.RoleOption, 0, len(roles))
+	for _, role := range roles {
+		if role.ID == roleID || role.IsAdmin {
+			continue
+		}
+
+		disabled := false
+		if roleID > 0 {
+			for _, ancestorID := range roleAncestors(parents, role.ID) {
+				if ancestorID == roleID {
+					disabled = true
+					break
+				}
+			}
+		}
+		options = append(options, models.RoleOption{
+			ID:            role.ID,
+			Name:          role.Name,
+			Disabled:      disabled,
+			PermissionIDs: rolePermissionClosure(parents, permsByRole, role.ID),
+		})
+	}
+	return options, nil
+}
+
+// rolePermissionClosure mengembalikan permission milik roleID ditambah permission seluruh leluhurnya.
+// permsByRole harus sudah memuat roleID dan leluhurnya.
+func rolePermissionClosure(parents map[int][]int, permsByRole map[int][]int64, roleID int) []int64 {
+	var permIDs []int64
+	for _, id := range append([]int{roleID}, roleAncestors(parents, roleID)...) {
+		permIDs = append(permIDs, permsByRole[id]...)
 	}
-	return s.Repo.DeleteByID(id)
+	return uniqueInt64(permIDs)
 }
 
 func uniqueInt64(values []int64) []int64 {
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/oidc"
+	"gobase-app/repositories"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSSOUserNotFound dikembalikan jika akun SSO tidak cocok dengan user mana pun dan
+// pembuatan user otomatis tidak diaktifkan.
+var ErrSSOUserNotFound = errors.New("akun SSO Anda belum terdaftar di aplikasi, silakan hubungi administrator")
+
+// SSOSettings berisi aturan pencocokan user SSO yang dibaca dari environment:
+//   - OIDC_AUTO_PROVISION (default false) – buat user baru jika tidak ada yang cocok
+//   - OIDC_REQUIRE_VERIFIED_EMAIL (default true) – email hanya dipakai jika email_verified bernilai true
+//   - OIDC_NIP_CLAIM (default "nip") dan OIDC_GROUPS_CLAIM (default "groups")
+//   - OIDC_GROUP_ROLES – pemetaan group ke role, mis. "it-admin=admin,gudang=staff"
+//   - OIDC_DEFAULT_STORE_IDS – store untuk user yang dibuat otomatis, mis. "1,2"
+type SSOSettings struct {
+	AutoProvision        bool
+	RequireVerifiedEmail bool
+	NIPClaim             string
+	GroupsClaim          string
+	GroupRoles           map[string][]string
+	DefaultStoreIDs      []int
+}
+
+// LoadSSOSettings membaca aturan SSO dari environment.
+func LoadSSOSettings() SSOSettings {
+	settings := SSOSettings{
+		AutoProvision:        config.EnvBool("OIDC_AUTO_PROVISION"),
+		RequireVerifiedEmail: config.EnvBoolDefault("OIDC_REQUIRE_VERIFIED_EMAIL", true),
+		NIPClaim:             strings.TrimSpace(os.Getenv("OIDC_NIP_CLAIM")),
+		GroupsClaim:          strings.TrimSpace(os.Getenv("OIDC_GROUPS_CLAIM")),
+		GroupRoles:           parseGroupRoles("OIDC_GROUP_ROLES"),
+	}
+	if settings.NIPClaim == "" {
+		settings.NIPClaim = "nip"
+	}
+	if settings.GroupsClaim == "" {
+		settings.GroupsClaim = "groups"
+	}
+
+	for _, item := range config.EnvList("OIDC_DEFAULT_STORE_IDS") {
+		if id, err := strconv.Atoi(item); err == nil && id > 0 {
+			settings.DefaultStoreIDs = append(settings.DefaultStoreIDs, id)
+		}
+	}
+
+	return settings
+}
+
+// SSOService mencocokkan identitas dari identity provider OIDC dengan user lokal.
+type SSOService struct {
+	Users      *UserService
+	Identities *repositories.UserIdentityRepository
+	Settings   SSOSettings
+}
+
+// ResolveUser mencari user untuk claims ID token: identitas yang sudah terhubung, lalu email
+// terverifikasi, lalu NIP. Jika tidak ada yang cocok dan OIDC_AUTO_PROVISION aktif, user baru
+// dibuat. Role user disinkronkan dengan group SSO jika OIDC_GROUP_ROLES diisi.
+func (s *SSOService) ResolveUser(claims *oidc.Claims, actor models.AuditActor) (int, error) {
+	userID, err := s.Identities.FindUserID(claims.Issuer, claims.Subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		userID, err = s.matchUser(claims)
+		if errors.Is(err, sql.ErrNoRows) && s.Settings.AutoProvision {
+			userID, err = s.provisionUser(claims, actor)
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrSSOUserNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.Identities.Link(userID, claims.Issuer, claims.Subject, claims.Email, time.Now()); err != nil {
+		return 0, err
+	}
+
+	if err := syncGroupRoles(s.Users.Repo, userID, s.Settings.GroupRoles, claims.Strings(s.Settings.GroupsClaim), actor); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// matchUser mencocokkan user berdasarkan email lalu NIP; sql.ErrNoRows jika tidak ada yang cocok.
+func (s *SSOService) matchUser(claims *oidc.Claims) (int, error) {
+	if email := s.trustedEmail(claims); email != "" {
+		userID, err := s.Users.Repo.FindIDByEmail(email)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return userID, err
+		}
+	}
+
+	if nip := s.nip(claims); nip > 0 {
+		return s.Users.Repo.FindIDByNIP(nip)
+	}
+
+	return 0, sql.ErrNoRows
+}
+
+// provisionUser membuat user aktif dari claims memakai validasi yang sama dengan form admin.
+func (s *SSOService) provisionUser(claims *oidc.Claims, actor models.AuditActor) (int, error) {
+	email := s.trustedEmail(claims)
+	if email == "" {
+		return 0, invalidf("identity provider tidak mengirim email terverifikasi, user baru tidak bisa dibuat")
+	}
+
+	nip := s.nip(claims)
+	if nip <= 0 {
+		return 0, invalidf("claim %s dari identity provider wajib diisi untuk membuat user baru", s.Settings.NIPClaim)
+	}
+
+	if len(s.Settings.DefaultStoreIDs) == 0 {
+		return 0, invalidf("OIDC_DEFAULT_STORE_IDS belum diatur, user baru tidak bisa dibuat")
+	}
+
+	username := claims.PreferredUsername
+	if username == "" {
+		username, _, _ = strings.Cut(email, "@")
+	}
+	name := claims.Name
+	if name == "" {
+		name = username
+	}
+
+	roleIDs, err := s.Users.Repo.GetRoleIDsByNames(mappedRoles(s.Settings.GroupRoles, claims.Strings(s.Settings.GroupsClaim)))
+	if err != nil {
+		return 0, err
+	}
+	roleNames := make([]string, 0, len(roleIDs))
+	for roleName := range roleIDs {
+		roleNames = append(roleNames, roleName)
+	}
+	sort.Strings(roleNames)
+
+	// password acak hanya untuk memenuhi kolom wajib; user tetap bisa mengatur password
+	// sendiri lewat lupa password jika suatu saat perlu login tanpa SSO.
+	password, err := generateResetToken()
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Users.createUser(models.UserCreateInput{
+		NIP:       nip,
+		Username:  username,
+		Password:  password + "Aa1!",
+		Name:      name,
+		Email:     email,
+		Status:    "active",
+		StoreIDs:  s.Settings.DefaultStoreIDs,
+		RoleNames: roleNames,
+	}, actor)
+}
+
+func (s *SSOService) trustedEmail(claims *oidc.Claims) string {
+	if claims.Email == "" || (s.Settings.RequireVerifiedEmail && !claims.EmailVerified) {
+		return ""
+	}
+	return claims.Email
+}
+
+func (s *SSOService) nip(claims *oidc.Claims) int {
+	nip, err := strconv.Atoi(claims.String(s.Settings.NIPClaim))
+	if err != nil {
+		return 0
+	}
+	return nip
+}
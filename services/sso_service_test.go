@@ -0,0 +1,135 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gobase-app/models"
+	"gobase-app/oidc"
+	"gobase-app/repositories"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSSOServiceResolveUserMatchesVerifiedEmailOnly(t *testing.T) {
+	const issuer = "https://sso.kampus.local"
+
+	expectNoIdentity := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT user_id FROM user_identities`).
+			WithArgs(issuer, "sub-1").
+			WillReturnError(sql.ErrNoRows)
+	}
+	expectLink := func(mock sqlmock.Sqlmock, userID int) {
+		mock.ExpectExec(`INSERT INTO user_identities`).
+			WithArgs(userID, issuer, "sub-1", "alice@kampus.local", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	tests := []struct {
+		name          string
+		emailVerified bool
+		requireVerify bool
+		raw           map[string]interface{}
+		setup         func(sqlmock.Sqlmock)
+		wantID        int
+		wantErr       error
+	}{
+		{
+			name:          "email terverifikasi dicocokkan",
+			emailVerified: true,
+			requireVerify: true,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectNoIdentity(mock)
+				mock.ExpectQuery(`SELECT id FROM users WHERE email = \?`).
+					WithArgs("alice@kampus.local").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				expectLink(mock, 5)
+			},
+			wantID: 5,
+		},
+		{
+			name:          "email belum terverifikasi tidak dicocokkan",
+			requireVerify: true,
+			setup:         expectNoIdentity,
+			wantErr:       ErrSSOUserNotFound,
+		},
+		{
+			name:          "email belum terverifikasi, NIP tetap dicocokkan",
+			requireVerify: true,
+			raw:           map[string]interface{}{"nip": "250192"},
+			setup: func(mock sqlmock.Sqlmock) {
+				expectNoIdentity(mock)
+				mock.ExpectQuery(`SELECT id FROM users WHERE nip = \?`).
+					WithArgs(250192).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+				expectLink(mock, 6)
+			},
+			wantID: 6,
+		},
+		{
+			name: "OIDC_REQUIRE_VERIFIED_EMAIL=false memakai email apa adanya",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectNoIdentity(mock)
+				mock.ExpectQuery(`SELECT id FROM users WHERE email = \?`).
+					WithArgs("alice@kampus.local").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				expectLink(mock, 5)
+			},
+			wantID: 5,
+		},
+		{
+			name:          "identitas yang sudah terhubung tidak dicocokkan ulang",
+			requireVerify: true,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT user_id FROM user_identities`).
+					WithArgs(issuer, "sub-1").
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(9))
+				expectLink(mock, 9)
+			},
+			wantID: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			tt.setup(mock)
+
+			raw := map[string]interface{}{}
+			for key, val := range tt.raw {
+				raw[key] = val
+			}
+			claims := &oidc.Claims{
+				Issuer:        issuer,
+				Subject:       "sub-1",
+				Email:         "alice@kampus.local",
+				EmailVerified: tt.emailVerified,
+				Raw:           raw,
+			}
+			svc := &SSOService{
+				Users:      &UserService{Repo: &repositories.UserRepository{DB: db}},
+				Identities: &repositories.UserIdentityRepository{DB: db},
+				Settings:   SSOSettings{RequireVerifiedEmail: tt.requireVerify, NIPClaim: "nip", GroupsClaim: "groups"},
+			}
+
+			userID, err := svc.ResolveUser(claims, models.AuditActor{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if userID != tt.wantID {
+				t.Errorf("userID = %d, want %d", userID, tt.wantID)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
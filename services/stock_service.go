@@ -0,0 +1,203 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// StockPerPage adalah jumlah baris per halaman di halaman saldo dan ledger stok.
+const StockPerPage = 25
+
+// stockMaxQuantity membatasi jumlah per mutasi agar salah ketik (mis. kelebihan nol) tidak lolos.
+const stockMaxQuantity = 1000000000
+
+// StockMovementTypes memetakan jenis mutasi ke label yang ditampilkan.
+var StockMovementTypes = map[string]string{
+	models.StockReceipt:    "Penerimaan",
+	models.StockIssue:      "Pengeluaran",
+	models.StockAdjustment: "Penyesuaian",
+	models.StockTransfer:   "Transfer",
+}
+
+type StockService struct {
+	Repo   *repositories.StockRepository
+	Items  *repositories.ItemRepository
+	Stores *repositories.StoreRepository
+}
+
+// SearchBalances mengambil satu halaman saldo stok di store dalam scope.
+func (s *StockService) SearchBalances(scope models.StoreFilter, filter models.StockBalanceFilter) ([]models.StockBalance, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	filter.PerPage = StockPerPage
+	return s.Repo.SearchBalances(scope, filter)
+}
+
+// SearchMovements mengambil satu halaman ledger stok di store dalam scope.
+func (s *StockService) SearchMovements(scope models.StoreFilter, filter models.StockMovementFilter) ([]models.StockMovement, int, error) {
+	filter.Type = strings.TrimSpace(filter.Type)
+	if _, ok := StockMovementTypes[filter.Type]; filter.Type != "" && !ok {
+		return nil, 0, invalidf("jenis mutasi tidak valid")
+	}
+	for _, date := range []string{filter.DateFrom, filter.DateTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, 0, invalidf("format tanggal tidak valid, gunakan YYYY-MM-DD")
+		}
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	filter.PerPage = StockPerPage
+	return s.Repo.SearchMovements(scope, filter)
+}
+
+// GetStores mengambil store dalam scope untuk pilihan filter dan form mutasi.
+func (s *StockService) GetStores(scope models.StoreFilter) ([]models.Store, error) {
+	return s.Stores.GetFiltered(scope)
+}
+
+// GetTransferTargets mengambil store aktif yang bisa menjadi tujuan transfer. Tujuan tidak harus
+// store milik user; yang dibatasi hanya store asal.
+func (s *StockService) GetTransferTargets() ([]models.Store, error) {
+	return s.Stores.GetActive()
+}
+
+// GetItems mengambil seluruh item untuk pilihan item di form mutasi. Item nonaktif hanya
+// ditawarkan untuk pengeluaran dan penyesuaian.
+func (s *StockService) GetItems() ([]models.Item, error) {
+	items, _, err := s.Items.Search(models.ItemFilter{})
+	return items, err
+}
+
+// GetCategories mengambil kategori item untuk filter saldo.
+func (s *StockService) GetCategories() ([]string, error) {
+	return s.Items.GetCategories()
+}
+
+// RecordMovement memvalidasi lalu mencatat satu mutasi stok. Hak akses ke store asal dicek di
+// route (middleware.RequireStorePermission); di sini hanya aturan data: jumlah, item, store, dan
+// saldo yang tidak boleh negatif.
+func (s *StockService) RecordMovement(input models.StockMovementInput, actor models.AuditActor) error {
+	input.Type = strings.TrimSpace(input.Type)
+	input.Reason = strings.TrimSpace(input.Reason)
+	input.Reference = strings.TrimSpace(input.Reference)
+
+	if _, ok := StockMovementTypes[input.Type]; !ok {
+		return invalidf("jenis mutasi tidak valid")
+	}
+	if input.Type == models.StockAdjustment {
+		if input.Quantity < 0 {
+			return invalidf("jumlah hasil hitung tidak boleh negatif")
+		}
+	} else if input.Quantity <= 0 {
+		return invalidf("jumlah harus lebih dari 0")
+	}
+	if input.Quantity > stockMaxQuantity {
+		return invalidf("jumlah maksimal %d", stockMaxQuantity)
+	}
+	if input.Reason == "" {
+		return invalidf("alasan wajib diisi")
+	}
+	if utf8.RuneCountInString(input.Reason) > 255 {
+		return invalidf("alasan maksimal 255 karakter")
+	}
+	if utf8.RuneCountInString(input.Reference) > 100 {
+		return invalidf("referensi maksimal 100 karakter")
+	}
+
+	store, err := s.stockStore(input.StoreID)
+	if err != nil {
+		return err
+	}
+	if input.ItemID <= 0 {
+		return invalidf("item tidak valid")
+	}
+	item, err := s.Items.GetByID(input.ItemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundf("item dengan id %d tidak ditemukan", input.ItemID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// stok item/store nonaktif masih boleh dikeluarkan dan disesuaikan agar bisa dihabiskan,
+	// tetapi tidak boleh bertambah
+	if input.Type == models.StockReceipt || input.Type == models.StockTransfer {
+		if !item.IsActive {
+			return invalidf("item %s sudah nonaktif", item.Name)
+		}
+	}
+	if input.Type == models.StockReceipt && !store.IsActive {
+		return invalidf("store %s sudah nonaktif", store.StoreName)
+	}
+
+	stores := map[int]*models.Store{store.StoreID: store}
+	switch input.Type {
+	case models.StockReceipt:
+		err = s.Repo.Receive(input, actor)
+	case models.StockIssue:
+		err = s.Repo.Issue(input, actor)
+	case models.StockAdjustment:
+		err = s.Repo.Adjust(input, actor)
+	case models.StockTransfer:
+		if input.ToStoreID == input.StoreID {
+			return invalidf("store tujuan harus berbeda dengan store asal")
+		}
+		target, terr := s.stockStore(input.ToStoreID)
+		if terr != nil {
+			return terr
+		}
+		if !target.IsActive {
+			return invalidf("store tujuan %s sudah nonaktif", target.StoreName)
+		}
+		stores[target.StoreID] = target
+
+		ref, terr := generateTransferRef()
+		if terr != nil {
+			return terr
+		}
+		err = s.Repo.Transfer(input, ref, actor)
+	}
+
+	var insufficient *repositories.InsufficientStockError
+	if errors.As(err, &insufficient) {
+		storeName := ""
+		if st := stores[insufficient.StoreID]; st != nil {
+			storeName = st.StoreName
+		}
+		return conflictf("stok %s di %s tidak cukup: tersedia %d %s, diminta %d %s",
+			item.Name, storeName, insufficient.Available, item.Unit, insufficient.Requested, item.Unit)
+	}
+	return err
+}
+
+func (s *StockService) stockStore(id int) (*models.Store, error) {
+	if id <= 0 {
+		return nil, invalidf("store tidak valid")
+	}
+	store, err := s.Stores.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundf("store dengan id %d tidak ditemukan", id)
+	}
+	return store, err
+}
+
+// generateTransferRef membuat id acak yang menautkan baris keluar dan masuk satu transfer.
+func generateTransferRef() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"gobase-app/config"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"sort"
+)
+
+// StoreScope adalah store yang boleh diakses user pada satu request beserta permission yang hanya
+// berlaku di store tertentu. Permission global berlaku di semua store milik user; permission per
+// store hanya di store tersebut, dan keduanya tidak pernah berlaku di store yang bukan milik user.
+type StoreScope struct {
+	All         bool             // user admin: seluruh store tanpa batasan
+	StoreIDs    []int            // store yang ditugaskan ke user
+	Permissions map[string]bool  // permission global user
+	StoreGrants map[string][]int // nama permission -> store tempat permission itu berlaku
+}
+
+// ResolveStoreScope menyusun StoreScope user dari store yang ditugaskan dan permission per store-nya.
+// perms adalah permission global yang sudah dihitung (mis. sudah dibatasi ability token).
+func ResolveStoreScope(userID int, perms map[string]bool) (*StoreScope, error) {
+	scope := &StoreScope{Permissions: perms, StoreGrants: map[string][]int{}}
+	if userID <= 0 {
+		return scope, nil
+	}
+
+	isAdmin, err := IsAdminUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		scope.All = true
+		return scope, nil
+	}
+
+	storeIDs, err := (&repositories.UserRepository{DB: config.DB}).GetStoreIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	scope.StoreIDs = uniqueInts(storeIDs)
+	sort.Ints(scope.StoreIDs)
+
+	grants, err := (&repositories.PermissionRepository{DB: config.DB}).GetStorePermissionGrants(userID)
+	if err != nil {
+		return nil, err
+	}
+	for name, ids := range grants {
+		for _, id := range ids {
+			// grant untuk store yang sudah tidak ditugaskan ke user diabaikan
+			if scope.Allows(id) {
+				scope.StoreGrants[name] = append(scope.StoreGrants[name], id)
+			}
+		}
+	}
+	return scope, nil
+}
+
+// Allows mengecek apakah storeID termasuk store user.
+func (s *StoreScope) Allows(storeID int) bool {
+	if s.All {
+		return true
+	}
+	for _, id := range s.StoreIDs {
+		if id == storeID {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAny mengecek apakah minimal satu dari storeIDs termasuk store user.
+func (s *StoreScope) AllowsAny(storeIDs []int) bool {
+	if s.All {
+		return true
+	}
+	for _, id := range storeIDs {
+		if s.Allows(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Can mengecek permission perm di store storeID: permission global atau permission khusus store itu.
+// Admin boleh di semua store, tetapi permission-nya tetap dicek agar ability token berlaku.
+func (s *StoreScope) Can(perm string, storeID int) bool {
+	if s.All {
+		return s.Permissions[perm]
+	}
+	if !s.Allows(storeID) {
+		return false
+	}
+	if s.Permissions[perm] {
+		return true
+	}
+	for _, id := range s.StoreGrants[perm] {
+		if id == storeID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAnyStore mengecek apakah perm dimiliki secara global atau minimal di satu store user. Admin
+// sudah memiliki seluruh permission global, jadi All tidak dicek agar ability token tetap berlaku.
+func (s *StoreScope) CanAnyStore(perm string) bool {
+	return s.Permissions[perm] || len(s.StoreGrants[perm]) > 0
+}
+
+// Filter membatasi query ke seluruh store user.
+func (s *StoreScope) Filter() models.StoreFilter {
+	return models.StoreFilter{All: s.All, StoreIDs: s.StoreIDs}
+}
+
+// FilterFor membatasi query ke store tempat user memiliki permission perm.
+func (s *StoreScope) FilterFor(perm string) models.StoreFilter {
+	if s.All {
+		if s.Permissions[perm] {
+			return models.StoreFilter{All: true}
+		}
+		return models.StoreFilter{StoreIDs: []int{}}
+	}
+
+	storeIDs := []int{}
+	for _, id := range s.StoreIDs {
+		if s.Can(perm, id) {
+			storeIDs = append(storeIDs, id)
+		}
+	}
+	return models.StoreFilter{StoreIDs: storeIDs}
+}
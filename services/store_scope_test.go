@@ -0,0 +1,73 @@
+package services
+
+import (
+	"gobase-app/models"
+	"reflect"
+	"testing"
+)
+
+func TestStoreScopeCan(t *testing.T) {
+	scope := &StoreScope{
+		StoreIDs:    []int{1, 2},
+		Permissions: map[string]bool{"stock.view": true},
+		// grant di store 3 tidak pernah berlaku karena store 3 bukan milik user
+		StoreGrants: map[string][]int{"stock.issue": {2, 3}},
+	}
+	admin := &StoreScope{All: true, Permissions: map[string]bool{"stock.view": true, "stock.adjust": true}}
+	// admin lewat token yang ability-nya hanya stock.view
+	adminToken := &StoreScope{All: true, Permissions: map[string]bool{"stock.view": true}}
+
+	tests := []struct {
+		name    string
+		scope   *StoreScope
+		perm    string
+		storeID int
+		want    bool
+	}{
+		{name: "permission global di store milik user", scope: scope, perm: "stock.view", storeID: 1, want: true},
+		{name: "permission global di store lain", scope: scope, perm: "stock.view", storeID: 3, want: false},
+		{name: "permission per store di store tersebut", scope: scope, perm: "stock.issue", storeID: 2, want: true},
+		{name: "permission per store di store milik user yang lain", scope: scope, perm: "stock.issue", storeID: 1, want: false},
+		{name: "permission per store di store yang bukan milik user", scope: scope, perm: "stock.issue", storeID: 3, want: false},
+		{name: "permission yang tidak dimiliki", scope: scope, perm: "stock.adjust", storeID: 1, want: false},
+		{name: "admin di store mana pun", scope: admin, perm: "stock.adjust", storeID: 9, want: true},
+		{name: "admin lewat token dibatasi ability", scope: adminToken, perm: "stock.adjust", storeID: 9, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.Can(tt.perm, tt.storeID); got != tt.want {
+				t.Errorf("Can(%s, %d) = %v, want %v", tt.perm, tt.storeID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreScopeFilterFor(t *testing.T) {
+	scope := &StoreScope{
+		StoreIDs:    []int{1, 2, 4},
+		Permissions: map[string]bool{"stock.view": true},
+		StoreGrants: map[string][]int{"stock.issue": {2, 3}, "stock.adjust": {4}},
+	}
+
+	tests := []struct {
+		name  string
+		scope *StoreScope
+		perm  string
+		want  models.StoreFilter
+	}{
+		{name: "permission global memakai seluruh store user", scope: scope, perm: "stock.view", want: models.StoreFilter{StoreIDs: []int{1, 2, 4}}},
+		{name: "permission per store hanya store miliknya", scope: scope, perm: "stock.issue", want: models.StoreFilter{StoreIDs: []int{2}}},
+		{name: "permission yang tidak dimiliki tidak membuka store apa pun", scope: scope, perm: "items.delete", want: models.StoreFilter{StoreIDs: []int{}}},
+		{name: "admin tanpa batasan", scope: &StoreScope{All: true, Permissions: map[string]bool{"stock.issue": true}}, perm: "stock.issue", want: models.StoreFilter{All: true}},
+		{name: "admin lewat token tanpa ability", scope: &StoreScope{All: true, Permissions: map[string]bool{}}, perm: "stock.issue", want: models.StoreFilter{StoreIDs: []int{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.FilterFor(tt.perm); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterFor(%s) = %+v, want %+v", tt.perm, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+type StoreService struct {
+	Repo *repositories.StoreRepository
+}
+
+// storeCodePattern membatasi kode store agar konsisten dengan kode yang sudah ada (mis. "MK1", "MKM2").
+var storeCodePattern = regexp.MustCompile(`^[A-Z0-9_-]+$`)
+
+// SearchStores mengambil store dalam filter (store milik user yang login) sesuai pencarian.
+func (s *StoreService) SearchStores(filter models.StoreFilter, search models.StoreSearch) ([]models.Store, error) {
+	return s.Repo.Search(filter, search)
+}
+
+// GetStore mengambil store id yang termasuk filter. Store di luar filter dianggap tidak ada.
+func (s *StoreService) GetStore(filter models.StoreFilter, id int) (*models.Store, error) {
+	if id <= 0 {
+		return nil, invalidf("store tidak valid")
+	}
+	if !storeInFilter(filter, id) {
+		return nil, notFoundf("store dengan id %d tidak ditemukan", id)
+	}
+
+	store, err := s.Repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundf("store dengan id %d tidak ditemukan", id)
+	}
+	return store, err
+}
+
+// CreateStore memvalidasi lalu menyimpan store baru dalam kondisi aktif.
+func (s *StoreService) CreateStore(input models.StoreInput, actor models.AuditActor) (int64, error) {
+	input.ID = 0
+	input, err := s.validateStore(input)
+	if err != nil {
+		return 0, err
+	}
+	return s.Repo.Create(input, actor)
+}
+
+// UpdateStore memvalidasi lalu mengubah kode, nama, dan alamat store dalam filter.
+func (s *StoreService) UpdateStore(filter models.StoreFilter, input models.StoreInput, actor models.AuditActor) error {
+	if _, err := s.GetStore(filter, input.ID); err != nil {
+		return err
+	}
+
+	input, err := s.validateStore(input)
+	if err != nil {
+		return err
+	}
+	return s.Repo.Update(input, actor)
+}
+
+// SetStoreActive mengaktifkan atau menonaktifkan store dalam filter. Store nonaktif disembunyikan
+// dari pilihan store di form user, tetapi user yang sudah ditugaskan ke store itu tidak diubah.
+func (s *StoreService) SetStoreActive(filter models.StoreFilter, id int, active bool, actor models.AuditActor) error {
+	store, err := s.GetStore(filter, id)
+	if err != nil {
+		return err
+	}
+	if store.IsActive == active {
+		if active {
+			return invalidf("store %s sudah aktif", store.StoreName)
+		}
+		return invalidf("store %s sudah nonaktif", store.StoreName)
+	}
+	return s.Repo.SetActive(id, active, actor)
+}
+
+func (s *StoreService) validateStore(input models.StoreInput) (models.StoreInput, error) {
+	input.Code = strings.ToUpper(strings.TrimSpace(input.Code))
+	input.Name = strings.TrimSpace(input.Name)
+	input.Address = strings.TrimSpace(input.Address)
+
+	if input.Code == "" {
+		return input, invalidf("kode store wajib diisi")
+	}
+	if len(input.Code) > 50 || !storeCodePattern.MatchString(input.Code) {
+		return input, invalidf("kode store hanya boleh huruf, angka, tanda minus, dan garis bawah (maksimal 50 karakter)")
+	}
+	if input.Name == "" {
+		return input, invalidf("nama store wajib diisi")
+	}
+	if utf8.RuneCountInString(input.Name) > 255 {
+		return input, invalidf("nama store maksimal 255 karakter")
+	}
+	if input.Address == "" {
+		return input, invalidf("alamat store wajib diisi")
+	}
+
+	exists, err := s.Repo.ExistsByCodeExceptID(input.Code, input.ID)
+	if err != nil {
+		return input, err
+	}
+	if exists {
+		return input, conflictf("kode store %s sudah digunakan", input.Code)
+	}
+	return input, nil
+}
+
+func storeInFilter(filter models.StoreFilter, id int) bool {
+	if filter.All {
+		return true
+	}
+	for _, storeID := range filter.StoreIDs {
+		if storeID == id {
+			return true
+		}
+	}
+	return false
+}
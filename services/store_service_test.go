@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestStoreValidateStore(t *testing.T) {
+	valid := models.StoreInput{ID: 4, Code: " mk-2 ", Name: " Mini Market 2 ", Address: " Jl. Kampus 2 "}
+	with := func(change func(*models.StoreInput)) models.StoreInput {
+		input := valid
+		change(&input)
+		return input
+	}
+
+	tests := []struct {
+		name      string
+		input     models.StoreInput
+		codeTaken bool
+		wantErr   error
+		wantInput models.StoreInput
+	}{
+		{
+			name:      "valid dan dirapikan",
+			input:     valid,
+			wantInput: models.StoreInput{ID: 4, Code: "MK-2", Name: "Mini Market 2", Address: "Jl. Kampus 2"},
+		},
+		{name: "kode kosong", input: with(func(i *models.StoreInput) { i.Code = " " }), wantErr: ErrInvalidInput},
+		{name: "kode berisi spasi", input: with(func(i *models.StoreInput) { i.Code = "MK 2" }), wantErr: ErrInvalidInput},
+		{name: "kode berisi titik", input: with(func(i *models.StoreInput) { i.Code = "MK.2" }), wantErr: ErrInvalidInput},
+		{name: "kode terlalu panjang", input: with(func(i *models.StoreInput) { i.Code = strings.Repeat("M", 51) }), wantErr: ErrInvalidInput},
+		{name: "nama kosong", input: with(func(i *models.StoreInput) { i.Name = "" }), wantErr: ErrInvalidInput},
+		{name: "nama terlalu panjang", input: with(func(i *models.StoreInput) { i.Name = strings.Repeat("é", 256) }), wantErr: ErrInvalidInput},
+		{name: "alamat kosong", input: with(func(i *models.StoreInput) { i.Address = "  " }), wantErr: ErrInvalidInput},
+		{name: "kode dipakai store lain", input: valid, codeTaken: true, wantErr: ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if tt.wantErr == nil || tt.codeTaken {
+				count := 0
+				if tt.codeTaken {
+					count = 1
+				}
+				mock.ExpectQuery(`SELECT COUNT\(1\) FROM stores WHERE store_code = \? AND store_id <> \?`).WithArgs("MK-2", 4).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+			}
+
+			svc := &StoreService{Repo: &repositories.StoreRepository{DB: db}}
+			got, err := svc.validateStore(tt.input)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && err != nil:
+				t.Fatal(err)
+			case tt.wantErr == nil && got != tt.wantInput:
+				t.Errorf("input = %+v, want %+v", got, tt.wantInput)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// expectStoreByID mengharapkan store id dibaca sebelum diubah.
+func expectStoreByID(mock sqlmock.Sqlmock, id int, active bool) {
+	mock.ExpectQuery(`FROM stores\s+WHERE store_id IN \(\?\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id", "store_code", "store_name", "store_address", "is_active"}).
+			AddRow(id, "MK2", "Mini Market 2", "Jl. Kampus 2", active))
+}
+
+// expectStoreSnapshot mengharapkan snapshot audit store id di dalam transaksi.
+func expectStoreSnapshot(mock sqlmock.Sqlmock, id int64, name string, active bool) {
+	mock.ExpectQuery(`SELECT store_code, store_name, store_address, is_active\s+FROM stores`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"store_code", "store_name", "store_address", "is_active"}).
+			AddRow("MK2", name, "Jl. Kampus 2", active))
+}
+
+func TestStoreServiceScope(t *testing.T) {
+	actor := models.AuditActor{UserID: 5, Username: "alice"}
+	ownStores := models.StoreFilter{StoreIDs: []int{1, 2}}
+	update := models.StoreInput{ID: 2, Code: "MK2", Name: "Mini Market Dua", Address: "Jl. Kampus 2"}
+
+	tests := []struct {
+		name    string
+		setup   func(sqlmock.Sqlmock)
+		run     func(*StoreService) error
+		wantErr error
+	}{
+		{
+			name:    "id tidak valid",
+			run:     func(s *StoreService) error { _, err := s.GetStore(models.StoreFilter{All: true}, 0); return err },
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "baca store di luar filter",
+			run:     func(s *StoreService) error { _, err := s.GetStore(ownStores, 3); return err },
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "ubah store di luar filter",
+			run: func(s *StoreService) error {
+				return s.UpdateStore(ownStores, models.StoreInput{ID: 3, Code: "MK3", Name: "MK3", Address: "x"}, actor)
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "nonaktifkan store di luar filter",
+			run:     func(s *StoreService) error { return s.SetStoreActive(ownStores, 3, false, actor) },
+			wantErr: ErrNotFound,
+		},
+		{
+			name:  "tanpa batasan filter bisa membaca store mana pun",
+			setup: func(mock sqlmock.Sqlmock) { expectStoreByID(mock, 3, true) },
+			run:   func(s *StoreService) error { _, err := s.GetStore(models.StoreFilter{All: true}, 3); return err },
+		},
+		{
+			name: "store dalam filter sudah tidak ada",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM stores\s+WHERE store_id IN \(\?\)`).WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"store_id", "store_code", "store_name", "store_address", "is_active"}))
+			},
+			run:     func(s *StoreService) error { _, err := s.GetStore(ownStores, 2); return err },
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "nonaktifkan store yang sudah nonaktif",
+			setup:   func(mock sqlmock.Sqlmock) { expectStoreByID(mock, 2, false) },
+			run:     func(s *StoreService) error { return s.SetStoreActive(ownStores, 2, false, actor) },
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name: "nonaktifkan store dalam filter",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectStoreByID(mock, 2, true)
+				mock.ExpectBegin()
+				expectStoreSnapshot(mock, 2, "Mini Market 2", true)
+				mock.ExpectExec(`UPDATE stores SET is_active = \? WHERE store_id = \?`).WithArgs(false, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectStoreSnapshot(mock, 2, "Mini Market 2", false)
+				mock.ExpectExec(`INSERT INTO audit_logs`).
+					WithArgs(5, "alice", "store.deactivate", "store", int64(2), `{"is_active":true}`, `{"is_active":false}`, "", "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			run: func(s *StoreService) error { return s.SetStoreActive(ownStores, 2, false, actor) },
+		},
+		{
+			name: "ubah store dalam filter",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectStoreByID(mock, 2, true)
+				mock.ExpectQuery(`SELECT COUNT\(1\) FROM stores WHERE store_code = \?`).WithArgs("MK2", 2).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectBegin()
+				expectStoreSnapshot(mock, 2, "Mini Market 2", true)
+				mock.ExpectExec(`UPDATE stores SET store_code = \?, store_name = \?, store_address = \? WHERE store_id = \?`).
+					WithArgs("MK2", "Mini Market Dua", "Jl. Kampus 2", 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectStoreSnapshot(mock, 2, "Mini Market Dua", true)
+				mock.ExpectExec(`INSERT INTO audit_logs`).
+					WithArgs(5, "alice", "store.update", "store", int64(2), `{"store_name":"Mini Market 2"}`, `{"store_name":"Mini Market Dua"}`, "", "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			run: func(s *StoreService) error { return s.UpdateStore(ownStores, update, actor) },
+		},
+		{
+			// ID dari input diabaikan sehingga kode dicek terhadap seluruh store
+			name: "buat store baru",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT\(1\) FROM stores WHERE store_code = \?`).WithArgs("MK3", 0).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO stores \(store_code, store_name, store_address, is_active\)`).
+					WithArgs("MK3", "Mini Market 3", "Jl. Kampus 3").
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				expectStoreSnapshot(mock, 3, "Mini Market 3", true)
+				mock.ExpectExec(`INSERT INTO audit_logs`).
+					WithArgs(5, "alice", "store.create", "store", int64(3), nil, sqlmock.AnyArg(), "", "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			run: func(s *StoreService) error {
+				_, err := s.CreateStore(models.StoreInput{ID: 2, Code: "mk3", Name: "Mini Market 3", Address: "Jl. Kampus 3"}, actor)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+
+			err = tt.run(&StoreService{Repo: &repositories.StoreRepository{DB: db}})
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && err != nil:
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
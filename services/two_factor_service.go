@@ -0,0 +1,200 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	helpers "gobase-app/helper"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	recoveryCodeCount = 8
+	// toleransi satu time-step (±30 detik) untuk selisih jam perangkat.
+	totpSkew = 1
+)
+
+type TwoFactorService struct {
+	Repo *repositories.TwoFactorRepository
+}
+
+// GetState mengambil status 2FA user.
+func (s *TwoFactorService) GetState(userID int) (*models.TwoFactorState, error) {
+	return s.Repo.GetState(userID)
+}
+
+// IsRequired mengecek apakah role user, termasuk role yang diwarisinya, mewajibkan 2FA.
+func (s *TwoFactorService) IsRequired(userID int) (bool, error) {
+	roleIDs, err := effectiveUserRoleIDs(&repositories.RoleRepository{DB: s.Repo.DB}, userID)
+	if err != nil {
+		return false, err
+	}
+	return s.Repo.RoleRequiresTwoFactor(roleIDs)
+}
+
+// BeginEnrollment membuat secret baru (jika 2FA belum aktif) dan data QR untuk dipindai.
+func (s *TwoFactorService) BeginEnrollment(userID int, account string) (*models.TwoFactorSetup, error) {
+	state, err := s.Repo.GetState(userID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, errors.New("2FA sudah aktif")
+	}
+
+	secret := state.Secret
+	if secret == "" {
+		secret, err = helpers.GenerateTOTPSecret()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Repo.SavePendingSecret(userID, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	uri := helpers.TOTPProvisioningURI(twoFactorIssuer(), account, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 220)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: uri,
+		QRCode:          template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)),
+	}, nil
+}
+
+// ConfirmEnrollment mengaktifkan 2FA setelah kode pertama dari aplikasi authenticator valid.
+// Recovery code dalam bentuk plain hanya dikembalikan sekali di sini.
+func (s *TwoFactorService) ConfirmEnrollment(userID int, code string) ([]string, error) {
+	state, err := s.Repo.GetState(userID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, errors.New("2FA sudah aktif")
+	}
+	if state.Secret == "" {
+		return nil, errors.New("mulai pendaftaran 2FA terlebih dahulu")
+	}
+
+	step, ok := helpers.ValidateTOTP(state.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, errors.New("kode verifikasi tidak valid")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.Enable(userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify memeriksa kode TOTP atau recovery code saat login. Kode TOTP yang sama
+// tidak bisa dipakai dua kali dan recovery code hanya berlaku sekali.
+func (s *TwoFactorService) Verify(userID int, code string) (bool, error) {
+	state, err := s.Repo.GetState(userID)
+	if err != nil {
+		return false, err
+	}
+	if !state.Enabled {
+		return false, nil
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := helpers.ValidateTOTP(state.Secret, code, time.Now(), totpSkew); ok {
+		return s.Repo.MarkStepUsed(userID, step)
+	}
+
+	return s.Repo.UseRecoveryCode(userID, hashRecoveryCode(code))
+}
+
+// RegenerateRecoveryCodes membuat recovery code baru setelah kode TOTP diverifikasi.
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	ok, err := s.Verify(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("kode verifikasi tidak valid")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable mematikan 2FA setelah kode diverifikasi. Ditolak jika role user mewajibkan 2FA.
+func (s *TwoFactorService) Disable(userID int, code string) error {
+	required, err := s.IsRequired(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return errors.New("role Anda mewajibkan 2FA sehingga tidak bisa dinonaktifkan")
+	}
+
+	ok, err := s.Verify(userID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("kode verifikasi tidak valid")
+	}
+
+	return s.Repo.Disable(userID)
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(hex.EncodeToString(b))
+		codes[i] = fmt.Sprintf("%s-%s", raw[:5], raw[5:])
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func twoFactorIssuer() string {
+	if issuer := strings.TrimSpace(os.Getenv("APP_NAME")); issuer != "" {
+		return issuer
+	}
+	return "Stok Hadiah"
+}
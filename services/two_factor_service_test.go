@@ -0,0 +1,96 @@
+package services
+
+import (
+	helpers "gobase-app/helper"
+	"gobase-app/repositories"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTwoFactorVerifyRejectsReplayedCode(t *testing.T) {
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		stepDiff int64 // time-step kode relatif terhadap sekarang
+		used     bool  // MarkStepUsed menolak karena step sudah atau lebih baru dipakai
+		want     bool
+	}{
+		{name: "kode sekarang", want: true},
+		{name: "kode step sebelumnya", stepDiff: -1, want: true},
+		{name: "kode yang sudah dipakai", used: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			step := helpers.TOTPStep(time.Now()) + tt.stepDiff
+			code, err := helpers.TOTPCode(secret, step)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mock.ExpectQuery(`SELECT\s+u.two_factor_secret`).WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"secret", "enabled_at", "last_step", "remaining"}).
+					AddRow(secret, time.Now(), step-2, 8))
+			affected := int64(1)
+			if tt.used {
+				affected = 0
+			}
+			// pada batas step kode bisa cocok dengan step sebelah, jadi step tidak dicocokkan persis
+			mock.ExpectExec(`UPDATE users\s+SET two_factor_last_step = \?`).
+				WithArgs(sqlmock.AnyArg(), 7, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, affected))
+
+			svc := &TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: db}}
+			ok, err := svc.Verify(7, code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.want {
+				t.Errorf("Verify = %v, want %v", ok, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestTwoFactorRequiredByInheritedRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// user memegang role 4 yang mewarisi role 3; hanya role 3 yang mewajibkan 2FA
+	mock.ExpectQuery(`SELECT role_id\s+FROM model_has_roles`).WithArgs(7, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow(4))
+	mock.ExpectQuery(`SELECT role_id, parent_id FROM role_parents`).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id", "parent_id"}).AddRow(4, 3))
+	mock.ExpectQuery(`FROM roles\s+WHERE id IN \(\?,\?\) AND requires_two_factor = 1`).WithArgs(3, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	svc := &TwoFactorService{Repo: &repositories.TwoFactorRepository{DB: db}}
+	required, err := svc.IsRequired(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !required {
+		t.Error("IsRequired = false, want 2FA wajib dari role warisan")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"sort"
+)
+
+// UserPermissionService mengelola permission yang diberikan atau ditolak langsung ke user,
+// di luar permission yang didapat lewat role.
+type UserPermissionService struct {
+	Users       *repositories.UserRepository
+	Permissions *repositories.PermissionRepository
+	Roles       *repositories.RoleRepository
+}
+
+// Overview menyusun seluruh permission beserta asal-usulnya (role, role warisan, atau langsung) dan status
+// efektifnya untuk user tertentu.
+func (s *UserPermissionService) Overview(userID int) (*models.UserPermissionOverview, error) {
+	user, err := s.Users.GetByID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundf("user dengan id %d tidak ditemukan", userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.Permissions.GetGrouped()
+	if err != nil {
+		return nil, err
+	}
+	sources, err := s.rolePermissionSources(userID)
+	if err != nil {
+		return nil, err
+	}
+	direct, err := s.Permissions.GetDirectPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+	adminRoles, err := s.Roles.GetUserAdminRoleNames(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stores, err := (&repositories.StoreRepository{DB: s.Users.DB}).GetByIDs(user.StoreIDs)
+	if err != nil {
+		return nil, err
+	}
+	storePermissions, err := s.Permissions.GetStorePermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &models.UserPermissionOverview{
+		UserID:           user.ID,
+		Username:         user.Username,
+		Name:             user.Name,
+		AdminRoles:       adminRoles,
+		Stores:           stores,
+		StorePermissions: storePermissions,
+	}
+
+	for _, group := range groups {
+		item := models.UserPermissionGroup{Key: group.Key, Label: group.Label}
+		for _, perm := range group.Permissions {
+			denied, hasDirect := direct[perm.ID]
+			row := models.UserPermission{
+				Permission: perm,
+				RoleNames:  sources[perm.ID],
+				Granted:    hasDirect && !denied,
+				Denied:     hasDirect && denied,
+			}
+			// sama dengan aturan di PermissionRepository.GetUserPermissionNames
+			row.Effective = len(adminRoles) > 0 || ((len(row.RoleNames) > 0 || row.Granted) && !row.Denied)
+			if row.Effective {
+				overview.EffectiveCount++
+			}
+			item.Permissions = append(item.Permissions, row)
+		}
+		overview.Groups = append(overview.Groups, item)
+	}
+
+	return overview, nil
+}
+
+// GrantStore memberikan permission ke user khusus untuk satu store milik user tersebut, mis.
+// stock_edit hanya di MK2. Actor harus memiliki permission itu di store yang sama.
+func (s *UserPermissionService) GrantStore(userID, storeID int, permissionID int64, actor models.AuditActor, principal models.Principal) error {
+	if err := s.validateStore(userID, storeID, permissionID); err != nil {
+		return err
+	}
+	if err := s.guardAdminTarget(userID, principal); err != nil {
+		return err
+	}
+
+	guard, err := newDelegationGuard(s.Users.DB, principal)
+	if err != nil {
+		return err
+	}
+	if err := guard.ensureManageUser(s.Users, userID); err != nil {
+		return err
+	}
+	if err := guard.ensureStorePermission(permissionID, storeID); err != nil {
+		return err
+	}
+
+	if err := s.Permissions.AddStorePermission(userID, storeID, permissionID, actor); err != nil {
+		return err
+	}
+	InvalidateUserPermissions(userID)
+	return nil
+}
+
+// RevokeStore mencabut permission per store milik user.
+func (s *UserPermissionService) RevokeStore(userID, storeID int, permissionID int64, actor models.AuditActor, principal models.Principal) error {
+	if err := s.validate(userID, permissionID); err != nil {
+		return err
+	}
+	if err := s.guardDelegation(userID, permissionID, false, principal); err != nil {
+		return err
+	}
+
+	err := s.Permissions.DeleteStorePermission(userID, storeID, permissionID, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundf("permission tersebut tidak diberikan untuk store ini")
+	}
+	if err != nil {
+		return err
+	}
+	InvalidateUserPermissions(userID)
+	return nil
+}
+
+// rolePermissionSources mengembalikan label role yang memberikan setiap permission ke user, termasuk
+// role warisan dengan penanda role user yang mewariskannya (mis. "staff (lewat manager)").
+func (s *UserPermissionService) rolePermissionSources(userID int) (map[int64][]string, error) {
+	roleIDs, err := s.Roles.GetUserRoleIDs(userID)
+	if err != nil || len(roleIDs) == 0 {
+		return nil, err
+	}
+
+	parents, err := s.Roles.GetParentMap()
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.Roles.GetNames()
+	if err != nil {
+		return nil, err
+	}
+	permsByRole, err := s.Roles.GetPermissionIDsByRoles(expandRoleIDs(parents, roleIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[int64][]string)
+	seen := make(map[int64]map[string]bool)
+	add := func(permID int64, label string) {
+		if seen[permID] == nil {
+			seen[permID] = make(map[string]bool)
+		}
+		if !seen[permID][label] {
+			seen[permID][label] = true
+			sources[permID] = append(sources[permID], label)
+		}
+	}
+
+	for _, roleID := range roleIDs {
+		for _, permID := range permsByRole[roleID] {
+			add(permID, names[roleID])
+		}
+		for _, ancestorID := range roleAncestors(parents, roleID) {
+			for _, permID := range permsByRole[ancestorID] {
+				add(permID, fmt.Sprintf("%s (lewat %s)", names[ancestorID], names[roleID]))
+			}
+		}
+	}
+	for permID := range sources {
+		sort.Strings(sources[permID])
+	}
+	return sources, nil
+}
+
+// Grant memberikan permission langsung ke user. Deny yang ada untuk permission yang sama diganti.
+func (s *UserPermissionService) Grant(userID int, permissionID int64, actor models.AuditActor, principal models.Principal) error {
+	return s.set(userID, permissionID, false, actor, principal)
+}
+
+// Deny menolak permission untuk user walaupun didapat lewat role. Grant yang ada diganti.
+func (s *UserPermissionService) Deny(userID int, permissionID int64, actor models.AuditActor, principal models.Principal) error {
+	return s.set(userID, permissionID, true, actor, principal)
+}
+
+// RemoveGrant menghapus permission yang diberikan langsung ke user.
+func (s *UserPermissionService) RemoveGrant(userID int, permissionID int64, actor models.AuditActor, principal models.Principal) error {
+	return s.remove(userID, permissionID, false, actor, principal)
+}
+
+// RemoveDeny menghapus penolakan permission sehingga user kembali mengikuti role-nya.
+func (s *UserPermissionService) RemoveDeny(userID int, permissionID int64, actor models.AuditActor, principal models.Principal) error {
+	return s.remove(userID, permissionID, true, actor, principal)
+}
+
+func (s *UserPermissionService) set(userID int, permissionID int64, denied bool, actor models.AuditActor, principal models.Principal) error {
+	if err := s.validate(userID, permissionID); err != nil {
+		return err
+	}
+	// deny hanya mengurangi hak sehingga cukup dicek store-nya
+	if err := s.guardDelegation(userID, permissionID, !denied, principal); err != nil {
+		return err
+	}
+
+	if err := s.Permissions.SetDirectPermission(userID, permissionID, denied, actor); err != nil {
+		return err
+	}
+
+	InvalidateUserPermissions(userID)
+	return nil
+}
+
+func (s *UserPermissionService) remove(userID int, permissionID int64, denied bool, actor models.AuditActor, principal models.Principal) error {
+	if err := s.validate(userID, permissionID); err != nil {
+		return err
+	}
+	// mencabut deny bisa mengembalikan permission dari role, jadi diperlakukan seperti grant
+	if err := s.guardDelegation(userID, permissionID, denied, principal); err != nil {
+		return err
+	}
+
+	err := s.Permissions.DeleteDirectPermission(userID, permissionID, denied, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		if denied {
+			return notFoundf("permission tersebut tidak sedang ditolak untuk user ini")
+		}
+		return notFoundf("permission tersebut tidak diberikan langsung ke user ini")
+	}
+	if err != nil {
+		return err
+	}
+
+	InvalidateUserPermissions(userID)
+	return nil
+}
+
+// guardDelegation memastikan principal boleh mengelola user userID dan, jika grants bernilai true,
+// memiliki sendiri permission yang akan diberikan.
+func (s *UserPermissionService) guardDelegation(userID int, permissionID int64, grants bool, principal models.Principal) error {
+	if err := s.guardAdminTarget(userID, principal); err != nil {
+		return err
+	}
+
+	guard, err := newDelegationGuard(s.Users.DB, principal)
+	if err != nil {
+		return err
+	}
+	if err := guard.ensureManageUser(s.Users, userID); err != nil {
+		return err
+	}
+	if grants {
+		return guard.ensurePermissions([]int64{permissionID})
+	}
+	return nil
+}
+
+// guardAdminTarget menolak perubahan permission langsung maupun per store milik user admin jika
+// principal bukan admin, sama seperti mengubah user admin lewat form user.
+func (s *UserPermissionService) guardAdminTarget(userID int, principal models.Principal) error {
+	isAdmin, err := s.Roles.UserIsAdmin(userID)
+	if err != nil || !isAdmin {
+		return err
+	}
+	return requireAdminActor(s.Roles, principal, "mengubah permission user admin")
+}
+
+// validateStore memvalidasi permission per store: store wajib termasuk store milik user.
+func (s *UserPermissionService) validateStore(userID, storeID int, permissionID int64) error {
+	if err := s.validate(userID, permissionID); err != nil {
+		return err
+	}
+	if storeID <= 0 {
+		return invalidf("store wajib dipilih")
+	}
+
+	storeIDs, err := s.Users.GetStoreIDs(userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range storeIDs {
+		if id == storeID {
+			return nil
+		}
+	}
+	return invalidf("store tersebut bukan store milik user ini")
+}
+
+func (s *UserPermissionService) validate(userID int, permissionID int64) error {
+	if userID <= 0 {
+		return invalidf("user id tidak valid")
+	}
+	if permissionID <= 0 {
+		return invalidf("permission tidak valid")
+	}
+
+	if _, err := s.Users.GetStatus(userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFoundf("user dengan id %d tidak ditemukan", userID)
+		}
+		return err
+	}
+
+	found, err := s.Roles.FindExistingPermissionIDs([]int64{permissionID})
+	if err != nil {
+		return err
+	}
+	if !found[permissionID] {
+		return notFoundf("permission dengan id %d tidak ditemukan", permissionID)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUserPermissionAdminTargetRequiresAdmin(t *testing.T) {
+	const adminTarget = 7
+
+	tests := []struct {
+		name string
+		run  func(*UserPermissionService, models.Principal) error
+	}{
+		{
+			name: "grant",
+			run: func(s *UserPermissionService, principal models.Principal) error {
+				return s.Grant(adminTarget, 1, models.AuditActor{UserID: principal.UserID}, principal)
+			},
+		},
+		{
+			name: "deny",
+			run: func(s *UserPermissionService, principal models.Principal) error {
+				return s.Deny(adminTarget, 1, models.AuditActor{UserID: principal.UserID}, principal)
+			},
+		},
+		{
+			name: "hapus deny",
+			run: func(s *UserPermissionService, principal models.Principal) error {
+				return s.RemoveDeny(adminTarget, 1, models.AuditActor{UserID: principal.UserID}, principal)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT status FROM users WHERE id = \?`).WithArgs(adminTarget).
+				WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+			mock.ExpectQuery(`SELECT id FROM permissions WHERE id IN`).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			expectAdminCheck(mock, adminTarget, true)
+			expectAdminCheck(mock, delegatingUser, false)
+
+			svc := &UserPermissionService{
+				Users:       &repositories.UserRepository{DB: db},
+				Permissions: &repositories.PermissionRepository{DB: db},
+				Roles:       &repositories.RoleRepository{DB: db},
+			}
+			err = tt.run(svc, models.Principal{UserID: delegatingUser})
+			if !errors.Is(err, ErrForbidden) {
+				t.Errorf("err = %v, want ErrForbidden untuk perubahan permission user admin oleh non-admin", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -3,11 +3,11 @@ package services
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 	"net/mail"
-	"gobase-app/config"
 	"gobase-app/models"
 	"gobase-app/repositories"
+	"sort"
+	"strconv"
 	"strings"
 
 	"golang.org/x/crypto/bcrypt"
@@ -17,64 +17,122 @@ type UserService struct {
 	Repo *repositories.UserRepository
 }
 
-func (s *UserService) GetUsers() ([]models.User, error) {
-	return s.Repo.GetAll()
+// GetUsers mengambil user yang berada di store dalam filter.
+func (s *UserService) GetUsers(filter models.StoreFilter) ([]models.User, error) {
+	return s.Repo.GetAll(filter)
+}
+
+// GetUsersPage mengambil satu halaman user dalam filter store beserta total data untuk paginasi.
+func (s *UserService) GetUsersPage(filter models.StoreFilter, page, perPage int) ([]models.User, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	return s.Repo.GetPage(filter, perPage, (page-1)*perPage)
+}
+
+// GetUser mengambil detail satu user berdasarkan id.
+func (s *UserService) GetUser(id int) (*models.User, error) {
+	if id <= 0 {
+		return nil, invalidf("user id tidak valid")
+	}
+
+	user, err := s.Repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFoundf("user dengan id %d tidak ditemukan", id)
+	}
+	return user, err
 }
 
 // CreateUser memproses data dari form, melakukan validasi dasar, hashing password,
-// lalu menyimpan user beserta role yang dipilih.
-func (s *UserService) CreateUser(input models.UserCreateInput) error {
+// lalu menyimpan user beserta role yang dipilih. Mengembalikan id user baru.
+// Actor hanya boleh memberikan role yang permission-nya ia miliki dan store yang beririsan dengan store-nya.
+func (s *UserService) CreateUser(input models.UserCreateInput, actor models.AuditActor, principal models.Principal) (int, error) {
+	if strings.TrimSpace(input.Status) != "non_active" {
+		input.Status = "active"
+	}
+
+	roleIDs, err := s.Repo.GetRoleIDsByNames(uniqueStrings(input.RoleNames))
+	if err != nil {
+		return 0, err
+	}
+	roles := &repositories.RoleRepository{DB: s.Repo.DB}
+	grantsAdmin, err := roles.ContainsAdminRole(roleIDValues(roleIDs))
+	if err != nil {
+		return 0, err
+	}
+	if grantsAdmin {
+		if err := requireAdminActor(roles, principal, "memberikan role admin"); err != nil {
+			return 0, err
+		}
+	}
+
+	guard, err := newDelegationGuard(s.Repo.DB, principal)
+	if err != nil {
+		return 0, err
+	}
+	if err := guard.ensureRoles(roleIDValues(roleIDs), nil); err != nil {
+		return 0, err
+	}
+	if err := guard.ensureAssignStores(uniqueInts(input.StoreIDs), nil); err != nil {
+		return 0, err
+	}
+
+	return s.createUser(input, actor)
+}
+
+// createUser dipakai bersama oleh form admin dan registrasi mandiri (status pending).
+func (s *UserService) createUser(input models.UserCreateInput, actor models.AuditActor) (int, error) {
 	username := strings.TrimSpace(input.Username)
 	name := strings.TrimSpace(input.Name)
 	email := strings.TrimSpace(input.Email)
 	status := strings.TrimSpace(input.Status)
 
 	if username == "" || name == "" || input.Password == "" {
-		return errors.New("nama, username, dan password wajib diisi")
+		return 0, invalidf("nama, username, dan password wajib diisi")
 	}
 	if email == "" {
-		return errors.New("email wajib diisi")
+		return 0, invalidf("email wajib diisi")
 	}
 	if _, err := mail.ParseAddress(email); err != nil {
-		return errors.New("email tidak valid")
+		return 0, invalidf("email tidak valid")
 	}
 	if input.NIP <= 0 {
-		return errors.New("NIP wajib diisi")
+		return 0, invalidf("NIP wajib diisi")
 	}
-	if status != "active" && status != "non_active" {
+	if status != "active" && status != "non_active" && status != "pending" {
 		status = "active"
 	}
 
 	exists, err := s.Repo.ExistsByUsername(username)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if exists {
-		return fmt.Errorf("username '%s' sudah digunakan", username)
+		return 0, conflictf("username '%s' sudah digunakan", username)
 	}
 
 	exists, err = s.Repo.ExistsByNIP(input.NIP)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if exists {
-		return fmt.Errorf("NIP %d sudah digunakan", input.NIP)
+		return 0, conflictf("NIP %d sudah digunakan", input.NIP)
 	}
 
 	if email != "" {
 		exists, err = s.Repo.ExistsByEmail(email)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if exists {
-			return fmt.Errorf("email %s sudah digunakan", email)
+			return 0, conflictf("email %s sudah digunakan", email)
 		}
 	}
 
 	roleNames := uniqueStrings(input.RoleNames)
 	roleMap, err := s.Repo.GetRoleIDsByNames(roleNames)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	var (
@@ -91,7 +149,7 @@ func (s *UserService) CreateUser(input models.UserCreateInput) error {
 	}
 
 	if len(missingRoles) > 0 {
-		return fmt.Errorf("role tidak ditemukan: %s", strings.Join(missingRoles, ", "))
+		return 0, invalidf("role tidak ditemukan: %s", strings.Join(missingRoles, ", "))
 	}
 
 	storeIDs := uniqueInts(input.StoreIDs)
@@ -99,59 +157,83 @@ func (s *UserService) CreateUser(input models.UserCreateInput) error {
 		storeIDs = []int{}
 	}
 	if len(storeIDs) == 0 {
-		return errors.New("store wajib dipilih")
+		return 0, invalidf("store wajib dipilih")
+	}
+	if err := s.validateStores(storeIDs, nil); err != nil {
+		return 0, err
+	}
+
+	if err := LoadPasswordPolicy().Validate(input.Password, models.PasswordOwner{
+		NIP:      input.NIP,
+		Username: username,
+		Name:     name,
+	}); err != nil {
+		return 0, err
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = s.Repo.CreateUserWithRoles(repositories.UserCreateParams{
-		NIP:            input.NIP,
-		Username:       username,
-		HashedPassword: string(hashedPassword),
-		Name:           name,
-		Email:          email,
-		Status:         status,
-		StoreIDs:       storeIDs,
-	}, roleIDs)
+	userID, err := s.Repo.CreateUserWithRoles(repositories.UserCreateParams{
+		NIP:                input.NIP,
+		Username:           username,
+		HashedPassword:     string(hashedPassword),
+		Name:               name,
+		Email:              email,
+		Status:             status,
+		StoreIDs:           storeIDs,
+		MustChangePassword: input.MustChangePassword,
+	}, roleIDs, actor)
 
-	return err
+	return int(userID), err
 }
 
 // UpdateUser memperbarui data user yang sudah ada.
-func (s *UserService) UpdateUser(input models.UserUpdateInput) error {
+func (s *UserService) UpdateUser(input models.UserUpdateInput, actor models.AuditActor, principal models.Principal) error {
 	username := strings.TrimSpace(input.Username)
 	name := strings.TrimSpace(input.Name)
 	email := strings.TrimSpace(input.Email)
 	status := strings.TrimSpace(input.Status)
 
 	if input.ID <= 0 {
-		return errors.New("user tidak valid")
+		return invalidf("user tidak valid")
 	}
 	if username == "" || name == "" {
-		return errors.New("nama dan username wajib diisi")
+		return invalidf("nama dan username wajib diisi")
 	}
 	if email == "" {
-		return errors.New("email wajib diisi")
+		return invalidf("email wajib diisi")
 	}
 	if _, err := mail.ParseAddress(email); err != nil {
-		return errors.New("email tidak valid")
+		return invalidf("email tidak valid")
 	}
 	if input.NIP <= 0 {
-		return errors.New("NIP wajib diisi")
+		return invalidf("NIP wajib diisi")
 	}
 	if status != "active" && status != "non_active" {
 		status = "active"
 	}
 
+	// user hasil registrasi mandiri hanya bisa diaktifkan lewat approval
+	currentStatus, err := s.Repo.GetStatus(input.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundf("user dengan id %d tidak ditemukan", input.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if currentStatus == "pending" {
+		status = "pending"
+	}
+
 	exists, err := s.Repo.ExistsByUsernameExceptID(username, input.ID)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return fmt.Errorf("username '%s' sudah digunakan", username)
+		return conflictf("username '%s' sudah digunakan", username)
 	}
 
 	exists, err = s.Repo.ExistsByNIPExceptID(input.NIP, input.ID)
@@ -159,7 +241,7 @@ func (s *UserService) UpdateUser(input models.UserUpdateInput) error {
 		return err
 	}
 	if exists {
-		return fmt.Errorf("NIP %d sudah digunakan", input.NIP)
+		return conflictf("NIP %d sudah digunakan", input.NIP)
 	}
 
 	if email != "" {
@@ -168,7 +250,7 @@ func (s *UserService) UpdateUser(input models.UserUpdateInput) error {
 			return err
 		}
 		if exists {
-			return fmt.Errorf("email %s sudah digunakan", email)
+			return conflictf("email %s sudah digunakan", email)
 		}
 	}
 
@@ -192,7 +274,7 @@ func (s *UserService) UpdateUser(input models.UserUpdateInput) error {
 	}
 
 	if len(missingRoles) > 0 {
-		return fmt.Errorf("role tidak ditemukan: %s", strings.Join(missingRoles, ", "))
+		return invalidf("role tidak ditemukan: %s", strings.Join(missingRoles, ", "))
 	}
 
 	storeIDs := uniqueInts(input.StoreIDs)
@@ -200,11 +282,34 @@ func (s *UserService) UpdateUser(input models.UserUpdateInput) error {
 		storeIDs = []int{}
 	}
 	if len(storeIDs) == 0 {
-		return errors.New("store wajib dipilih")
+		return invalidf("store wajib dipilih")
+	}
+	currentStoreIDs, err := s.Repo.GetStoreIDs(input.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateStores(storeIDs, currentStoreIDs); err != nil {
+		return err
+	}
+
+	if err := s.guardAdminChange(input.ID, currentStatus, roleIDs, status, principal); err != nil {
+		return err
+	}
+	if err := s.guardDelegation(input.ID, roleIDs, storeIDs, currentStoreIDs, principal); err != nil {
+		return err
 	}
 
 	var hashedPassword string
 	if strings.TrimSpace(input.Password) != "" {
+		if err := checkNewPassword(s.Repo, models.PasswordOwner{
+			ID:       input.ID,
+			NIP:      input.NIP,
+			Username: username,
+			Name:     name,
+		}, input.Password); err != nil {
+			return err
+		}
+
 		hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 		if err != nil {
 			return err
@@ -212,7 +317,7 @@ func (s *UserService) UpdateUser(input models.UserUpdateInput) error {
 		hashedPassword = string(hashed)
 	}
 
-	return s.Repo.UpdateUserWithRoles(repositories.UserUpdateParams{
+	if err := s.Repo.UpdateUserWithRoles(repositories.UserUpdateParams{
 		ID:             input.ID,
 		NIP:            input.NIP,
 		Username:       username,
@@ -221,89 +326,150 @@ func (s *UserService) UpdateUser(input models.UserUpdateInput) error {
 		Email:          email,
 		Status:         status,
 		StoreIDs:       storeIDs,
-	}, roleIDs)
+
+		MustChangePassword: input.MustChangePassword,
+	}, roleIDs, actor); err != nil {
+		return err
+	}
+	InvalidateUserPermissions(input.ID)
+
+	// User yang dinonaktifkan langsung di-logout dari semua session.
+	if status == "non_active" {
+		return s.revokeSessions(input.ID)
+	}
+
+	return nil
 }
 
-const userModelType = "Models\\User"
+// ChangePassword dipakai user untuk mengganti password sendiri setelah memasukkan password saat ini.
+// Password baru wajib lolos kebijakan password dan tidak boleh sama dengan riwayat terakhir.
+func (s *UserService) ChangePassword(userID int, current, password, confirmation string) error {
+	if current == "" || password == "" {
+		return invalidf("password saat ini dan password baru wajib diisi")
+	}
+	if password != confirmation {
+		return invalidf("konfirmasi password tidak sama")
+	}
+
+	owner, err := s.Repo.GetPasswordOwner(userID)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(owner.PasswordHash), []byte(current)) != nil {
+		return invalidf("password saat ini salah")
+	}
+
+	if err := checkNewPassword(s.Repo, owner, password); err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.Repo.ChangePassword(userID, string(hashed))
+}
 
 // DeleteUser removes user data by ID.
-func (s *UserService) DeleteUser(id int) error {
+func (s *UserService) DeleteUser(id int, actor models.AuditActor, principal models.Principal) error {
 	if id <= 0 {
-		return errors.New("user id tidak valid")
+		return invalidf("user id tidak valid")
+	}
+
+	currentStatus, err := s.Repo.GetStatus(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundf("user dengan id %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.guardAdminChange(id, currentStatus, nil, "", principal); err != nil {
+		return err
+	}
+	guard, err := newDelegationGuard(s.Repo.DB, principal)
+	if err != nil {
+		return err
+	}
+	if err := guard.ensureManageUser(s.Repo, id); err != nil {
+		return err
 	}
-	return s.Repo.DeleteUser(id)
+
+	if err := s.Repo.DeleteUser(id, actor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFoundf("user dengan id %d tidak ditemukan", id)
+		}
+		return err
+	}
+	InvalidateUserPermissions(id)
+	return s.revokeSessions(id)
 }
 
-func UserHasPermission(userID int, perm string) (bool, error) {
-	var dummy int
-	// Cek permission via role yang dimiliki user
-	queryRole := `
-		SELECT 1
-		FROM model_has_roles mhr
-		JOIN role_has_permissions rhp ON rhp.role_id = mhr.role_id
-		JOIN permissions p ON p.id = rhp.permission_id
-		WHERE mhr.model_id = ? AND mhr.model_type = ? AND p.name = ?
-		LIMIT 1
-	`
-	err := config.DB.QueryRow(queryRole, userID, userModelType, perm).Scan(&dummy)
-	if err == nil {
-		return true, nil
-	}
-	if err != sql.ErrNoRows {
-		return false, err
-	}
-
-	// Fallback: cek permission langsung ke user (model_has_permissions)
-	queryDirect := `
-		SELECT 1
-		FROM model_has_permissions mhp
-		JOIN permissions p ON p.id = mhp.permission_id
-		WHERE mhp.model_id = ? AND mhp.model_type = ? AND p.name = ?
-		LIMIT 1
-	`
-
-	err = config.DB.QueryRow(queryDirect, userID, userModelType, perm).Scan(&dummy)
-	if err == nil {
-		return true, nil
-	}
-	if err == sql.ErrNoRows {
-		return false, nil // tidak punya permission
-	}
-
-	return false, err // error lain
+// revokeSessions mengakhiri seluruh session user dan mencatat event session_revoked untuk
+// setiap perangkat yang ter-logout.
+func (s *UserService) revokeSessions(userID int) error {
+	events := &AuthEventService{Repo: &repositories.AuthEventRepository{DB: s.Repo.DB}}
+	_, err := events.EndOtherSessions(userID, "")
+	return err
 }
 
-func GetUserPermissions(userID int) (map[string]bool, error) {
-	perms := make(map[string]bool)
+// guardAdminChange memastikan hanya admin yang boleh mengubah user admin atau memberikan role
+// admin, dan user admin aktif terakhir tidak dinonaktifkan, dihapus, atau dicabut role adminnya.
+// roleIDs dan status adalah nilai setelah perubahan; roleIDs nil dan status kosong berarti user dihapus.
+func (s *UserService) guardAdminChange(userID int, currentStatus string, roleIDs []int64, status string, principal models.Principal) error {
+	roles := &repositories.RoleRepository{DB: s.Repo.DB}
 
-	rows, err := config.DB.Query(`
-		SELECT DISTINCT p.name
-		FROM permissions p
-		JOIN role_has_permissions rhp ON rhp.permission_id = p.id
-		JOIN model_has_roles mhr ON mhr.role_id = rhp.role_id
-		WHERE mhr.model_id = ? AND mhr.model_type = ?
+	wasAdmin, err := roles.UserIsAdmin(userID)
+	if err != nil {
+		return err
+	}
+	willAdmin, err := roles.ContainsAdminRole(roleIDs)
+	if err != nil {
+		return err
+	}
+	if !wasAdmin && !willAdmin {
+		return nil
+	}
 
-		UNION
+	if err := requireAdminActor(roles, principal, "mengubah user admin atau memberikan role admin"); err != nil {
+		return err
+	}
+
+	if wasAdmin && currentStatus == "active" && (!willAdmin || status != "active") {
+		return ensureAdminRemains(roles, 0, userID)
+	}
+	return nil
+}
 
-		SELECT DISTINCT p2.name
-		FROM permissions p2
-		JOIN model_has_permissions mhp ON mhp.permission_id = p2.id
-		WHERE mhp.model_id = ? AND mhp.model_type = ?
-	`, userID, userModelType, userID, userModelType)
+// guardDelegation memastikan principal boleh mengelola user userID (store saat ini beririsan), store
+// baru yang ditugaskan termasuk store principal, dan role baru yang diberikan tidak melebihi
+// permission principal.
+func (s *UserService) guardDelegation(userID int, roleIDs []int64, storeIDs, currentStoreIDs []int, principal models.Principal) error {
+	guard, err := newDelegationGuard(s.Repo.DB, principal)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err := guard.ensureManageUser(s.Repo, userID); err != nil {
+		return err
+	}
+	if err := guard.ensureAssignStores(storeIDs, currentStoreIDs); err != nil {
+		return err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			return nil, err
-		}
-		perms[name] = true
+	currentRoleIDs, err := (&repositories.RoleRepository{DB: s.Repo.DB}).GetUserRoleIDs(userID)
+	if err != nil {
+		return err
 	}
+	return guard.ensureRoles(roleIDs, currentRoleIDs)
+}
 
-	return perms, nil
+func roleIDValues(roleIDs map[string]int64) []int64 {
+	ids := make([]int64, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
 }
 
 func uniqueStrings(values []string) []string {
@@ -320,6 +486,42 @@ func uniqueStrings(values []string) []string {
 	return result
 }
 
+// validateStores memastikan seluruh storeIDs ada di tabel stores dan masih aktif. Store nonaktif
+// hanya diterima jika sudah ditugaskan ke user sebelumnya (currentStoreIDs).
+func (s *UserService) validateStores(storeIDs, currentStoreIDs []int) error {
+	stores, err := (&repositories.StoreRepository{DB: s.Repo.DB}).GetByIDs(storeIDs)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[int]bool, len(currentStoreIDs))
+	for _, id := range currentStoreIDs {
+		current[id] = true
+	}
+
+	found := make(map[int]bool, len(stores))
+	var inactive []string
+	for _, store := range stores {
+		found[store.StoreID] = true
+		if !store.IsActive && !current[store.StoreID] {
+			inactive = append(inactive, store.StoreName)
+		}
+	}
+	var missing []string
+	for _, id := range storeIDs {
+		if !found[id] {
+			missing = append(missing, strconv.Itoa(id))
+		}
+	}
+	if len(missing) > 0 {
+		return invalidf("store tidak ditemukan: %s", strings.Join(missing, ", "))
+	}
+	if len(inactive) > 0 {
+		return invalidf("store sudah nonaktif dan tidak bisa dipilih: %s", strings.Join(inactive, ", "))
+	}
+	return nil
+}
+
 func uniqueInts(values []int) []int {
 	seen := make(map[int]bool)
 	var result []int
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"gobase-app/models"
+	"gobase-app/repositories"
+	"gobase-app/sessionstore"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectUserUpdate mengharapkan UpdateUser user 7 oleh admin 1 tanpa mengubah role, store, dan password.
+func expectUserUpdate(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectQuery(`SELECT status FROM users WHERE id = \?`).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	for _, column := range []string{"username", "nip", "email"} {
+		mock.ExpectQuery(`SELECT COUNT\(1\) FROM users WHERE ` + column + ` = \? AND id <> \?`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	}
+	mock.ExpectQuery(`SELECT TRUE FROM users WHERE id = \?`).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT store_id FROM user_stores`).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id"}).AddRow(1))
+	mock.ExpectQuery(`FROM stores\s+WHERE store_id IN`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id", "store_code", "store_name", "store_address", "is_active"}).
+			AddRow(1, "MK1", "Mekarsari", "Jl. Mekarsari", true))
+	expectAdminCheck(mock, 7, false)
+	expectAdminCheck(mock, 1, true)
+	mock.ExpectQuery(`SELECT role_id\s+FROM model_has_roles`).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id"}))
+
+	mock.ExpectBegin()
+	expectUserStatusSnapshot(mock, "active", "Alice", "alice@kampus.local")
+	mock.ExpectExec(`UPDATE users\s+SET nip = \?`).
+		WithArgs(1001, "alice", "Alice", "alice@kampus.local", status, false, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM user_stores`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT IGNORE INTO user_stores`).WithArgs(int64(7), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM model_has_roles`).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectUserStatusSnapshot(mock, status, "Alice", "alice@kampus.local")
+	if status != "active" {
+		mock.ExpectExec(`INSERT INTO audit_logs`).WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func TestUserServiceRevokesSessions(t *testing.T) {
+	admin := models.AuditActor{UserID: 1, Username: "admin"}
+	principal := models.Principal{UserID: 1}
+	update := func(status string) func(*UserService) error {
+		return func(s *UserService) error {
+			return s.UpdateUser(models.UserUpdateInput{
+				ID:       7,
+				NIP:      1001,
+				Username: "alice",
+				Name:     "Alice",
+				Email:    "alice@kampus.local",
+				Status:   status,
+				StoreIDs: []int{1},
+			}, admin, principal)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		setup       func(sqlmock.Sqlmock)
+		run         func(*UserService) error
+		wantRevoked bool
+	}{
+		{
+			name:        "user dinonaktifkan",
+			setup:       func(mock sqlmock.Sqlmock) { expectUserUpdate(mock, "non_active") },
+			run:         update("non_active"),
+			wantRevoked: true,
+		},
+		{
+			name:  "user tetap aktif",
+			setup: func(mock sqlmock.Sqlmock) { expectUserUpdate(mock, "active") },
+			run:   update("active"),
+		},
+		{
+			name: "user dihapus",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT status FROM users WHERE id = \?`).WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+				expectAdminCheck(mock, 7, false)
+				expectAdminCheck(mock, 1, true)
+
+				mock.ExpectBegin()
+				expectUserSnapshot(mock, "Alice", "alice@kampus.local")
+				for _, table := range []string{"model_has_roles", "model_has_permissions", "model_has_store_permissions",
+					"user_stores", "personal_access_tokens", "user_identities", "users"} {
+					mock.ExpectExec(`DELETE FROM ` + table + ` WHERE`).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectExec(`INSERT INTO audit_logs`).
+					WithArgs(1, "admin", "user.delete", "user", int64(7), sqlmock.AnyArg(), nil, "", "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			run:         func(s *UserService) error { return s.DeleteUser(7, admin, principal) },
+			wantRevoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			tt.setup(mock)
+			if tt.wantRevoked {
+				// setiap perangkat yang ter-logout tercatat di riwayat login user
+				for _, ip := range []string{"10.0.0.2", "10.0.0.3"} {
+					mock.ExpectExec(`INSERT INTO auth_events`).
+						WithArgs(7, "", "", 7, models.AuthEventSessionRevoked, ip, "", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+			}
+
+			backend := sessionstore.NewMemoryBackend()
+			sessionstore.SetDefault(backend)
+			t.Cleanup(func() { sessionstore.SetDefault(nil) })
+			now := time.Now()
+			expires := now.Add(time.Hour)
+			backend.Save(models.Session{ID: "alice-laptop", UserID: 7, IPAddress: "10.0.0.2", LastActivity: now, ExpiresAt: expires})
+			backend.Save(models.Session{ID: "alice-phone", UserID: 7, IPAddress: "10.0.0.3", LastActivity: now.Add(-time.Minute), ExpiresAt: expires})
+			backend.Save(models.Session{ID: "admin", UserID: 1, ExpiresAt: expires})
+
+			svc := &UserService{Repo: &repositories.UserRepository{DB: db}}
+			if err := tt.run(svc); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			remaining, _ := backend.FindByUserID(7)
+			if tt.wantRevoked && len(remaining) != 0 {
+				t.Errorf("%d session user 7 masih aktif, want dicabut", len(remaining))
+			}
+			if !tt.wantRevoked && len(remaining) != 2 {
+				t.Errorf("%d session user 7 aktif, want 2", len(remaining))
+			}
+			if others, _ := backend.FindByUserID(1); len(others) != 1 {
+				t.Error("session user lain ikut dicabut")
+			}
+		})
+	}
+}
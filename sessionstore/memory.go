@@ -0,0 +1,102 @@
+package sessionstore
+
+import (
+	"gobase-app/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryBackend menyimpan session di memori proses. Cocok untuk test dan development
+// satu instance; seluruh session hilang ketika aplikasi restart.
+type MemoryBackend struct {
+	mu       sync.RWMutex
+	sessions map[string]models.Session
+}
+
+// NewMemoryBackend membuat backend memory kosong.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{sessions: make(map[string]models.Session)}
+}
+
+func (m *MemoryBackend) Find(id string) (*models.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[id]
+	if !ok || !s.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (m *MemoryBackend) Save(s models.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[s.ID] = s
+	return nil
+}
+
+func (m *MemoryBackend) Touch(id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.sessions[id]; ok {
+		s.LastActivity = at
+		m.sessions[id] = s
+	}
+	return nil
+}
+
+func (m *MemoryBackend) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *MemoryBackend) DeleteByUserID(userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.sessions {
+		if s.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryBackend) FindByUserID(userID int) ([]models.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	var result []models.Session
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.ExpiresAt.After(now) {
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastActivity.After(result[j].LastActivity)
+	})
+	return result, nil
+}
+
+func (m *MemoryBackend) DeleteExpired() ([]models.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var expired []models.Session
+	for id, s := range m.sessions {
+		if !s.ExpiresAt.After(now) {
+			expired = append(expired, s)
+			delete(m.sessions, id)
+		}
+	}
+	return expired, nil
+}
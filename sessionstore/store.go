@@ -0,0 +1,351 @@
+// Package sessionstore menyediakan session store server-side untuk gin-contrib/sessions.
+// Cookie hanya berisi ID session yang ditandatangani (dan dienkripsi), sedangkan isi session
+// disimpan di Backend (MySQL atau memory) sehingga session bisa dicabut dari sisi server.
+package sessionstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/gob"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"gobase-app/models"
+	"time"
+
+	ginsessions "github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// Backend adalah tempat penyimpanan data session.
+type Backend interface {
+	Find(id string) (*models.Session, error)
+	Save(s models.Session) error
+	// Touch memperbarui waktu aktivitas terakhir session tanpa mengubah isinya.
+	Touch(id string, at time.Time) error
+	Delete(id string) error
+	DeleteByUserID(userID int) error
+	// FindByUserID mengambil seluruh session yang masih berlaku milik user.
+	FindByUserID(userID int) ([]models.Session, error)
+	// DeleteExpired menghapus session kedaluwarsa dan mengembalikan session yang dihapus.
+	DeleteExpired() ([]models.Session, error)
+}
+
+// UserIDKey adalah key session yang dipakai untuk mengaitkan session dengan user.
+const UserIDKey = "user_id"
+
+var defaultBackend Backend
+
+// SetDefault mendaftarkan backend yang dipakai oleh RevokeUser.
+func SetDefault(b Backend) {
+	defaultBackend = b
+}
+
+// RevokeUser menghapus seluruh session aktif milik user (force logout).
+func RevokeUser(userID int) error {
+	if defaultBackend == nil || userID <= 0 {
+		return nil
+	}
+	return defaultBackend.DeleteByUserID(userID)
+}
+
+// SessionKey menghasilkan penanda session yang aman ditampilkan ke browser.
+// ID session asli setara dengan cookie login sehingga tidak boleh ikut dirender.
+func SessionKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
+
+// UserSessions mengambil session aktif milik user dari backend default.
+func UserSessions(userID int) ([]models.Session, error) {
+	if defaultBackend == nil || userID <= 0 {
+		return nil, nil
+	}
+	return defaultBackend.FindByUserID(userID)
+}
+
+// RevokeSession mengakhiri satu session milik user berdasarkan SessionKey.
+// Mengembalikan nil jika session tidak ditemukan atau bukan milik user tersebut.
+func RevokeSession(userID int, key string) (*models.Session, error) {
+	sessions, err := UserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		if SessionKey(s.ID) != key {
+			continue
+		}
+		if err := defaultBackend.Delete(s.ID); err != nil {
+			return nil, err
+		}
+		return &s, nil
+	}
+	return nil, nil
+}
+
+// RevokeOtherSessions mengakhiri seluruh session user kecuali session dengan ID currentID.
+func RevokeOtherSessions(userID int, currentID string) ([]models.Session, error) {
+	sessions, err := UserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revoked []models.Session
+	for _, s := range sessions {
+		if s.ID == currentID {
+			continue
+		}
+		if err := defaultBackend.Delete(s.ID); err != nil {
+			return revoked, err
+		}
+		revoked = append(revoked, s)
+	}
+	return revoked, nil
+}
+
+// Store mengimplementasikan ginsessions.Store dengan data session di Backend.
+type Store struct {
+	Codecs  []securecookie.Codec
+	options *sessions.Options
+	backend Backend
+	// anonymousTTL membatasi umur session tanpa user (mis. hanya berisi token CSRF halaman login)
+	// agar pengunjung anonim tidak menumpuk baris session sampai MaxAge.
+	anonymousTTL time.Duration
+	// touchInterval membatasi seberapa sering aktivitas terakhir ditulis ketika session hanya dibaca.
+	touchInterval time.Duration
+}
+
+// DefaultAnonymousTTL adalah umur default session tanpa user di backend.
+const DefaultAnonymousTTL = time.Hour
+
+// DefaultTouchInterval adalah jarak minimal antar penulisan aktivitas terakhir session yang hanya dibaca.
+const DefaultTouchInterval = time.Minute
+
+var base32RawStdEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewStore membuat store baru. keyPairs mengikuti aturan securecookie.CodecsFromPairs:
+// pasangan pertama dipakai untuk encode, pasangan berikutnya hanya untuk decode (rotasi key).
+func NewStore(backend Backend, keyPairs ...[]byte) *Store {
+	s := &Store{
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		options: &sessions.Options{
+			Path:   "/",
+			MaxAge: 86400 * 30,
+		},
+		backend:       backend,
+		anonymousTTL:  DefaultAnonymousTTL,
+		touchInterval: DefaultTouchInterval,
+	}
+	s.MaxAge(s.options.MaxAge)
+	return s
+}
+
+// Options mengatur opsi cookie default untuk session baru.
+func (s *Store) Options(options ginsessions.Options) {
+	s.options = options.ToGorillaOptions()
+	s.MaxAge(s.options.MaxAge)
+}
+
+// MaxAge mengatur umur session pada store dan codec cookie.
+func (s *Store) MaxAge(age int) {
+	s.options.MaxAge = age
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
+
+// AnonymousTTL mengatur umur session tanpa user di backend. Session tersebut dihapus StartCleanup
+// setelah kedaluwarsa; nilai <= 0 berarti mengikuti MaxAge.
+func (s *Store) AnonymousTTL(ttl time.Duration) {
+	s.anonymousTTL = ttl
+}
+
+// TouchInterval mengatur jarak minimal antar penulisan aktivitas terakhir ketika session dimuat
+// tanpa disimpan. Nilai <= 0 berarti aktivitas ditulis pada setiap request.
+func (s *Store) TouchInterval(interval time.Duration) {
+	s.touchInterval = interval
+}
+
+// Get mengembalikan session dari registry request.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New membuat session baru atau memuat session lama dari backend berdasarkan cookie.
+// Cookie yang tidak valid atau session yang sudah dicabut menghasilkan session kosong.
+// Aktivitas terakhir session yang dimuat diperbarui paling sering sekali per touchInterval.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	var id string
+	if err := securecookie.DecodeMulti(name, c.Value, &id, s.Codecs...); err != nil {
+		return session, nil
+	}
+
+	record, err := s.backend.Find(id)
+	if err != nil {
+		return session, err
+	}
+	if record == nil {
+		return session, nil
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(record.Payload)).Decode(&session.Values); err != nil {
+		return session, nil
+	}
+
+	session.ID = id
+	session.IsNew = false
+
+	if now := time.Now(); now.Sub(record.LastActivity) >= s.touchInterval {
+		if err := s.backend.Touch(id, now); err != nil {
+			return session, err
+		}
+	}
+	return session, nil
+}
+
+// Save menyimpan session ke backend dan menulis cookie berisi ID session.
+// Session dengan MaxAge <= 0 dihapus dari backend.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge <= 0 || len(session.Values) == 0 {
+		if session.ID != "" {
+			if err := s.backend.Delete(session.ID); err != nil {
+				return err
+			}
+		}
+		opts := *session.Options
+		opts.MaxAge = -1
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", &opts))
+		return nil
+	}
+
+	userID := sessionUserID(session.Values)
+
+	// Ganti ID ketika pemilik session berubah (login/ganti user) untuk mencegah session fixation.
+	if session.ID != "" {
+		existing, err := s.backend.Find(session.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.UserID != userID {
+			if err := s.backend.Delete(session.ID); err != nil {
+				return err
+			}
+			session.ID = ""
+		}
+	}
+	if session.ID == "" {
+		session.ID = base32RawStdEncoding.EncodeToString(securecookie.GenerateRandomKey(32))
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(session.Values); err != nil {
+		return err
+	}
+
+	ttl := time.Duration(session.Options.MaxAge) * time.Second
+	if userID == 0 && s.anonymousTTL > 0 && s.anonymousTTL < ttl {
+		ttl = s.anonymousTTL
+	}
+
+	now := time.Now()
+	if err := s.backend.Save(models.Session{
+		ID:           session.ID,
+		UserID:       userID,
+		IPAddress:    clientIP(r),
+		UserAgent:    r.UserAgent(),
+		Payload:      buf.Bytes(),
+		LastActivity: now,
+		ExpiresAt:    now.Add(ttl),
+	}); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// StartCleanup menjalankan pembersihan session kedaluwarsa secara berkala, termasuk session anonim
+// yang melewati AnonymousTTL.
+// onExpired (opsional) dipanggil untuk setiap session milik user yang dihapus karena kedaluwarsa.
+func StartCleanup(b Backend, interval time.Duration, logf func(format string, v ...interface{}), onExpired func(models.Session)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			expired, err := b.DeleteExpired()
+			if err != nil && logf != nil {
+				logf("failed to clean expired sessions: %v", err)
+			}
+			if onExpired == nil {
+				continue
+			}
+			for _, s := range expired {
+				if s.UserID > 0 {
+					onExpired(s)
+				}
+			}
+		}
+	}()
+}
+
+func sessionUserID(values map[interface{}]interface{}) int {
+	switch id := values[UserIDKey].(type) {
+	case int:
+		return id
+	case int64:
+		return int(id)
+	}
+	if u, ok := values["user"].(models.SessionUser); ok {
+		return u.UserID
+	}
+	return 0
+}
+
+type clientIPKey struct{}
+
+// ResolveClientIP menyimpan IP client hasil gin Context.ClientIP (yang memperhitungkan
+// TRUSTED_PROXIES) ke request agar session mencatat IP yang sama dengan riwayat login dan
+// pembatasan login, bukan IP proxy. Harus dipasang sebelum middleware sessions.
+func ResolveClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = WithClientIP(c.Request, c.ClientIP())
+		c.Next()
+	}
+}
+
+// WithClientIP mengembalikan salinan r yang membawa IP client yang sudah di-resolve.
+func WithClientIP(r *http.Request, ip string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip))
+}
+
+// clientIP memakai IP dari WithClientIP, atau alamat koneksi langsung jika tidak ada.
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok && ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,275 @@
+package sessionstore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ginsessions "github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/securecookie"
+)
+
+const testCookieName = "mysession"
+
+var testSessionKey = []byte("0123456789abcdef0123456789abcdef")
+
+// testBrowser menyimpan cookie session antar request seperti browser.
+type testBrowser struct {
+	store  *Store
+	cookie *http.Cookie
+}
+
+// request memuat session dari cookie, menjalankan change, lalu menyimpan session dan
+// mengembalikan ID session setelah disimpan.
+func (b *testBrowser) request(t *testing.T, change func(values map[interface{}]interface{})) string {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if b.cookie != nil {
+		r.AddCookie(b.cookie)
+	}
+	w := httptest.NewRecorder()
+
+	session, err := b.store.Get(r, testCookieName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change != nil {
+		change(session.Values)
+	}
+	if err := session.Save(r, w); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.Name == testCookieName {
+			b.cookie = c
+		}
+	}
+	return session.ID
+}
+
+// load memuat session dari cookie tanpa menyimpannya.
+func (b *testBrowser) load(t *testing.T) map[interface{}]interface{} {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if b.cookie != nil {
+		r.AddCookie(b.cookie)
+	}
+	session, err := b.store.Get(r, testCookieName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session.Values
+}
+
+func setUser(userID int) func(map[interface{}]interface{}) {
+	return func(values map[interface{}]interface{}) {
+		values[UserIDKey] = userID
+	}
+}
+
+func TestStoreRotatesIDWhenUserChanges(t *testing.T) {
+	backend := NewMemoryBackend()
+	b := &testBrowser{store: NewStore(backend, testSessionKey)}
+
+	anonymous := b.request(t, func(values map[interface{}]interface{}) { values["csrf_token"] = "token-1" })
+	login := b.request(t, setUser(5))
+	if login == anonymous {
+		t.Fatal("ID session tidak berganti saat login")
+	}
+	if s, _ := backend.Find(anonymous); s != nil {
+		t.Error("session anonim masih tersimpan setelah login")
+	}
+	if s, _ := backend.Find(login); s == nil || s.UserID != 5 {
+		t.Fatalf("session login = %+v, want milik user 5", s)
+	}
+
+	if again := b.request(t, func(values map[interface{}]interface{}) { values["flash"] = "ok" }); again != login {
+		t.Error("ID session berganti padahal user tidak berubah")
+	}
+
+	switched := b.request(t, setUser(6))
+	if switched == login {
+		t.Fatal("ID session tidak berganti saat user berganti")
+	}
+	if s, _ := backend.Find(login); s != nil {
+		t.Error("session user sebelumnya masih tersimpan")
+	}
+}
+
+func TestStoreLogoutDeletesSession(t *testing.T) {
+	backend := NewMemoryBackend()
+	b := &testBrowser{store: NewStore(backend, testSessionKey)}
+
+	id := b.request(t, setUser(5))
+	b.request(t, func(values map[interface{}]interface{}) {
+		for key := range values {
+			delete(values, key)
+		}
+	})
+
+	if s, _ := backend.Find(id); s != nil {
+		t.Error("session masih tersimpan setelah dikosongkan")
+	}
+	if b.cookie.MaxAge >= 0 {
+		t.Errorf("cookie MaxAge = %d, want dihapus", b.cookie.MaxAge)
+	}
+}
+
+func TestRevokeUserEndsAllSessionsOfUser(t *testing.T) {
+	backend := NewMemoryBackend()
+	SetDefault(backend)
+	t.Cleanup(func() { SetDefault(nil) })
+	store := NewStore(backend, testSessionKey)
+
+	laptop := &testBrowser{store: store}
+	phone := &testBrowser{store: store}
+	other := &testBrowser{store: store}
+	laptop.request(t, setUser(5))
+	phone.request(t, setUser(5))
+	other.request(t, setUser(6))
+
+	if err := RevokeUser(5); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, b := range map[string]*testBrowser{"laptop": laptop, "phone": phone} {
+		if values := b.load(t); len(values) != 0 {
+			t.Errorf("session %s user 5 masih berisi %v setelah dicabut", name, values)
+		}
+	}
+	if values := other.load(t); values[UserIDKey] != 6 {
+		t.Errorf("session user lain ikut dicabut: %v", values)
+	}
+}
+
+func TestRevokeOtherSessionsKeepsCurrent(t *testing.T) {
+	backend := NewMemoryBackend()
+	SetDefault(backend)
+	t.Cleanup(func() { SetDefault(nil) })
+	store := NewStore(backend, testSessionKey)
+
+	current := &testBrowser{store: store}
+	stolen := &testBrowser{store: store}
+	currentID := current.request(t, setUser(5))
+	stolen.request(t, setUser(5))
+
+	revoked, err := RevokeOtherSessions(5, currentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revoked) != 1 {
+		t.Fatalf("revoked = %d session, want 1", len(revoked))
+	}
+	if values := current.load(t); values[UserIDKey] != 5 {
+		t.Error("session yang sedang dipakai ikut dicabut")
+	}
+	if values := stolen.load(t); len(values) != 0 {
+		t.Error("session lain masih aktif")
+	}
+}
+
+func TestStoreIgnoresForgedCookie(t *testing.T) {
+	backend := NewMemoryBackend()
+	store := NewStore(backend, testSessionKey)
+	victim := &testBrowser{store: store}
+	id := victim.request(t, setUser(5))
+
+	// cookie berisi ID session asli tetapi ditandatangani key lain
+	forged, err := securecookie.EncodeMulti(testCookieName, id, securecookie.CodecsFromPairs([]byte("abcdef0123456789abcdef0123456789"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attacker := &testBrowser{store: store, cookie: &http.Cookie{Name: testCookieName, Value: forged}}
+	if values := attacker.load(t); len(values) != 0 {
+		t.Errorf("cookie palsu memuat session %v", values)
+	}
+}
+
+func TestStoreLimitsAnonymousTTL(t *testing.T) {
+	backend := NewMemoryBackend()
+	store := NewStore(backend, testSessionKey)
+	store.AnonymousTTL(10 * time.Minute)
+
+	anonymous := &testBrowser{store: store}
+	user := &testBrowser{store: store}
+	anonID := anonymous.request(t, func(values map[interface{}]interface{}) { values["csrf_token"] = "token-1" })
+	userID := user.request(t, setUser(5))
+
+	anon, _ := backend.Find(anonID)
+	if anon == nil || anon.ExpiresAt.After(time.Now().Add(10*time.Minute)) {
+		t.Errorf("session anonim = %+v, want kedaluwarsa dalam 10 menit", anon)
+	}
+	logged, _ := backend.Find(userID)
+	if logged == nil || logged.ExpiresAt.Before(time.Now().Add(24*time.Hour)) {
+		t.Errorf("session user = %+v, want mengikuti MaxAge", logged)
+	}
+}
+
+func TestStoreTouchesLastActivityOnLoad(t *testing.T) {
+	backend := NewMemoryBackend()
+	b := &testBrowser{store: NewStore(backend, testSessionKey)}
+	id := b.request(t, setUser(5))
+
+	setLastActivity := func(at time.Time) {
+		s, _ := backend.Find(id)
+		s.LastActivity = at
+		backend.Save(*s)
+	}
+
+	recent := time.Now().Add(-10 * time.Second).Truncate(time.Second)
+	setLastActivity(recent)
+	b.load(t)
+	if s, _ := backend.Find(id); !s.LastActivity.Equal(recent) {
+		t.Errorf("LastActivity = %v, want tetap %v karena belum lewat interval", s.LastActivity, recent)
+	}
+
+	stale := time.Now().Add(-5 * time.Minute)
+	setLastActivity(stale)
+	b.load(t)
+	if s, _ := backend.Find(id); !s.LastActivity.After(stale.Add(4 * time.Minute)) {
+		t.Errorf("LastActivity = %v, want diperbarui saat session dimuat", s.LastActivity)
+	}
+}
+
+func TestStoreRecordsResolvedClientIP(t *testing.T) {
+	backend := NewMemoryBackend()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := r.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	r.Use(ResolveClientIP(), ginsessions.Sessions(testCookieName, NewStore(backend, testSessionKey)))
+	r.GET("/", func(c *gin.Context) {
+		session := ginsessions.Default(c)
+		session.Set(UserIDKey, 5)
+		session.Save()
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		// proxy tepercaya meneruskan IP client lewat X-Forwarded-For
+		{name: "lewat proxy tepercaya", remoteAddr: "10.0.0.1:40000", want: "203.0.113.7"},
+		{name: "header dari client langsung diabaikan", remoteAddr: "198.51.100.4:40000", want: "198.51.100.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			sessions, _ := backend.FindByUserID(5)
+			backend.DeleteByUserID(5)
+			if len(sessions) != 1 || sessions[0].IPAddress != tt.want {
+				t.Errorf("session = %+v, want satu session dengan IP %s", sessions, tt.want)
+			}
+		})
+	}
+}